@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/rs/zerolog v1.31.0
+	github.com/shopspring/decimal v1.3.1
 	github.com/stretchr/testify v1.10.0
 	github.com/valyala/fasthttp v1.51.0
 )
@@ -0,0 +1,129 @@
+// Package backoff provides delay strategies for retrying requests and reconnecting streams.
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Strategy computes the delay to wait before a retry attempt.
+// Attempts are numbered from zero; attempt zero starts a new sequence.
+type Strategy interface {
+	NextDelay(attempt int) time.Duration
+}
+
+// Exponential grows the delay from Base by Multiplier per attempt, capped at Max, without jitter
+type Exponential struct {
+	Base       time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// NewExponential creates an exponential strategy. A multiplier below 1 is treated as 2.
+func NewExponential(base, maxDelay time.Duration, multiplier float64) Exponential {
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	if maxDelay < base {
+		maxDelay = base
+	}
+	return Exponential{Base: base, Max: maxDelay, Multiplier: multiplier}
+}
+
+// NextDelay returns Base * Multiplier^attempt, capped at Max
+func (e Exponential) NextDelay(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	delay := float64(e.Base) * math.Pow(e.Multiplier, float64(attempt))
+	if delay >= float64(e.Max) || math.IsInf(delay, 0) || math.IsNaN(delay) {
+		return e.Max
+	}
+	return time.Duration(delay)
+}
+
+// FullJitter picks a random delay between zero and the exponential delay for the attempt
+type FullJitter struct {
+	exponential Exponential
+	rng         *lockedRand
+}
+
+// NewFullJitter creates a full jitter strategy. A nil rng uses a randomly seeded source.
+func NewFullJitter(base, maxDelay time.Duration, multiplier float64, rng *rand.Rand) *FullJitter {
+	return &FullJitter{
+		exponential: NewExponential(base, maxDelay, multiplier),
+		rng:         newLockedRand(rng),
+	}
+}
+
+// NextDelay returns a random delay in [0, Base * Multiplier^attempt], capped at Max
+func (f *FullJitter) NextDelay(attempt int) time.Duration {
+	return f.rng.between(0, f.exponential.NextDelay(attempt))
+}
+
+// DecorrelatedJitter picks a random delay between Base and three times the previous delay, capped at Max.
+// Delays spread out more than full jitter while still growing with each attempt.
+type DecorrelatedJitter struct {
+	base     time.Duration
+	max      time.Duration
+	previous time.Duration
+	rng      *lockedRand
+	mu       sync.Mutex
+}
+
+// NewDecorrelatedJitter creates a decorrelated jitter strategy. A nil rng uses a randomly seeded source.
+func NewDecorrelatedJitter(base, maxDelay time.Duration, rng *rand.Rand) *DecorrelatedJitter {
+	if maxDelay < base {
+		maxDelay = base
+	}
+	return &DecorrelatedJitter{
+		base: base,
+		max:  maxDelay,
+		rng:  newLockedRand(rng),
+	}
+}
+
+// NextDelay returns a random delay in [Base, 3 * previous delay], capped at Max.
+// Attempt zero starts over from Base.
+func (d *DecorrelatedJitter) NextDelay(attempt int) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if attempt <= 0 || d.previous < d.base {
+		d.previous = d.base
+	}
+
+	upper := d.max
+	if d.previous <= d.max/3 {
+		upper = d.previous * 3
+	}
+	delay := d.rng.between(d.base, upper)
+	d.previous = delay
+	return delay
+}
+
+// lockedRand guards a random source shared between goroutines
+type lockedRand struct {
+	rng *rand.Rand
+	mu  sync.Mutex
+}
+
+// newLockedRand wraps rng, seeding a new source if rng is nil
+func newLockedRand(rng *rand.Rand) *lockedRand {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &lockedRand{rng: rng}
+}
+
+// between returns a random duration in [low, high]
+func (r *lockedRand) between(low, high time.Duration) time.Duration {
+	if high <= low {
+		return low
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return low + time.Duration(r.rng.Int63n(int64(high-low)+1))
+}
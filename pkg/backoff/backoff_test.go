@@ -0,0 +1,121 @@
+package backoff
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestExponential_NextDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Exponential
+		expected []time.Duration
+	}{
+		{
+			name:     "doubling",
+			strategy: NewExponential(100*time.Millisecond, time.Second, 2),
+			expected: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second},
+		},
+		{
+			name:     "tripling",
+			strategy: NewExponential(10*time.Millisecond, 500*time.Millisecond, 3),
+			expected: []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 90 * time.Millisecond, 270 * time.Millisecond, 500 * time.Millisecond},
+		},
+		{
+			name:     "invalid multiplier defaults to 2",
+			strategy: NewExponential(time.Second, 10*time.Second, 0),
+			expected: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second},
+		},
+		{
+			name:     "max below base",
+			strategy: NewExponential(time.Second, time.Millisecond, 2),
+			expected: []time.Duration{time.Second, time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		for attempt, expected := range test.expected {
+			if got := test.strategy.NextDelay(attempt); got != expected {
+				t.Errorf("%s: attempt %d = %v, expected %v", test.name, attempt, got, expected)
+			}
+		}
+	}
+}
+
+func TestExponential_Overflow(t *testing.T) {
+	e := NewExponential(time.Second, time.Minute, 2)
+	if got := e.NextDelay(10000); got != time.Minute {
+		t.Errorf("Expected max delay for large attempt, got %v", got)
+	}
+}
+
+func TestJitter_Sequences(t *testing.T) {
+	const (
+		base     = 10 * time.Millisecond
+		maxDelay = time.Second
+	)
+	exponential := NewExponential(base, maxDelay, 2)
+
+	tests := []struct {
+		name    string
+		create  func(rng *rand.Rand) Strategy
+		inRange func(attempt int, previous, delay time.Duration) bool
+	}{
+		{
+			name: "full jitter",
+			create: func(rng *rand.Rand) Strategy {
+				return NewFullJitter(base, maxDelay, 2, rng)
+			},
+			inRange: func(attempt int, _, delay time.Duration) bool {
+				return delay >= 0 && delay <= exponential.NextDelay(attempt)
+			},
+		},
+		{
+			name: "decorrelated jitter",
+			create: func(rng *rand.Rand) Strategy {
+				return NewDecorrelatedJitter(base, maxDelay, rng)
+			},
+			inRange: func(attempt int, previous, delay time.Duration) bool {
+				upper := 3 * previous
+				if attempt == 0 {
+					upper = 3 * base
+				}
+				if upper > maxDelay {
+					upper = maxDelay
+				}
+				return delay >= base && delay <= upper
+			},
+		},
+	}
+
+	for _, test := range tests {
+		first := test.create(rand.New(rand.NewSource(42)))
+		second := test.create(rand.New(rand.NewSource(42)))
+
+		previous := time.Duration(0)
+		for attempt := 0; attempt < 20; attempt++ {
+			delay := first.NextDelay(attempt)
+			if !test.inRange(attempt, previous, delay) {
+				t.Errorf("%s: attempt %d delay %v out of range (previous %v)", test.name, attempt, delay, previous)
+			}
+			// The same seed yields the same sequence
+			if replay := second.NextDelay(attempt); replay != delay {
+				t.Errorf("%s: attempt %d not reproducible: %v != %v", test.name, attempt, replay, delay)
+			}
+			previous = delay
+		}
+	}
+}
+
+func TestDecorrelatedJitter_Restart(t *testing.T) {
+	d := NewDecorrelatedJitter(10*time.Millisecond, time.Second, rand.New(rand.NewSource(1)))
+	for attempt := 0; attempt < 10; attempt++ {
+		d.NextDelay(attempt)
+	}
+
+	// Attempt zero starts a new sequence from the base delay
+	if delay := d.NextDelay(0); delay > 30*time.Millisecond {
+		t.Errorf("Expected delay within 3x base after restart, got %v", delay)
+	}
+}
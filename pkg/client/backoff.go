@@ -0,0 +1,45 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/backoff"
+)
+
+// Backoff tracks retry attempts and computes delays with a backoff strategy.
+// By default it uses exponential backoff with full jitter: each call to Next returns a random
+// delay between zero and the current ceiling, where the ceiling grows by Multiplier per attempt
+// from Base up to Max.
+type Backoff struct {
+	strategy backoff.Strategy
+	attempt  int
+	mu       sync.Mutex
+}
+
+// NewBackoff creates a new full jitter backoff. A multiplier below 1 is treated as 2.
+func NewBackoff(base, maxDelay time.Duration, multiplier float64) *Backoff {
+	return NewBackoffWithStrategy(backoff.NewFullJitter(base, maxDelay, multiplier, nil))
+}
+
+// NewBackoffWithStrategy creates a new backoff that uses the given strategy
+func NewBackoffWithStrategy(strategy backoff.Strategy) *Backoff {
+	return &Backoff{strategy: strategy}
+}
+
+// Next returns the delay to wait before the next attempt
+func (b *Backoff) Next() time.Duration {
+	b.mu.Lock()
+	attempt := b.attempt
+	b.attempt++
+	b.mu.Unlock()
+
+	return b.strategy.NextDelay(attempt)
+}
+
+// Reset restarts the backoff from the base delay
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.attempt = 0
+}
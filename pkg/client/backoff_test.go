@@ -0,0 +1,56 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/backoff"
+)
+
+func TestBackoff_Bounds(t *testing.T) {
+	b := NewBackoff(10*time.Millisecond, time.Second, 2)
+	ceilings := backoff.NewExponential(10*time.Millisecond, time.Second, 2)
+
+	for attempt := 0; attempt < 20; attempt++ {
+		ceiling := ceilings.NextDelay(attempt)
+		delay := b.Next()
+		if delay < 0 || delay > ceiling {
+			t.Errorf("Attempt %d: delay %v outside [0, %v]", attempt, delay, ceiling)
+		}
+		if delay > time.Second {
+			t.Errorf("Attempt %d: delay %v exceeds max", attempt, delay)
+		}
+	}
+}
+
+func TestBackoff_MonotonicCeiling(t *testing.T) {
+	ceilings := backoff.NewExponential(10*time.Millisecond, time.Second, 2)
+
+	previous := time.Duration(0)
+	for attempt := 0; attempt < 20; attempt++ {
+		ceiling := ceilings.NextDelay(attempt)
+		if ceiling < previous {
+			t.Errorf("Attempt %d: ceiling %v decreased from %v", attempt, ceiling, previous)
+		}
+		if ceiling > time.Second {
+			t.Errorf("Attempt %d: ceiling %v exceeds max", attempt, ceiling)
+		}
+		previous = ceiling
+	}
+	if previous != time.Second {
+		t.Errorf("Expected ceiling to reach max, got %v", previous)
+	}
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	b := NewBackoff(10*time.Millisecond, time.Second, 2)
+
+	for i := 0; i < 5; i++ {
+		b.Next()
+	}
+	b.Reset()
+
+	if delay := b.Next(); delay > 10*time.Millisecond {
+		t.Errorf("Expected delay within base after reset, got %v", delay)
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// DebugHistorySize is the number of round trips kept while debugging is enabled
+	DebugHistorySize = 20
+	// maxDebugBodySize is the number of body bytes kept per captured request or response
+	maxDebugBodySize = 64 << 10
+)
+
+// RoundTrip is a request and its response captured while debugging is enabled. Sensitive
+// headers and body fields are always masked, and bodies are truncated to 64 KiB.
+type RoundTrip struct {
+	Time           time.Time         // When the request was sent
+	Method         string            // HTTP method
+	URL            string            // Request URL
+	RequestHeaders map[string]string // Request headers, with credentials masked
+	RequestBody    string            // Request body
+	StatusCode     int               // HTTP status code, 0 if no response was received
+	ResponseBody   string            // Response body
+	Err            error             // Transport error, if any
+	Duration       time.Duration     // Time from sending the request until the response was read
+}
+
+// roundTripLog keeps the most recent round trips in a ring buffer
+type roundTripLog struct {
+	entries []RoundTrip
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+// add records a round trip, replacing the oldest one once the log is full
+func (l *roundTripLog) add(trip RoundTrip) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = trip
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// list returns the recorded round trips, oldest first
+func (l *roundTripLog) list() []RoundTrip {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]RoundTrip(nil), l.entries[:l.next]...)
+	}
+	return append(append([]RoundTrip(nil), l.entries[l.next:]...), l.entries[:l.next]...)
+}
+
+// EnableDebug starts capturing the last DebugHistorySize requests and responses for
+// inspection with LastRoundTrip and RoundTrips, independently of the log level. Captured
+// round trips are kept in memory only. Calling it again keeps the existing history.
+func (c *HTTPClient) EnableDebug() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.debug == nil {
+		c.debug = &roundTripLog{entries: make([]RoundTrip, DebugHistorySize)}
+	}
+}
+
+// DisableDebug stops capturing round trips and discards the captured ones
+func (c *HTTPClient) DisableDebug() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.debug = nil
+}
+
+// LastRoundTrip returns the most recent captured round trip. It returns false if debugging
+// is disabled or no request has been sent since it was enabled.
+func (c *HTTPClient) LastRoundTrip() (RoundTrip, bool) {
+	trips := c.RoundTrips()
+	if len(trips) == 0 {
+		return RoundTrip{}, false
+	}
+	return trips[len(trips)-1], true
+}
+
+// RoundTrips returns the captured round trips, oldest first
+func (c *HTTPClient) RoundTrips() []RoundTrip {
+	c.mu.RLock()
+	debug := c.debug
+	c.mu.RUnlock()
+	if debug == nil {
+		return nil
+	}
+	return debug.list()
+}
+
+// captureRoundTrip records a request and its response if debugging is enabled
+func (c *HTTPClient) captureRoundTrip(req *fasthttp.Request, resp *fasthttp.Response, sent time.Time, duration time.Duration, err error) {
+	c.mu.RLock()
+	debug := c.debug
+	c.mu.RUnlock()
+	if debug == nil {
+		return
+	}
+
+	headers := make(map[string]string)
+	req.Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = string(value)
+	})
+	trip := RoundTrip{
+		Time:           sent,
+		Method:         string(req.Header.Method()),
+		URL:            req.URI().String(),
+		RequestHeaders: redactHeaders(headers),
+		RequestBody:    debugBody(req.Body()),
+		Err:            err,
+		Duration:       duration,
+	}
+	if err == nil {
+		trip.StatusCode = resp.StatusCode()
+		trip.ResponseBody = debugBody(resp.Body())
+	}
+	debug.add(trip)
+}
+
+// debugBody masks and truncates a body for capture
+func debugBody(body []byte) string {
+	if len(body) > maxDebugBodySize {
+		return maskBody(body[:maxDebugBodySize]) + "...(truncated)"
+	}
+	return maskBody(body)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// DefaultHTTPCacheEntries is the number of responses kept by SetHTTPCache when no size is given
+const DefaultHTTPCacheEntries = 256
+
+// httpCache keeps the most recently used GET responses that carry a validator, keyed by URL
+type httpCache struct {
+	maxEntries int
+	entries    map[string]*list.Element // values are *httpCacheEntry
+	order      *list.List               // most recently used first
+	mu         sync.Mutex
+}
+
+// httpCacheEntry is a cached response together with the validators to revalidate it
+type httpCacheEntry struct {
+	url          string
+	etag         string
+	lastModified string
+	body         []byte
+	header       http.Header
+}
+
+// newHTTPCache creates a cache holding up to maxEntries responses
+func newHTTPCache(maxEntries int) *httpCache {
+	return &httpCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// get returns the cached response for url, marking it as recently used
+func (h *httpCache) get(url string) (*httpCacheEntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	element, ok := h.entries[url]
+	if !ok {
+		return nil, false
+	}
+	h.order.MoveToFront(element)
+	return element.Value.(*httpCacheEntry), true
+}
+
+// put stores a response, evicting the least recently used one when the cache is full
+func (h *httpCache) put(entry *httpCacheEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if element, ok := h.entries[entry.url]; ok {
+		element.Value = entry
+		h.order.MoveToFront(element)
+		return
+	}
+	h.entries[entry.url] = h.order.PushFront(entry)
+	for h.order.Len() > h.maxEntries {
+		oldest := h.order.Back()
+		h.order.Remove(oldest)
+		delete(h.entries, oldest.Value.(*httpCacheEntry).url)
+	}
+}
+
+// SetHTTPCache enables or disables caching of GET responses that carry an ETag or
+// Last-Modified header. While enabled, a GET request for a cached URL is sent as a conditional
+// request, and on 304 Not Modified the cached body is returned as if the server had sent it.
+// Requests still count against the rate limit. Up to maxEntries responses are kept, evicting the
+// least recently used; non-positive values keep DefaultHTTPCacheEntries. Calling it again
+// clears the cache.
+func (c *HTTPClient) SetHTTPCache(enabled bool, maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !enabled {
+		c.httpCache = nil
+		return
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultHTTPCacheEntries
+	}
+	c.httpCache = newHTTPCache(maxEntries)
+}
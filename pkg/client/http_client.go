@@ -2,9 +2,11 @@ package client
 
 import (
 	"context"
-	"math/rand"
+	stderrors "errors"
+	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,29 +23,112 @@ const (
 	APITypePrivate APIType = "private"
 )
 
+// ResultHook is called after every request attempt with the request URL, the HTTP
+// status code (0 if no response was received) and the transport error, if any
+type ResultHook func(url string, statusCode int, err error)
+
 // HTTPClient HTTP client wrapper with rate limiting and proxy support
 type HTTPClient struct {
 	client         *fasthttp.Client
 	customClient   *http.Client
+	proxiedClients map[string]*http.Client     // copies of customClient per proxy
+	proxyClients   map[string]*fasthttp.Client // fasthttp clients per proxy, built from client
+	http2Client    *http.Client                // customClient installed by SetHTTP2, if any
 	publicLimiter  *RateLimiter
 	privateLimiter *RateLimiter
+	maxLimitWait   time.Duration // longest wait for a rate limit token, 0 for no limit
+	dialTimeout    time.Duration // connect timeout for direct and proxied dials, 0 for the defaults
 	headers        map[string]string
 	proxies        []string
+	proxyPool      *proxyPool
 	logger         zerolog.Logger
+	resultHook     ResultHook
+	observer       RequestObserver
+	redactLogs     bool
+	debug          *roundTripLog // captured round trips, nil unless debugging is enabled
+	httpCache      *httpCache    // cached GET responses, nil unless enabled with SetHTTPCache
 	mu             sync.RWMutex
+
+	inflight sync.WaitGroup // outstanding requests
+	closed   bool           // set once Shutdown is called
 }
 
+const (
+	// DefaultMaxConnsPerHost bounds concurrent connections per host. It leaves ample
+	// headroom above exchange rate limits while preventing runaway connection growth.
+	DefaultMaxConnsPerHost = 512
+	// DefaultMaxIdleConnDuration keeps idle connections warm between bursts of
+	// order traffic so latency-sensitive requests avoid a fresh TLS handshake.
+	DefaultMaxIdleConnDuration = 30 * time.Second
+	// DefaultMaxResponseBytes caps response bodies so that a misbehaving endpoint cannot
+	// exhaust memory. It is far above the largest legitimate exchange response.
+	DefaultMaxResponseBytes = 64 << 20
+	// DefaultProxyDialTimeout bounds connecting to a proxy when no dial timeout is set.
+	// Direct connections use fasthttp's own default.
+	DefaultProxyDialTimeout = 10 * time.Second
+)
+
 // NewHTTPClient creates a new HTTP client
 func NewHTTPClient(timeout time.Duration) *HTTPClient {
-	return &HTTPClient{
-		client: &fasthttp.Client{
-			ReadTimeout:  timeout,
-			WriteTimeout: timeout,
-		},
-		headers: make(map[string]string),
-		proxies: make([]string, 0),
-		logger:  zerolog.Nop(), // Default no-op logger
+	c := &HTTPClient{
+		headers:        make(map[string]string),
+		proxies:        make([]string, 0),
+		proxyPool:      newProxyPool(),
+		proxiedClients: make(map[string]*http.Client),
+		proxyClients:   make(map[string]*fasthttp.Client),
+		logger:         zerolog.Nop(), // Default no-op logger
+		redactLogs:     true,
+	}
+	c.client = &fasthttp.Client{
+		ReadTimeout:         timeout,
+		WriteTimeout:        timeout,
+		MaxConnsPerHost:     DefaultMaxConnsPerHost,
+		MaxIdleConnDuration: DefaultMaxIdleConnDuration,
+		MaxResponseBodySize: DefaultMaxResponseBytes,
+		Dial:                c.dial,
+	}
+	return c
+}
+
+// SetDialTimeout bounds how long connecting to an endpoint or proxy may take, separately from
+// the request timeout given to NewHTTPClient. A short dial timeout makes an unreachable
+// endpoint fail fast while a long request timeout still allows large responses to be read.
+// It applies to direct and proxied fasthttp dials, proxy probes and the HTTP/2 client, but not
+// to a client set with SetCustomHTTPClient. Non-positive values restore the defaults.
+func (c *HTTPClient) SetDialTimeout(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
 	}
+	c.dialTimeout = d
+	c.resetProxiedClients()
+}
+
+// DialTimeout returns the timeout set with SetDialTimeout, or 0 when the defaults apply
+func (c *HTTPClient) DialTimeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dialTimeout
+}
+
+// dial connects to addr within the dial timeout, or fasthttp's default when none is set
+func (c *HTTPClient) dial(addr string) (net.Conn, error) {
+	if d := c.DialTimeout(); d > 0 {
+		return fasthttp.DialTimeout(addr, d)
+	}
+	return fasthttp.Dial(addr)
+}
+
+// dialContext connects to addr for net/http transports within the dial timeout, or within the
+// 30 seconds net/http's default transport allows when none is set
+func (c *HTTPClient) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	timeout := c.DialTimeout()
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	return dialer.DialContext(ctx, network, addr)
 }
 
 // SetRateLimit sets rate limiting configuration for specific API type
@@ -58,6 +143,161 @@ func (c *HTTPClient) SetRateLimit(apiType APIType, requests int, interval time.D
 	}
 }
 
+// SetRateLimiter installs an existing rate limiter for the given API type. Passing the same
+// limiter to several clients makes them share one token bucket.
+func (c *HTTPClient) SetRateLimiter(apiType APIType, limiter *RateLimiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch apiType {
+	case APITypePublic:
+		c.publicLimiter = limiter
+	case APITypePrivate:
+		c.privateLimiter = limiter
+	}
+}
+
+// SetMaxRateLimitWait bounds how long a request waits for a rate limit token. A request that
+// cannot get a token within d fails with ErrRateLimit instead of queueing, which suits callers
+// that would rather drop a request than send it late. The context deadline still applies when
+// it is sooner. Non-positive values wait as long as the context allows.
+func (c *HTTPClient) SetMaxRateLimitWait(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	c.maxLimitWait = d
+}
+
+// waitForTokens takes n tokens from limiter, waiting at most maxWait when it is positive
+func waitForTokens(ctx context.Context, limiter *RateLimiter, n int, maxWait time.Duration) error {
+	if maxWait <= 0 {
+		if err := limiter.WaitN(ctx, n); err != nil {
+			return errors.Wrap(errors.ErrRateLimit, "rate limit error", notSent(err))
+		}
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+	if err := limiter.WaitN(waitCtx, n); err != nil {
+		if ctx.Err() == nil {
+			return errors.Wrapf(errors.ErrRateLimit, notSent(nil), "no rate limit token available within %s", maxWait)
+		}
+		return errors.Wrap(errors.ErrRateLimit, "rate limit error", notSent(err))
+	}
+	return nil
+}
+
+// GetRateLimiter returns the rate limiter for the given API type, or nil if none is set
+func (c *HTTPClient) GetRateLimiter(apiType APIType) *RateLimiter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch apiType {
+	case APITypePublic:
+		return c.publicLimiter
+	case APITypePrivate:
+		return c.privateLimiter
+	}
+	return nil
+}
+
+// SetConnectionPool configures connection pooling for the underlying client and the fasthttp
+// clients used for proxies, which are rebuilt with the new settings. Non-positive values fall
+// back to DefaultMaxConnsPerHost and DefaultMaxIdleConnDuration.
+func (c *HTTPClient) SetConnectionPool(maxConnsPerHost int, idleTimeout time.Duration) {
+	if maxConnsPerHost <= 0 {
+		maxConnsPerHost = DefaultMaxConnsPerHost
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultMaxIdleConnDuration
+	}
+
+	c.mu.Lock()
+	previous := c.client
+	c.client = &fasthttp.Client{
+		ReadTimeout:         previous.ReadTimeout,
+		WriteTimeout:        previous.WriteTimeout,
+		MaxConnsPerHost:     maxConnsPerHost,
+		MaxIdleConnDuration: idleTimeout,
+		MaxResponseBodySize: previous.MaxResponseBodySize,
+		Dial:                previous.Dial,
+	}
+	c.resetProxiedClients()
+	c.mu.Unlock()
+
+	previous.CloseIdleConnections()
+}
+
+// SetMaxResponseBytes limits the size of response bodies. Larger responses fail with
+// ErrInvalidResponse without being read in full. Non-positive values restore DefaultMaxResponseBytes.
+func (c *HTTPClient) SetMaxResponseBytes(n int) {
+	if n <= 0 {
+		n = DefaultMaxResponseBytes
+	}
+
+	c.mu.Lock()
+	previous := c.client
+	c.client = &fasthttp.Client{
+		ReadTimeout:         previous.ReadTimeout,
+		WriteTimeout:        previous.WriteTimeout,
+		MaxConnsPerHost:     previous.MaxConnsPerHost,
+		MaxIdleConnDuration: previous.MaxIdleConnDuration,
+		MaxResponseBodySize: n,
+		Dial:                previous.Dial,
+	}
+	c.resetProxiedClients()
+	c.mu.Unlock()
+
+	previous.CloseIdleConnections()
+}
+
+// newProxyClient creates a client that dials through the given proxy with the base client's
+// settings. The proxy is dialed with the base client's dial timeout when one is set and
+// DefaultProxyDialTimeout otherwise.
+func newProxyClient(base *fasthttp.Client, proxy string, dialTimeout time.Duration) *fasthttp.Client {
+	if dialTimeout <= 0 {
+		dialTimeout = DefaultProxyDialTimeout
+	}
+	return &fasthttp.Client{
+		ReadTimeout:         base.ReadTimeout,
+		WriteTimeout:        base.WriteTimeout,
+		MaxConnsPerHost:     base.MaxConnsPerHost,
+		MaxIdleConnDuration: base.MaxIdleConnDuration,
+		MaxResponseBodySize: base.MaxResponseBodySize,
+		Dial: func(addr string) (net.Conn, error) {
+			return fasthttp.DialTimeout(proxyAddr(proxy), dialTimeout)
+		},
+	}
+}
+
+// proxyClient returns the fasthttp client that sends requests through proxy, built from base.
+// Clients are kept per proxy so that their connections are reused, and are dropped whenever
+// base is replaced, for example by SetConnectionPool, so they always carry its settings.
+func (c *HTTPClient) proxyClient(base *fasthttp.Client, proxy string) *fasthttp.Client {
+	c.mu.RLock()
+	proxied, ok := c.proxyClients[proxy]
+	dialTimeout := c.dialTimeout
+	c.mu.RUnlock()
+	if ok {
+		return proxied
+	}
+
+	proxied = newProxyClient(base, proxy, dialTimeout)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != base {
+		// The base client was replaced meanwhile; do not cache a client built from the old one
+		return proxied
+	}
+	if existing, ok := c.proxyClients[proxy]; ok {
+		return existing
+	}
+	c.proxyClients[proxy] = proxied
+	return proxied
+}
+
 // SetLogger sets custom logger
 func (c *HTTPClient) SetLogger(logger zerolog.Logger) {
 	c.mu.Lock()
@@ -65,11 +305,105 @@ func (c *HTTPClient) SetLogger(logger zerolog.Logger) {
 	c.logger = logger
 }
 
-// SetCustomHTTPClient sets custom HTTP client
+// SetLogRedaction enables or disables masking of credentials and bodies in logs and in the
+// messages of HTTP status errors (enabled by default). StatusError.Body always holds the full body.
+func (c *HTTPClient) SetLogRedaction(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redactLogs = enabled
+}
+
+// logBody returns the response body as it should appear in logs
+func (c *HTTPClient) logBody(body []byte) string {
+	c.mu.RLock()
+	redact := c.redactLogs
+	c.mu.RUnlock()
+	if redact {
+		return redactBody(body)
+	}
+	return string(body)
+}
+
+// logHeaders returns request headers as they should appear in logs
+func (c *HTTPClient) logHeaders(headers map[string]string) map[string]string {
+	c.mu.RLock()
+	redact := c.redactLogs
+	c.mu.RUnlock()
+	if redact {
+		return redactHeaders(headers)
+	}
+	return headers
+}
+
+// SetRequestObserver sets an observer that is notified of every request attempt with its timings
+func (c *HTTPClient) SetRequestObserver(observer RequestObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observer = observer
+}
+
+// reportResult passes the outcome of a request attempt to the result hook and request observer
+func (c *HTTPClient) reportResult(method, url string, apiType APIType, statusCode int, err error, timing RequestTiming) {
+	c.mu.RLock()
+	hook := c.resultHook
+	observer := c.observer
+	c.mu.RUnlock()
+
+	if hook != nil {
+		hook(url, statusCode, err)
+	}
+	if observer != nil {
+		observer.ObserveRequest(RequestInfo{
+			Method:     method,
+			URL:        url,
+			APIType:    apiType,
+			StatusCode: statusCode,
+			Err:        err,
+			Timing:     timing,
+		})
+	}
+}
+
+// SetResultHook sets a hook that observes the outcome of every request
+func (c *HTTPClient) SetResultHook(hook ResultHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resultHook = hook
+}
+
+// SetCustomHTTPClient sends requests through client instead of the built-in fasthttp client,
+// for example to use a custom transport, TLS configuration or instrumentation. Request timeouts,
+// rate limits and the maximum response size still apply, and so does the client's own Timeout.
+// Proxies set with SetProxies are applied to copies of the client's *http.Transport; clients
+// with another kind of transport ignore them. Passing nil restores the fasthttp client.
 func (c *HTTPClient) SetCustomHTTPClient(client *http.Client) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.customClient = client
+	c.resetProxiedClients()
+}
+
+// SetTransport sends requests through a net/http client using transport. Passing nil restores
+// the fasthttp client. See SetCustomHTTPClient.
+func (c *HTTPClient) SetTransport(transport http.RoundTripper) {
+	if transport == nil {
+		c.SetCustomHTTPClient(nil)
+		return
+	}
+	c.SetCustomHTTPClient(&http.Client{Transport: transport})
+}
+
+// resetProxiedClients drops the per-proxy copies of the custom client and the per-proxy
+// fasthttp clients, closing their idle connections. c.mu must be held.
+func (c *HTTPClient) resetProxiedClients() {
+	for _, proxied := range c.proxiedClients {
+		proxied.CloseIdleConnections()
+	}
+	c.proxiedClients = make(map[string]*http.Client)
+	for _, proxied := range c.proxyClients {
+		proxied.CloseIdleConnections()
+	}
+	c.proxyClients = make(map[string]*fasthttp.Client)
 }
 
 // SetHeaders sets custom request headers
@@ -87,6 +421,122 @@ func (c *HTTPClient) SetProxies(proxies []string) {
 	defer c.mu.Unlock()
 	c.proxies = make([]string, len(proxies))
 	copy(c.proxies, proxies)
+	c.proxyPool.reset(proxies)
+	c.resetProxiedClients()
+}
+
+// Shutdown stops accepting new requests and waits for in-flight requests to finish
+// or for ctx to be done, whichever comes first. Idle connections are closed afterwards.
+func (c *HTTPClient) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = errors.Wrap(errors.ErrTimeout, "shutdown interrupted before in-flight requests completed", ctx.Err())
+	}
+
+	c.mu.RLock()
+	baseClient := c.client
+	customClient := c.customClient
+	logger := c.logger
+	c.mu.RUnlock()
+
+	baseClient.CloseIdleConnections()
+	if customClient != nil {
+		customClient.CloseIdleConnections()
+	}
+	c.mu.Lock()
+	c.resetProxiedClients()
+	c.mu.Unlock()
+
+	logger.Debug().Err(err).Msg("HTTP client shut down")
+	return err
+}
+
+// acquire registers a new in-flight request, failing if the client has been shut down
+func (c *HTTPClient) acquire() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errors.Wrap(errors.ErrExchangeUnavailable, "HTTP client is shut down", notSent(nil))
+	}
+	c.inflight.Add(1)
+	return nil
+}
+
+// errNotSent is in the chain of every error returned before a request reached the transport
+var errNotSent = stderrors.New("request not sent")
+
+// notSent marks cause as an error returned before the request was sent
+func notSent(cause error) error {
+	if cause == nil {
+		return errNotSent
+	}
+	return fmt.Errorf("%w: %w", errNotSent, cause)
+}
+
+// RequestNotSent reports whether err was returned before the request was sent, because the
+// client was shut down, the context was done or no rate limit token was available. Such a
+// request had no effect on the exchange and can be sent again.
+func RequestNotSent(err error) bool {
+	return stderrors.Is(err, errNotSent)
+}
+
+// Rate limit response headers
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// syncRateLimiter feeds rate limit response headers, when present, into the limiter.
+// The reset header is interpreted as a Unix timestamp in seconds.
+func syncRateLimiter(limiter *RateLimiter, header *fasthttp.ResponseHeader) {
+	remainingValue := header.Peek(headerRateLimitRemaining)
+	if len(remainingValue) == 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(string(remainingValue))
+	if err != nil {
+		return
+	}
+
+	limit, _ := strconv.Atoi(string(header.Peek(headerRateLimitLimit)))
+
+	var reset time.Time
+	if resetUnix, err := strconv.ParseInt(string(header.Peek(headerRateLimitReset)), 10, 64); err == nil {
+		reset = time.Unix(resetUnix, 0)
+	}
+
+	limiter.SyncFromHeaders(remaining, limit, reset)
+}
+
+// requestTimeout returns the timeout for a request, shortened to the context deadline if sooner.
+// It fails if the context is already done.
+func requestTimeout(ctx context.Context, timeout time.Duration) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, errors.Wrap(errors.ErrTimeout, "request cancelled", notSent(err))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return 0, errors.Wrap(errors.ErrTimeout, "request cancelled", notSent(context.DeadlineExceeded))
+		}
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return timeout, nil
 }
 
 // Get sends a GET request (public API by default)
@@ -106,20 +556,121 @@ func (c *HTTPClient) GetWithType(ctx context.Context, url string, apiType APITyp
 
 // PostWithHeaders sends a POST request with custom headers
 func (c *HTTPClient) PostWithHeaders(ctx context.Context, url string, body []byte, headers map[string]string, apiType APIType) ([]byte, error) {
-	return c.requestWithHeaders(ctx, "POST", url, body, headers, apiType)
+	return c.requestWithHeaders(ctx, "POST", url, body, headers, apiType, 1, nil, nil)
+}
+
+// RequestWithWeight sends an HTTP request with custom headers that consumes weight tokens
+// from the rate limiter of apiType, for requests the exchange counts more heavily
+func (c *HTTPClient) RequestWithWeight(ctx context.Context, method, url string, body []byte, headers map[string]string, apiType APIType, weight int) ([]byte, error) {
+	return c.requestWithHeaders(ctx, method, url, body, headers, apiType, weight, nil, nil)
+}
+
+// SignFunc builds the headers of a signed request, including its nonce
+type SignFunc func() (map[string]string, error)
+
+// Sequencer serializes signed requests that share a nonce sequence, such as every request made
+// with one API key, so that they reach the server in the order their nonces were drawn
+type Sequencer struct {
+	slot chan struct{}
+}
+
+// NewSequencer creates a sequencer
+func NewSequencer() *Sequencer {
+	return &Sequencer{slot: make(chan struct{}, 1)}
+}
+
+// acquire waits until no other request holds the sequencer or ctx is done
+func (s *Sequencer) acquire(ctx context.Context) error {
+	select {
+	case s.slot <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(errors.ErrTimeout, "request cancelled", notSent(ctx.Err()))
+	}
+}
+
+// release lets the next request hold the sequencer
+func (s *Sequencer) release() {
+	<-s.slot
+}
+
+// RequestSigned sends a request whose headers are built by sign, consuming weight tokens from
+// the rate limiter of apiType. The tokens are taken first; seq is then held while sign draws the
+// nonce and the request is sent, until its response is received, so requests sharing seq reach
+// the server in nonce order. An error from sign is returned as is, and the request is not sent.
+func (c *HTTPClient) RequestSigned(ctx context.Context, method, url string, body []byte, apiType APIType, weight int, seq *Sequencer, sign SignFunc) ([]byte, error) {
+	return c.requestWithHeaders(ctx, method, url, body, nil, apiType, weight, seq, sign)
+}
+
+// GetWithResponseHeaders sends a GET request and also returns the response headers
+func (c *HTTPClient) GetWithResponseHeaders(ctx context.Context, url string, apiType APIType) ([]byte, http.Header, error) {
+	respHeaders := make(http.Header)
+	body, err := c.request(ctx, "GET", url, nil, apiType, respHeaders)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, respHeaders, nil
 }
 
 // RequestWithType sends HTTP request with specified API type
 func (c *HTTPClient) RequestWithType(ctx context.Context, method, url string, body []byte, apiType APIType) ([]byte, error) {
-	return c.request(ctx, method, url, body, apiType)
+	return c.request(ctx, method, url, body, apiType, nil)
 }
 
-// requestWithHeaders sends HTTP request with custom headers
-func (c *HTTPClient) requestWithHeaders(ctx context.Context, method, url string, body []byte, headers map[string]string, apiType APIType) ([]byte, error) {
+// Warmup sends a HEAD request to url so that DNS resolution and the TCP and TLS handshakes
+// happen before the first real request, which then reuses the pooled connection. Any HTTP
+// response counts as success. Warmup is not rate limited. Requests through proxies use their
+// own connections, so they do not benefit from it.
+func (c *HTTPClient) Warmup(ctx context.Context, url string) error {
+	if err := c.acquire(); err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+
 	c.mu.RLock()
 	logger := c.logger
+	baseClient := c.client
+	userAgent := c.headers["User-Agent"]
 	c.mu.RUnlock()
 
+	timeout, err := requestTimeout(ctx, baseClient.ReadTimeout)
+	if err != nil {
+		return err
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.Header.SetMethod(fasthttp.MethodHead)
+	req.SetRequestURI(url)
+	if userAgent != "" {
+		req.Header.SetUserAgent(userAgent)
+	}
+	resp.SkipBody = true
+
+	start := time.Now()
+	if err := c.do(ctx, baseClient, "", req, resp, timeout); err != nil {
+		logger.Error().Err(err).Str("url", url).Msg("Warmup request failed")
+		return errors.Wrap(errors.ErrNetworkError, "warmup request failed", err)
+	}
+	logger.Debug().Str("url", url).Int("status", resp.StatusCode()).Dur("duration", time.Since(start)).Msg("Connection warmed up")
+	return nil
+}
+
+// requestWithHeaders sends HTTP request with custom headers. With sign, the headers are built
+// once the rate limit tokens and seq, if any, are held.
+func (c *HTTPClient) requestWithHeaders(ctx context.Context, method, url string, body []byte, headers map[string]string, apiType APIType, weight int, seq *Sequencer, sign SignFunc) ([]byte, error) {
+	c.mu.RLock()
+	logger := c.logger
+	c.mu.RUnlock()
+
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+	started := time.Now()
+
 	// Log request
 	logger.Debug().Str("method", method).Str("url", url).Str("apiType", string(apiType)).Msg("Sending HTTP request with custom headers")
 
@@ -132,13 +683,30 @@ func (c *HTTPClient) requestWithHeaders(ctx context.Context, method, url string,
 	case APITypePrivate:
 		rateLimiter = c.privateLimiter
 	}
+	maxLimitWait := c.maxLimitWait
 	c.mu.RUnlock()
 
 	if rateLimiter != nil {
-		if err := rateLimiter.Wait(ctx); err != nil {
+		if err := waitForTokens(ctx, rateLimiter, weight, maxLimitWait); err != nil {
 			logger.Error().Err(err).Msg("Rate limit error")
-			return nil, errors.Wrap(errors.ErrRateLimit, "rate limit error", err)
+			return nil, err
+		}
+	}
+	if seq != nil {
+		if err := seq.acquire(ctx); err != nil {
+			logger.Debug().Err(err).Msg("Request cancelled while waiting for its turn")
+			return nil, err
+		}
+		defer seq.release()
+	}
+	queueWait := time.Since(started)
+
+	if sign != nil {
+		signed, err := sign()
+		if err != nil {
+			return nil, err
 		}
+		headers = signed
 	}
 
 	req := fasthttp.AcquireRequest()
@@ -162,55 +730,110 @@ func (c *HTTPClient) requestWithHeaders(ctx context.Context, method, url string,
 	}
 	proxies := make([]string, len(c.proxies))
 	copy(proxies, c.proxies)
+	baseClient := c.client
 	c.mu.RUnlock()
 
 	// Override with custom headers
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	logger.Debug().Interface("headers", c.logHeaders(headers)).Msg("Applied custom request headers")
 
 	// Select client (with or without proxy)
-	client := c.client
+	proxy := ""
 	if len(proxies) > 0 {
-		proxy := proxies[rand.Intn(len(proxies))]
-		client = &fasthttp.Client{
-			ReadTimeout:  c.client.ReadTimeout,
-			WriteTimeout: c.client.WriteTimeout,
-			Dial: func(addr string) (net.Conn, error) {
-				return fasthttp.DialTimeout(proxy, time.Second*10)
-			},
-		}
+		proxy = c.proxyPool.pick(proxies)
+	}
+
+	// Honor context cancellation and deadline
+	timeout, err := requestTimeout(ctx, baseClient.ReadTimeout)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Request cancelled before sending")
+		return nil, err
 	}
 
 	// Send request
 	start := time.Now()
-	err := client.DoTimeout(req, resp, c.client.ReadTimeout)
+	err = c.do(ctx, baseClient, proxy, req, resp, timeout)
 	duration := time.Since(start)
+	c.captureRoundTrip(req, resp, start, duration, err)
+
+	if proxy != "" {
+		c.proxyPool.record(proxy, err)
+	}
+
+	statusCode := 0
+	if err == nil {
+		statusCode = resp.StatusCode()
+	}
+	timing := RequestTiming{QueueWait: queueWait, RoundTrip: duration, Total: time.Since(started)}
+	c.reportResult(method, url, apiType, statusCode, err, timing)
 
 	if err != nil {
-		logger.Error().Err(err).Dur("duration", duration).Msg("Request failed")
-		return nil, errors.Wrap(errors.ErrNetworkError, "request failed", err)
+		logger.Error().Err(err).Dur("queueWait", queueWait).Dur("duration", duration).Msg("Request failed")
+		return nil, requestError(err, baseClient.MaxResponseBodySize)
 	}
 
 	// Log response
-	logger.Debug().Int("status", resp.StatusCode()).Dur("duration", duration).Msg("Received HTTP response")
+	logger.Debug().Int("status", resp.StatusCode()).Dur("queueWait", queueWait).Dur("duration", duration).Dur("total", timing.Total).Msg("Received HTTP response")
+
+	if rateLimiter != nil {
+		syncRateLimiter(rateLimiter, &resp.Header)
+	}
 
 	// Check response status
 	if resp.StatusCode() != fasthttp.StatusOK {
-		logger.Error().Int("status", resp.StatusCode()).Bytes("body", resp.Body()).Msg("HTTP error response")
-		return nil, errors.Newf(errors.ErrNetworkError, "HTTP error: %d %s", resp.StatusCode(), resp.Body())
+		logger.Error().Int("status", resp.StatusCode()).Str("body", c.logBody(resp.Body())).Msg("HTTP error response")
+		return nil, errors.Wrapf(errors.ErrNetworkError, newStatusError(resp), "HTTP error: %d %s", resp.StatusCode(), c.logBody(resp.Body()))
 	}
 
 	logger.Debug().Int("bodySize", len(resp.Body())).Msg("Request completed successfully")
-	return resp.Body(), nil
+	// Copy the body, since resp goes back to the pool when this function returns
+	return append([]byte(nil), resp.Body()...), nil
+}
+
+// StatusError is the cause of the error returned for a response with a status other than 200.
+// It keeps the status code and body so that exchange packages can decode their error responses.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// newStatusError copies the status and body of a pooled response
+func newStatusError(resp *fasthttp.Response) *StatusError {
+	return &StatusError{StatusCode: resp.StatusCode(), Body: append([]byte(nil), resp.Body()...)}
 }
 
-// request sends HTTP request with rate limiting and proxy support
-func (c *HTTPClient) request(ctx context.Context, method, url string, body []byte, apiType APIType) ([]byte, error) {
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP status %d", e.StatusCode)
+}
+
+// requestError wraps a transport error, reporting oversized responses as invalid rather than as
+// network failures. SDK errors, such as a proxy that cannot be used, keep their code.
+func requestError(err error, maxResponseBytes int) error {
+	if sdkErr, ok := err.(*errors.SDKError); ok {
+		return sdkErr
+	}
+	if err == fasthttp.ErrBodyTooLarge {
+		return errors.Wrapf(errors.ErrInvalidResponse, err, "response body exceeds %d bytes", maxResponseBytes)
+	}
+	return errors.Wrap(errors.ErrNetworkError, "request failed", err)
+}
+
+// request sends HTTP request with rate limiting and proxy support.
+// If respHeaders is non-nil, the response headers are copied into it.
+func (c *HTTPClient) request(ctx context.Context, method, url string, body []byte, apiType APIType, respHeaders http.Header) ([]byte, error) {
 	c.mu.RLock()
 	logger := c.logger
 	c.mu.RUnlock()
 
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+	started := time.Now()
+
 	// Log request
 	logger.Debug().Str("method", method).Str("url", url).Str("apiType", string(apiType)).Msg("Sending HTTP request")
 
@@ -223,14 +846,16 @@ func (c *HTTPClient) request(ctx context.Context, method, url string, body []byt
 	case APITypePrivate:
 		rateLimiter = c.privateLimiter
 	}
+	maxLimitWait := c.maxLimitWait
 	c.mu.RUnlock()
 
 	if rateLimiter != nil {
-		if err := rateLimiter.Wait(ctx); err != nil {
+		if err := waitForTokens(ctx, rateLimiter, 1, maxLimitWait); err != nil {
 			logger.Error().Err(err).Msg("Rate limit error")
-			return nil, errors.Wrap(errors.ErrRateLimit, "rate limit error", err)
+			return nil, err
 		}
 	}
+	queueWait := time.Since(started)
 
 	req := fasthttp.AcquireRequest()
 	resp := fasthttp.AcquireResponse()
@@ -254,40 +879,107 @@ func (c *HTTPClient) request(ctx context.Context, method, url string, body []byt
 	}
 	proxies := make([]string, len(c.proxies))
 	copy(proxies, c.proxies)
+	baseClient := c.client
+	cache := c.httpCache
 	c.mu.RUnlock()
 
+	// Revalidate a cached response instead of downloading it again
+	var cached *httpCacheEntry
+	if cache != nil && method == fasthttp.MethodGet {
+		if entry, ok := cache.get(url); ok {
+			cached = entry
+			if entry.etag != "" {
+				req.Header.Set(fasthttp.HeaderIfNoneMatch, entry.etag)
+			}
+			if entry.lastModified != "" {
+				req.Header.Set(fasthttp.HeaderIfModifiedSince, entry.lastModified)
+			}
+		}
+	}
+
 	// Select client (with or without proxy)
-	client := c.client
+	proxy := ""
 	if len(proxies) > 0 {
-		proxy := proxies[rand.Intn(len(proxies))]
-		client = &fasthttp.Client{
-			ReadTimeout:  c.client.ReadTimeout,
-			WriteTimeout: c.client.WriteTimeout,
-			Dial: func(addr string) (net.Conn, error) {
-				return fasthttp.DialTimeout(proxy, time.Second*10)
-			},
-		}
+		proxy = c.proxyPool.pick(proxies)
+	}
+
+	// Honor context cancellation and deadline
+	timeout, err := requestTimeout(ctx, baseClient.ReadTimeout)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Request cancelled before sending")
+		return nil, err
 	}
 
 	// Send request
 	start := time.Now()
-	err := client.DoTimeout(req, resp, c.client.ReadTimeout)
+	err = c.do(ctx, baseClient, proxy, req, resp, timeout)
 	duration := time.Since(start)
+	c.captureRoundTrip(req, resp, start, duration, err)
+
+	if proxy != "" {
+		c.proxyPool.record(proxy, err)
+	}
+
+	statusCode := 0
+	if err == nil {
+		statusCode = resp.StatusCode()
+	}
+	timing := RequestTiming{QueueWait: queueWait, RoundTrip: duration, Total: time.Since(started)}
+	c.reportResult(method, url, apiType, statusCode, err, timing)
 
 	if err != nil {
-		logger.Error().Err(err).Dur("duration", duration).Msg("Request failed")
-		return nil, errors.Wrap(errors.ErrNetworkError, "request failed", err)
+		logger.Error().Err(err).Dur("queueWait", queueWait).Dur("duration", duration).Msg("Request failed")
+		return nil, requestError(err, baseClient.MaxResponseBodySize)
 	}
 
 	// Log response
-	logger.Debug().Int("status", resp.StatusCode()).Dur("duration", duration).Msg("Received HTTP response")
+	logger.Debug().Int("status", resp.StatusCode()).Dur("queueWait", queueWait).Dur("duration", duration).Dur("total", timing.Total).Msg("Received HTTP response")
+
+	if rateLimiter != nil {
+		syncRateLimiter(rateLimiter, &resp.Header)
+	}
+
+	if cached != nil && resp.StatusCode() == fasthttp.StatusNotModified {
+		logger.Debug().Int("bodySize", len(cached.body)).Msg("Response not modified, serving cached body")
+		if respHeaders != nil {
+			for key, values := range cached.header {
+				respHeaders[key] = append([]string(nil), values...)
+			}
+		}
+		return append([]byte(nil), cached.body...), nil
+	}
 
 	// Check response status
 	if resp.StatusCode() != fasthttp.StatusOK {
-		logger.Error().Int("status", resp.StatusCode()).Bytes("body", resp.Body()).Msg("HTTP error response")
-		return nil, errors.Newf(errors.ErrNetworkError, "HTTP error: %d %s", resp.StatusCode(), resp.Body())
+		logger.Error().Int("status", resp.StatusCode()).Str("body", c.logBody(resp.Body())).Msg("HTTP error response")
+		return nil, errors.Wrapf(errors.ErrNetworkError, newStatusError(resp), "HTTP error: %d %s", resp.StatusCode(), c.logBody(resp.Body()))
+	}
+
+	if respHeaders != nil {
+		resp.Header.VisitAll(func(key, value []byte) {
+			respHeaders.Add(string(key), string(value))
+		})
+	}
+
+	if cache != nil && method == fasthttp.MethodGet {
+		etag := string(resp.Header.Peek(fasthttp.HeaderETag))
+		lastModified := string(resp.Header.Peek(fasthttp.HeaderLastModified))
+		if etag != "" || lastModified != "" {
+			header := make(http.Header)
+			resp.Header.VisitAll(func(key, value []byte) {
+				header.Add(string(key), string(value))
+			})
+			cache.put(&httpCacheEntry{
+				url:          url,
+				etag:         etag,
+				lastModified: lastModified,
+				body:         append([]byte(nil), resp.Body()...),
+				header:       header,
+			})
+		}
 	}
 
 	logger.Debug().Int("bodySize", len(resp.Body())).Msg("Request completed successfully")
-	return resp.Body(), nil
+	// Copy the body, since resp goes back to the pool when this function returns
+	return append([]byte(nil), resp.Body()...), nil
 }
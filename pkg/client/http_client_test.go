@@ -1,8 +1,17 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 )
 
 func TestNewHTTPClient(t *testing.T) {
@@ -28,6 +37,42 @@ func TestNewHTTPClient(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_SetConnectionPool(t *testing.T) {
+	client := NewHTTPClient(10 * time.Second)
+
+	if client.client.MaxConnsPerHost != DefaultMaxConnsPerHost {
+		t.Errorf("Expected default max conns %d, got %d", DefaultMaxConnsPerHost, client.client.MaxConnsPerHost)
+	}
+
+	cached := client.proxyClient(client.client, "proxy1:8080")
+	if client.proxyClient(client.client, "proxy1:8080") != cached {
+		t.Error("Expected the proxy client to be reused")
+	}
+
+	client.SetConnectionPool(64, time.Minute)
+
+	if client.client.MaxConnsPerHost != 64 {
+		t.Errorf("Expected max conns 64, got %d", client.client.MaxConnsPerHost)
+	}
+	if client.client.MaxIdleConnDuration != time.Minute {
+		t.Errorf("Expected idle timeout %v, got %v", time.Minute, client.client.MaxIdleConnDuration)
+	}
+	if client.client.ReadTimeout != 10*time.Second {
+		t.Errorf("Expected read timeout to be preserved, got %v", client.client.ReadTimeout)
+	}
+
+	proxyClient := client.proxyClient(client.client, "proxy1:8080")
+	if proxyClient == cached {
+		t.Error("Expected the proxy client to be rebuilt for the new pool")
+	}
+	if proxyClient.MaxConnsPerHost != 64 || proxyClient.MaxIdleConnDuration != time.Minute {
+		t.Error("Expected proxy client to inherit pool settings")
+	}
+	if client.proxyClient(client.client, "proxy1:8080") != proxyClient {
+		t.Error("Expected the rebuilt proxy client to be reused")
+	}
+}
+
 func TestHTTPClient_SetRateLimit(t *testing.T) {
 	client := NewHTTPClient(10 * time.Second)
 
@@ -90,14 +135,696 @@ func TestHTTPClient_SetProxies(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_Shutdown(t *testing.T) {
+	client := NewHTTPClient(10 * time.Second)
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	// New requests are rejected without touching the network
+	_, err := client.Get(context.Background(), "http://localhost/")
+	if errors.GetCode(err) != errors.ErrExchangeUnavailable {
+		t.Errorf("Expected %s after shutdown, got %v", errors.ErrExchangeUnavailable, err)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	headers := map[string]string{
+		"X-GEMINI-APIKEY":    "account-key",
+		"X-GEMINI-SIGNATURE": "signature",
+		"X-GEMINI-PAYLOAD":   "payload",
+		"Content-Type":       "text/plain",
+	}
+
+	redacted := redactHeaders(headers)
+	for _, k := range []string{"X-GEMINI-APIKEY", "X-GEMINI-SIGNATURE", "X-GEMINI-PAYLOAD"} {
+		if redacted[k] != redactedValue {
+			t.Errorf("Expected header %s to be redacted, got %s", k, redacted[k])
+		}
+	}
+	if redacted["Content-Type"] != "text/plain" {
+		t.Errorf("Expected Content-Type to be kept, got %s", redacted["Content-Type"])
+	}
+	if headers["X-GEMINI-APIKEY"] != "account-key" {
+		t.Error("Expected original headers to be left untouched")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	body := []byte(`{"address":"bc1qexample","amount":"1.5","apiKey":"abc"}`)
+	redacted := redactBody(body)
+	if strings.Contains(redacted, "bc1qexample") || strings.Contains(redacted, "abc\"") {
+		t.Errorf("Expected sensitive fields to be masked, got %s", redacted)
+	}
+	if !strings.Contains(redacted, `"amount":"1.5"`) {
+		t.Errorf("Expected non-sensitive fields to be kept, got %s", redacted)
+	}
+
+	long := redactBody([]byte(strings.Repeat("x", maxLoggedBodySize*2)))
+	if !strings.HasSuffix(long, "...(truncated)") {
+		t.Error("Expected long body to be truncated")
+	}
+}
+
+func TestHTTPClient_StatusErrorRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"result":"error","address":"bc1qexample"}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	requests := map[string]func() error{
+		"GET": func() error {
+			_, err := client.Get(context.Background(), server.URL)
+			return err
+		},
+		"POST with headers": func() error {
+			_, err := client.PostWithHeaders(context.Background(), server.URL, nil, map[string]string{"X-Test": "1"}, APITypePrivate)
+			return err
+		},
+	}
+	for name, request := range requests {
+		err := request()
+		if err == nil || strings.Contains(err.Error(), "bc1qexample") {
+			t.Errorf("%s: expected the error message to be redacted, got %v", name, err)
+			continue
+		}
+		// The full body stays available for parsing
+		sdkErr, ok := err.(*errors.SDKError)
+		if !ok {
+			t.Fatalf("%s: expected an SDKError, got %T", name, err)
+		}
+		if statusErr, ok := sdkErr.Cause.(*StatusError); !ok || !strings.Contains(string(statusErr.Body), "bc1qexample") {
+			t.Errorf("%s: expected the status error to keep the full body, got %v", name, sdkErr.Cause)
+		}
+	}
+
+	client.SetLogRedaction(false)
+	if err := requests["GET"](); err == nil || !strings.Contains(err.Error(), "bc1qexample") {
+		t.Errorf("Expected the raw body in the message without redaction, got %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	timeout, err := requestTimeout(context.Background(), 10*time.Second)
+	if err != nil || timeout != 10*time.Second {
+		t.Errorf("Expected 10s timeout, got %v, %v", timeout, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	timeout, err = requestTimeout(ctx, 10*time.Second)
+	if err != nil || timeout > time.Second {
+		t.Errorf("Expected timeout bounded by context deadline, got %v, %v", timeout, err)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := requestTimeout(cancelled, 10*time.Second); errors.GetCode(err) != errors.ErrTimeout {
+		t.Errorf("Expected %s for cancelled context, got %v", errors.ErrTimeout, err)
+	}
+}
+
+func TestHTTPClient_ProxyEviction(t *testing.T) {
+	client := NewHTTPClient(10 * time.Second)
+	proxies := []string{"http://proxy1:8080", "http://proxy2:8080"}
+	client.SetProxies(proxies)
+
+	dialErr := errors.New(errors.ErrNetworkError, "dial failed")
+	for i := 0; i < proxyFailureThreshold; i++ {
+		client.proxyPool.record("http://proxy1:8080", dialErr)
+	}
+	client.proxyPool.record("http://proxy2:8080", nil)
+
+	stats := client.ProxyStats()
+	if !stats["http://proxy1:8080"].Evicted {
+		t.Error("Expected proxy1 to be evicted")
+	}
+	if stats["http://proxy1:8080"].Failures != proxyFailureThreshold {
+		t.Errorf("Expected %d failures, got %d", proxyFailureThreshold, stats["http://proxy1:8080"].Failures)
+	}
+	if stats["http://proxy2:8080"].Evicted || stats["http://proxy2:8080"].Requests != 1 {
+		t.Errorf("Expected proxy2 to be healthy with 1 request, got %+v", stats["http://proxy2:8080"])
+	}
+
+	// Only the healthy proxy is selected while proxy1 cools down
+	for i := 0; i < 20; i++ {
+		if proxy := client.proxyPool.pick(proxies); proxy != "http://proxy2:8080" {
+			t.Fatalf("Expected healthy proxy to be picked, got %s", proxy)
+		}
+	}
+}
+
+func TestHTTPClient_RequestObserver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	client.SetRateLimit(APITypePublic, 1, 100*time.Millisecond)
+
+	var infos []RequestInfo
+	client.SetRequestObserver(RequestObserverFunc(func(info RequestInfo) {
+		infos = append(infos, info)
+	}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetWithType(context.Background(), server.URL, APITypePublic); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 observed requests, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.StatusCode != http.StatusOK || info.Err != nil || info.Method != "GET" || info.APIType != APITypePublic {
+			t.Errorf("Unexpected request info: %+v", info)
+		}
+		if info.Timing.Total < info.Timing.QueueWait+info.Timing.RoundTrip {
+			t.Errorf("Expected total to cover queue wait and round trip, got %+v", info.Timing)
+		}
+	}
+	// The second request waits for the rate limiter to refill
+	if infos[1].Timing.QueueWait < 50*time.Millisecond {
+		t.Errorf("Expected second request to be queued by the rate limiter, got %v", infos[1].Timing.QueueWait)
+	}
+}
+
 // TestHTTPClient_Get is skipped to avoid network dependencies in unit tests
 // Integration tests should be run separately
+func TestHTTPClient_Warmup(t *testing.T) {
+	var methods []string
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodHead {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	if err := client.Warmup(context.Background(), server.URL); err != nil {
+		t.Fatalf("Expected warmup to accept any HTTP response, got %v", err)
+	}
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(methods) != 2 || methods[0] != http.MethodHead {
+		t.Errorf("Expected a HEAD warmup before the request, got %v", methods)
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("Expected the request to reuse the warmed connection, got %d connections", n)
+	}
+
+	server.Close()
+	if err := client.Warmup(context.Background(), server.URL); err == nil {
+		t.Error("Expected warmup to fail when the server is unreachable")
+	}
+}
+
+func TestHTTPClient_SetMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	if client.client.MaxResponseBodySize != DefaultMaxResponseBytes {
+		t.Errorf("Expected default max response size %d, got %d", DefaultMaxResponseBytes, client.client.MaxResponseBodySize)
+	}
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	client.SetMaxResponseBytes(1024)
+	_, err := client.Get(context.Background(), server.URL)
+	if errors.GetCode(err) != errors.ErrInvalidResponse {
+		t.Errorf("Expected ErrInvalidResponse for an oversized response, got %v", err)
+	}
+	_, err = client.PostWithHeaders(context.Background(), server.URL, nil, map[string]string{"X-Test": "1"}, APITypePrivate)
+	if errors.GetCode(err) != errors.ErrInvalidResponse {
+		t.Errorf("Expected ErrInvalidResponse for an oversized response with headers, got %v", err)
+	}
+
+	client.SetConnectionPool(64, time.Minute)
+	if client.client.MaxResponseBodySize != 1024 {
+		t.Errorf("Expected max response size to survive pool changes, got %d", client.client.MaxResponseBodySize)
+	}
+	if proxyClient := newProxyClient(client.client, "proxy1:8080", 0); proxyClient.MaxResponseBodySize != 1024 {
+		t.Error("Expected proxy client to inherit the max response size")
+	}
+}
+
+func TestHTTPClient_SetDialTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(30 * time.Second)
+	client.SetDialTimeout(time.Second)
+	if client.DialTimeout() != time.Second {
+		t.Errorf("Expected dial timeout 1s, got %v", client.DialTimeout())
+	}
+
+	// The dial timeout survives pool changes and does not shorten requests
+	client.SetConnectionPool(64, time.Minute)
+	if client.client.Dial == nil {
+		t.Error("Expected the dialer to survive pool changes")
+	}
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	client.SetDialTimeout(-1)
+	if client.DialTimeout() != 0 {
+		t.Errorf("Expected negative dial timeout to restore the defaults, got %v", client.DialTimeout())
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHTTPClient_SetTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Echo", r.Header.Get("X-Test"))
+		_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+	}))
+	defer server.Close()
+
+	var calls int32
+	client := NewHTTPClient(10 * time.Second)
+	client.SetTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	}))
+
+	body, err := client.PostWithHeaders(context.Background(), server.URL, []byte("{}"), map[string]string{"X-Test": "1"}, APITypePrivate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(body) != 2048 {
+		t.Errorf("Expected 2048 byte body, got %d", len(body))
+	}
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 requests through the transport, got %d", got)
+	}
+
+	client.SetMaxResponseBytes(1024)
+	_, err = client.Get(context.Background(), server.URL)
+	if errors.GetCode(err) != errors.ErrInvalidResponse {
+		t.Errorf("Expected ErrInvalidResponse for an oversized response, got %v", err)
+	}
+
+	client.SetTransport(nil)
+	client.SetMaxResponseBytes(0)
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected requests to bypass a removed transport, got %d calls", got)
+	}
+}
+
+func TestHTTPClient_CustomClientProxy(t *testing.T) {
+	var proxiedHost atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost.Store(r.URL.Host)
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	}))
+	defer proxy.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	client.SetCustomHTTPClient(&http.Client{Transport: &http.Transport{}})
+	client.SetProxies([]string{strings.TrimPrefix(proxy.URL, "http://")})
+
+	body, err := client.Get(context.Background(), "http://exchange.invalid/v1/symbols")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(body) != `{"result":"ok"}` {
+		t.Errorf("Unexpected body: %s", body)
+	}
+	if host, _ := proxiedHost.Load().(string); host != "exchange.invalid" {
+		t.Errorf("Expected the proxy to receive the request for exchange.invalid, got %q", host)
+	}
+
+	// A proxy without a scheme is cached under its normalized URL and reused
+	if _, err := client.Get(context.Background(), "http://exchange.invalid/v1/symbols"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(client.proxiedClients) != 1 {
+		t.Errorf("Expected one cached proxied client, got %d", len(client.proxiedClients))
+	}
+
+	// A proxy that cannot be used fails instead of sending the request directly
+	client.SetProxies([]string{"http://bad host:80"})
+	if _, err := client.Get(context.Background(), "http://exchange.invalid/v1/symbols"); errors.GetCode(err) != errors.ErrInvalidInput {
+		t.Errorf("Expected ErrInvalidInput for an unparsable proxy, got %v", err)
+	}
+	client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Error("Expected no request without the proxy")
+		return nil, fmt.Errorf("unexpected request")
+	}))
+	client.SetProxies([]string{strings.TrimPrefix(proxy.URL, "http://")})
+	if _, err := client.Get(context.Background(), "http://exchange.invalid/v1/symbols"); errors.GetCode(err) != errors.ErrInvalidInput {
+		t.Errorf("Expected ErrInvalidInput for a transport that cannot use a proxy, got %v", err)
+	}
+}
+
+func TestHTTPClient_SetHTTP2(t *testing.T) {
+	var protocols []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protocols = append(protocols, r.Proto)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	client.SetConnectionPool(16, time.Minute)
+	client.SetHTTP2(true)
+	if !client.HTTP2Enabled() {
+		t.Fatal("Expected HTTP/2 to be enabled")
+	}
+	transport, ok := client.customClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, got %T", client.customClient.Transport)
+	}
+	if !transport.ForceAttemptHTTP2 || transport.MaxConnsPerHost != 16 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("Unexpected transport settings: ForceAttemptHTTP2=%v MaxConnsPerHost=%d IdleConnTimeout=%v",
+			transport.ForceAttemptHTTP2, transport.MaxConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	// Trust the test server's certificate
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(protocols) != 1 || protocols[0] != "HTTP/2.0" {
+		t.Errorf("Expected an HTTP/2 request, got %v", protocols)
+	}
+
+	client.SetHTTP2(false)
+	if client.HTTP2Enabled() || client.customClient != nil {
+		t.Error("Expected disabling HTTP/2 to restore the fasthttp client")
+	}
+
+	// A custom client set afterwards takes over from HTTP/2
+	client.SetHTTP2(true)
+	client.SetCustomHTTPClient(&http.Client{})
+	if client.HTTP2Enabled() {
+		t.Error("Expected a custom client to replace the HTTP/2 client")
+	}
+	client.SetHTTP2(false)
+	if client.customClient == nil {
+		t.Error("Expected disabling HTTP/2 to keep a custom client")
+	}
+}
+
+func TestHTTPClient_Debug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, `{"result":"error","reason":"EndpointNotFound"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"address":"bc1qxy2k","currency":"btc"}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := client.LastRoundTrip(); ok {
+		t.Error("Expected no round trips to be captured before debugging is enabled")
+	}
+
+	client.EnableDebug()
+	headers := map[string]string{"X-GEMINI-APIKEY": "secret-key", "X-Trace": "abc"}
+	if _, err := client.PostWithHeaders(context.Background(), server.URL+"/v1/addresses", nil, headers, APITypePrivate); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.Get(context.Background(), server.URL+"/missing"); err == nil {
+		t.Fatal("Expected an error for a 404 response")
+	}
+
+	trip, ok := client.LastRoundTrip()
+	if !ok {
+		t.Fatal("Expected a captured round trip")
+	}
+	if trip.StatusCode != http.StatusNotFound || !strings.Contains(trip.ResponseBody, "EndpointNotFound") {
+		t.Errorf("Expected the failed response to be captured, got %d %q", trip.StatusCode, trip.ResponseBody)
+	}
+
+	trips := client.RoundTrips()
+	if len(trips) != 2 {
+		t.Fatalf("Expected 2 captured round trips, got %d", len(trips))
+	}
+	first := trips[0]
+	if first.Method != http.MethodPost || first.URL != server.URL+"/v1/addresses" {
+		t.Errorf("Unexpected request %s %s", first.Method, first.URL)
+	}
+	if first.RequestHeaders["X-Gemini-Apikey"] != redactedValue || first.RequestHeaders["X-Trace"] != "abc" {
+		t.Errorf("Expected only credentials to be masked, got %v", first.RequestHeaders)
+	}
+	if strings.Contains(first.ResponseBody, "bc1qxy2k") || !strings.Contains(first.ResponseBody, `"currency":"btc"`) {
+		t.Errorf("Expected sensitive response fields to be masked, got %q", first.ResponseBody)
+	}
+
+	// Only the most recent round trips are kept
+	for i := 0; i < DebugHistorySize+5; i++ {
+		_, _ = client.Get(context.Background(), fmt.Sprintf("%s/?n=%d", server.URL, i))
+	}
+	trips = client.RoundTrips()
+	if len(trips) != DebugHistorySize {
+		t.Fatalf("Expected %d captured round trips, got %d", DebugHistorySize, len(trips))
+	}
+	if last := trips[len(trips)-1].URL; !strings.HasSuffix(last, fmt.Sprintf("n=%d", DebugHistorySize+4)) {
+		t.Errorf("Expected the newest round trip last, got %s", last)
+	}
+
+	client.DisableDebug()
+	if _, ok := client.LastRoundTrip(); ok {
+		t.Error("Expected captured round trips to be discarded")
+	}
+}
+
 func TestHTTPClient_Get(t *testing.T) {
 	t.Skip("Skipping network-dependent test")
 }
 
 // TestHTTPClient_RateLimitIntegration is skipped to avoid network dependencies
 // Rate limiting is tested separately in rate_limiter_test.go
+func TestHTTPClient_RequestSigned(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("X-Block") != "" {
+			<-release
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	seq := NewSequencer()
+	var signs int32
+	sign := func(headers map[string]string) SignFunc {
+		return func() (map[string]string, error) {
+			atomic.AddInt32(&signs, 1)
+			return headers, nil
+		}
+	}
+
+	// The sequencer is held until the response arrives, so a second request waits without signing
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.RequestSigned(context.Background(), "POST", server.URL, nil, APITypePrivate, 1, seq, sign(map[string]string{"X-Block": "1"}))
+		done <- err
+	}()
+	for atomic.LoadInt32(&hits) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.RequestSigned(ctx, "POST", server.URL, nil, APITypePrivate, 1, seq, sign(nil))
+	if errors.GetCode(err) != errors.ErrTimeout || !RequestNotSent(err) {
+		t.Errorf("Expected an unsent ErrTimeout while the sequencer is held, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if signs != 1 {
+		t.Errorf("Expected only the sent request to be signed, got %d signatures", signs)
+	}
+
+	// Requests are only signed once they hold their rate limit tokens
+	client.SetRateLimit(APITypePrivate, 1, time.Hour)
+	client.SetMaxRateLimitWait(50 * time.Millisecond)
+	if _, err := client.RequestSigned(context.Background(), "POST", server.URL, nil, APITypePrivate, 1, seq, sign(nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = client.RequestSigned(context.Background(), "POST", server.URL, nil, APITypePrivate, 1, seq, sign(nil))
+	if errors.GetCode(err) != errors.ErrRateLimit || signs != 2 {
+		t.Errorf("Expected ErrRateLimit without signing, got %v after %d signatures", err, signs)
+	}
+
+	// A signing error is returned as is and nothing is sent
+	client.SetRateLimit(APITypePrivate, 100, time.Second)
+	signErr := errors.New(errors.ErrInvalidSignature, "no key")
+	_, err = client.RequestSigned(context.Background(), "POST", server.URL, nil, APITypePrivate, 1, seq, func() (map[string]string, error) {
+		return nil, signErr
+	})
+	if err != signErr || hits != 2 {
+		t.Errorf("Expected the signing error without a request, got %v after %d requests", err, hits)
+	}
+}
+
+func TestHTTPClient_SetMaxRateLimitWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(10 * time.Second)
+	client.SetRateLimit(APITypePublic, 1, time.Hour)
+	client.SetMaxRateLimitWait(50 * time.Millisecond)
+
+	if _, err := client.GetWithType(context.Background(), server.URL, APITypePublic); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	_, err := client.GetWithType(context.Background(), server.URL, APITypePublic)
+	if errors.GetCode(err) != errors.ErrRateLimit {
+		t.Errorf("Expected ErrRateLimit for a starved bucket, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop after the cap, took %v", elapsed)
+	}
+
+	// A sooner context deadline still wins over the cap
+	client.SetMaxRateLimitWait(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = client.PostWithHeaders(ctx, server.URL, nil, nil, APITypePublic)
+	if errors.GetCode(err) != errors.ErrRateLimit {
+		t.Errorf("Expected ErrRateLimit when the context expires, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop at the context deadline, took %v", elapsed)
+	}
+}
+
 func TestHTTPClient_RateLimitIntegration(t *testing.T) {
 	t.Skip("Skipping network-dependent test")
 }
+
+func TestHTTPClient_SetHTTPCache(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/v1/symbols":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write([]byte(`["btcusd","ethusd"]`))
+		case "/v1/pricefeed":
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			if r.Header.Get("If-Modified-Since") != "" {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"pair":"BTCUSD","price":"100"}]`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5 * time.Second)
+
+	// Without the cache, no conditional requests are sent
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(context.Background(), server.URL+"/v1/symbols"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if notModified != 0 {
+		t.Errorf("Expected no conditional requests without the cache, got %d", notModified)
+	}
+
+	client.SetHTTPCache(true, 1)
+	for i := 0; i < 3; i++ {
+		body, headers, err := client.GetWithResponseHeaders(context.Background(), server.URL+"/v1/symbols", APITypePublic)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(body) != `["btcusd","ethusd"]` {
+			t.Errorf("Expected the cached body, got %s", body)
+		}
+		if headers.Get("ETag") != `"v1"` {
+			t.Errorf("Expected the ETag header, got %q", headers.Get("ETag"))
+		}
+	}
+	if notModified != 2 {
+		t.Errorf("Expected 2 requests answered with 304, got %d", notModified)
+	}
+
+	// A full cache evicts the least recently used response
+	if body, err := client.Get(context.Background(), server.URL+"/v1/pricefeed"); err != nil || string(body) != `[{"pair":"BTCUSD","price":"100"}]` {
+		t.Fatalf("Unexpected response %s: %v", body, err)
+	}
+	if body, err := client.Get(context.Background(), server.URL+"/v1/pricefeed"); err != nil || string(body) != `[{"pair":"BTCUSD","price":"100"}]` {
+		t.Fatalf("Unexpected cached response %s: %v", body, err)
+	}
+	if _, err := client.Get(context.Background(), server.URL+"/v1/symbols"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if notModified != 3 {
+		t.Errorf("Expected the evicted symbols response to be fetched in full, got %d 304 responses", notModified)
+	}
+
+	// Responses without validators are not cached
+	client.SetHTTPCache(true, 0)
+	before := requests
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(context.Background(), server.URL+"/v1/other"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if requests-before != 2 || notModified != 3 {
+		t.Errorf("Expected uncached responses to be fetched in full")
+	}
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// SetHTTP2 switches requests to HTTP/2 where the server supports it. fasthttp only speaks
+// HTTP/1.1, so enabling HTTP/2 sends requests through a net/http client instead, replacing any
+// client set with SetCustomHTTPClient. HTTP/2 is negotiated during the TLS handshake; plain
+// http URLs keep using HTTP/1.1.
+//
+// HTTP/2 multiplexes requests over one connection per host, which suits CDN-fronted endpoints
+// and proxies that limit connections. In exchange, net/http allocates more per request than
+// fasthttp, and a single slow or lossy connection delays every request sharing it. Disabling
+// HTTP/2 restores the fasthttp client.
+func (c *HTTPClient) SetHTTP2(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !enabled {
+		if c.http2Client != nil && c.customClient == c.http2Client {
+			c.http2Client.CloseIdleConnections()
+			c.customClient = nil
+			c.resetProxiedClients()
+		}
+		c.http2Client = nil
+		return
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxConnsPerHost = c.client.MaxConnsPerHost
+	transport.IdleConnTimeout = c.client.MaxIdleConnDuration
+	transport.DialContext = c.dialContext
+	c.http2Client = &http.Client{Transport: transport}
+	c.customClient = c.http2Client
+	c.resetProxiedClients()
+}
+
+// HTTP2Enabled reports whether requests are sent with the client installed by SetHTTP2
+func (c *HTTPClient) HTTP2Enabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.http2Client != nil && c.customClient == c.http2Client
+}
+
+// do sends req and reads the response into resp. It uses the custom net/http client when one
+// is set and fasthttp otherwise, routing the request through proxy when it is not empty.
+func (c *HTTPClient) do(ctx context.Context, base *fasthttp.Client, proxy string, req *fasthttp.Request, resp *fasthttp.Response, timeout time.Duration) error {
+	c.mu.RLock()
+	custom := c.customClient
+	c.mu.RUnlock()
+
+	if custom == nil {
+		client := base
+		if proxy != "" {
+			client = c.proxyClient(base, proxy)
+		}
+		return client.DoTimeout(req, resp, timeout)
+	}
+
+	if proxy != "" {
+		proxied, err := c.proxiedHTTPClient(custom, proxy)
+		if err != nil {
+			return err
+		}
+		custom = proxied
+	}
+	return doNetHTTP(ctx, custom, req, resp, timeout, base.MaxResponseBodySize)
+}
+
+// proxiedHTTPClient returns a copy of custom whose transport sends requests through proxy.
+// Copies are kept per proxy URL so that their connections are reused. A proxy that cannot be
+// parsed, or a client whose transport is not an *http.Transport, fails with ErrInvalidInput
+// rather than sending the request without the proxy.
+func (c *HTTPClient) proxiedHTTPClient(custom *http.Client, proxy string) (*http.Client, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	c.mu.RLock()
+	proxied, ok := c.proxiedClients[proxy]
+	c.mu.RUnlock()
+	if ok {
+		return proxied, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, err, "invalid proxy %q", proxy)
+	}
+	var transport *http.Transport
+	switch t := custom.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, errors.Newf(errors.ErrInvalidInput, "cannot route requests through proxy %q: custom transport %T is not an *http.Transport", proxy, t)
+	}
+	transport.Proxy = http.ProxyURL(proxyURL)
+	clone := *custom
+	clone.Transport = transport
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.customClient != custom {
+		// The custom client was replaced meanwhile; do not cache a copy of the old one
+		return &clone, nil
+	}
+	if existing, ok := c.proxiedClients[proxy]; ok {
+		transport.CloseIdleConnections()
+		return existing, nil
+	}
+	c.proxiedClients[proxy] = &clone
+	return &clone, nil
+}
+
+// doNetHTTP sends a fasthttp request through a net/http client and fills resp with the
+// response, so the rest of the request path does not depend on which client sent it.
+// Responses larger than maxBodySize fail with fasthttp.ErrBodyTooLarge, as with fasthttp.
+func doNetHTTP(ctx context.Context, client *http.Client, req *fasthttp.Request, resp *fasthttp.Response, timeout time.Duration, maxBodySize int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if len(req.Body()) > 0 {
+		body = bytes.NewReader(req.Body())
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, string(req.Header.Method()), req.URI().String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		switch strings.ToLower(string(key)) {
+		case "host", "content-length":
+			// Set by net/http from the URL and body
+		default:
+			httpReq.Header.Set(string(key), string(value))
+		}
+	})
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	reader := io.Reader(httpResp.Body)
+	if maxBodySize > 0 {
+		reader = io.LimitReader(httpResp.Body, int64(maxBodySize)+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if maxBodySize > 0 && len(data) > maxBodySize {
+		return fasthttp.ErrBodyTooLarge
+	}
+
+	resp.SetStatusCode(httpResp.StatusCode)
+	for key, values := range httpResp.Header {
+		if strings.EqualFold(key, "Content-Length") {
+			continue
+		}
+		for _, value := range values {
+			resp.Header.Add(key, value)
+		}
+	}
+	resp.SetBody(data)
+	return nil
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"time"
+)
+
+// RequestTiming breaks down where the time of a request was spent.
+// fasthttp does not expose connection level tracing, so DNS, connect and TLS
+// time are all part of RoundTrip.
+type RequestTiming struct {
+	QueueWait time.Duration // Time spent waiting for the rate limiter
+	RoundTrip time.Duration // Time from sending the request until the response was read
+	Total     time.Duration // Time from the call until the response was read
+}
+
+// RequestInfo describes a completed request attempt
+type RequestInfo struct {
+	Method     string        // HTTP method
+	URL        string        // Request URL
+	APIType    APIType       // Public or private API
+	StatusCode int           // HTTP status code, 0 if no response was received
+	Err        error         // Transport error, if any
+	Timing     RequestTiming // Phase timings
+}
+
+// RequestObserver is notified after every request attempt, for example to export latency metrics.
+// It is called synchronously on the request path, so implementations should be fast.
+type RequestObserver interface {
+	ObserveRequest(info RequestInfo)
+}
+
+// RequestObserverFunc adapts a function to a RequestObserver
+type RequestObserverFunc func(info RequestInfo)
+
+// ObserveRequest calls f(info)
+func (f RequestObserverFunc) ObserveRequest(info RequestInfo) {
+	f(info)
+}
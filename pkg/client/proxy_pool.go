@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// proxyFailureThreshold is the number of consecutive failures before a proxy is evicted
+	proxyFailureThreshold = 3
+	// proxyEvictionCooldown is how long an evicted proxy is skipped before being re-admitted
+	proxyEvictionCooldown = time.Minute
+)
+
+// ProxyStat reports the health of a single proxy
+type ProxyStat struct {
+	Requests            int64     `json:"requests"`             // Requests sent through the proxy
+	Failures            int64     `json:"failures"`             // Requests that failed at the transport level
+	ConsecutiveFailures int       `json:"consecutive_failures"` // Failures since the last success
+	Evicted             bool      `json:"evicted"`              // Whether the proxy is currently skipped
+	EvictedUntil        time.Time `json:"evicted_until"`        // When the proxy is re-admitted
+}
+
+// proxyPool selects proxies at random while routing around ones that keep failing
+type proxyPool struct {
+	stats map[string]*ProxyStat
+	mu    sync.Mutex
+}
+
+// newProxyPool creates an empty proxy pool
+func newProxyPool() *proxyPool {
+	return &proxyPool{
+		stats: make(map[string]*ProxyStat),
+	}
+}
+
+// reset replaces the proxy list, keeping stats for proxies that remain
+func (p *proxyPool) reset(proxies []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make(map[string]*ProxyStat, len(proxies))
+	for _, proxy := range proxies {
+		if stat, ok := p.stats[proxy]; ok {
+			stats[proxy] = stat
+		} else {
+			stats[proxy] = &ProxyStat{}
+		}
+	}
+	p.stats = stats
+}
+
+// pick returns a random healthy proxy from the list. Evicted proxies are re-admitted once
+// their cooldown expires; if every proxy is evicted, any proxy may be returned.
+func (p *proxyPool) pick(proxies []string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		stat, ok := p.stats[proxy]
+		if !ok || !stat.Evicted {
+			healthy = append(healthy, proxy)
+			continue
+		}
+		if !now.Before(stat.EvictedUntil) {
+			stat.Evicted = false
+			stat.ConsecutiveFailures = 0
+			healthy = append(healthy, proxy)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = proxies
+	}
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// record updates proxy stats with the outcome of a request
+func (p *proxyPool) record(proxy string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stat, ok := p.stats[proxy]
+	if !ok {
+		return
+	}
+	stat.Requests++
+	if err == nil {
+		stat.ConsecutiveFailures = 0
+		return
+	}
+	stat.Failures++
+	stat.ConsecutiveFailures++
+	if stat.ConsecutiveFailures >= proxyFailureThreshold {
+		p.evict(stat)
+	}
+}
+
+// evict marks a proxy as unusable until the cooldown expires. Must be called with p.mu held.
+func (p *proxyPool) evict(stat *ProxyStat) {
+	stat.Evicted = true
+	stat.EvictedUntil = time.Now().Add(proxyEvictionCooldown)
+}
+
+// snapshot returns a copy of all proxy stats
+func (p *proxyPool) snapshot() map[string]ProxyStat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make(map[string]ProxyStat, len(p.stats))
+	for proxy, stat := range p.stats {
+		stats[proxy] = *stat
+	}
+	return stats
+}
+
+// ProxyStats returns health statistics for each configured proxy
+func (c *HTTPClient) ProxyStats() map[string]ProxyStat {
+	return c.proxyPool.snapshot()
+}
+
+// ProbeProxies checks that each configured proxy accepts TCP connections, evicting
+// the ones that do not and re-admitting the ones that do. It is a lightweight health
+// probe that can be run periodically; requests do not depend on it.
+func (c *HTTPClient) ProbeProxies(ctx context.Context) {
+	c.mu.RLock()
+	proxies := make([]string, len(c.proxies))
+	copy(proxies, c.proxies)
+	dialTimeout := c.dialTimeout
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, proxy := range proxies {
+		wg.Add(1)
+		go func(proxy string) {
+			defer wg.Done()
+			dialer := net.Dialer{Timeout: dialTimeout}
+			conn, err := dialer.DialContext(ctx, "tcp", proxyAddr(proxy))
+			if err == nil {
+				_ = conn.Close()
+			}
+
+			c.proxyPool.mu.Lock()
+			defer c.proxyPool.mu.Unlock()
+			stat, ok := c.proxyPool.stats[proxy]
+			if !ok {
+				return
+			}
+			if err != nil {
+				c.proxyPool.evict(stat)
+				return
+			}
+			stat.Evicted = false
+			stat.ConsecutiveFailures = 0
+		}(proxy)
+	}
+	wg.Wait()
+}
+
+// proxyAddr strips an optional scheme from a proxy URL, leaving host:port
+func proxyAddr(proxy string) string {
+	if i := strings.Index(proxy, "://"); i >= 0 {
+		return proxy[i+3:]
+	}
+	return proxy
+}
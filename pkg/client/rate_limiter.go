@@ -3,62 +3,110 @@ package client
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Clock provides the current time and timers, allowing time to be simulated in tests
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
 // RateLimiter implements token bucket rate limiting
 type RateLimiter struct {
 	tokens     int           // current available tokens
 	maxTokens  int           // maximum tokens
 	interval   time.Duration // refill interval
 	lastRefill time.Time     // last refill time
+	clock      Clock         // time source
 	mu         sync.Mutex    // mutex for thread safety
+
+	totalWaits        atomic.Int64 // number of Wait calls that had to block
+	totalWaitDuration atomic.Int64 // cumulative blocked time in nanoseconds
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(maxTokens int, interval time.Duration) *RateLimiter {
+	return NewRateLimiterWithClock(maxTokens, interval, realClock{})
+}
+
+// NewRateLimiterWithClock creates a new rate limiter that reads time from the given clock
+func NewRateLimiterWithClock(maxTokens int, interval time.Duration, clock Clock) *RateLimiter {
 	return &RateLimiter{
 		tokens:     maxTokens,
 		maxTokens:  maxTokens,
 		interval:   interval,
-		lastRefill: time.Now(),
+		lastRefill: clock.Now(),
+		clock:      clock,
+	}
+}
+
+// refill adds one token per elapsed interval. lastRefill advances by whole
+// intervals so partial progress towards the next token is not lost.
+// Must be called with rl.mu held.
+func (rl *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(rl.lastRefill)
+	if elapsed < rl.interval {
+		return
 	}
+	periods := int(elapsed / rl.interval)
+	rl.tokens = min(rl.maxTokens, rl.tokens+periods)
+	rl.lastRefill = rl.lastRefill.Add(time.Duration(periods) * rl.interval)
 }
 
 // Wait waits for a token to become available
 func (rl *RateLimiter) Wait(ctx context.Context) error {
+	return rl.WaitN(ctx, 1)
+}
+
+// WaitN waits until n tokens are available and consumes them, for requests the
+// exchange counts more than once. n is capped at the bucket capacity.
+func (rl *RateLimiter) WaitN(ctx context.Context, n int) error {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	// Refill tokens based on elapsed time
-	now := time.Now()
-	elapsed := now.Sub(rl.lastRefill)
-	if elapsed >= rl.interval {
-		periods := int(elapsed / rl.interval)
-		rl.tokens = min(rl.maxTokens, rl.tokens+periods)
-		rl.lastRefill = now
-	}
+	var waitStart time.Time
+	for {
+		// Refill tokens based on elapsed time
+		now := rl.clock.Now()
+		rl.refill(now)
 
-	// If no tokens available, wait
-	if rl.tokens <= 0 {
-		waitTime := rl.interval - (now.Sub(rl.lastRefill) % rl.interval)
+		// Consume the tokens
+		need := max(1, min(n, rl.maxTokens))
+		if rl.tokens >= need {
+			rl.tokens -= need
+			if !waitStart.IsZero() {
+				rl.totalWaitDuration.Add(int64(now.Sub(waitStart)))
+			}
+			return nil
+		}
+
+		// If not enough tokens are available, wait until the next refill
+		if waitStart.IsZero() {
+			waitStart = now
+			rl.totalWaits.Add(1)
+		}
+		waitTime := rl.interval - now.Sub(rl.lastRefill)
 		rl.mu.Unlock()
 
 		select {
 		case <-ctx.Done():
+			rl.totalWaitDuration.Add(int64(rl.clock.Now().Sub(waitStart)))
+			rl.mu.Lock()
 			return ctx.Err()
-		case <-time.After(waitTime):
+		case <-rl.clock.After(waitTime):
 			// Continue after waiting
 		}
 
 		rl.mu.Lock()
-		rl.tokens = 1
-		rl.lastRefill = time.Now()
 	}
-
-	// Consume a token
-	rl.tokens--
-	return nil
 }
 
 // TryAcquire attempts to acquire a token without waiting
@@ -67,13 +115,7 @@ func (rl *RateLimiter) TryAcquire() bool {
 	defer rl.mu.Unlock()
 
 	// Refill tokens based on elapsed time
-	now := time.Now()
-	elapsed := now.Sub(rl.lastRefill)
-	if elapsed >= rl.interval {
-		periods := int(elapsed / rl.interval)
-		rl.tokens = min(rl.maxTokens, rl.tokens+periods)
-		rl.lastRefill = now
-	}
+	rl.refill(rl.clock.Now())
 
 	// Check if tokens are available
 	if rl.tokens <= 0 {
@@ -85,6 +127,50 @@ func (rl *RateLimiter) TryAcquire() bool {
 	return true
 }
 
+// SyncFromHeaders aligns the bucket with the exchange's own accounting, as reported by
+// rate limit response headers. A positive limit replaces the bucket capacity, remaining
+// replaces the available tokens, and a future reset time delays the next refill until then.
+func (rl *RateLimiter) SyncFromHeaders(remaining, limit int, reset time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if limit > 0 {
+		rl.maxTokens = limit
+	}
+	rl.tokens = max(0, min(remaining, rl.maxTokens))
+
+	if reset.After(rl.clock.Now()) {
+		rl.lastRefill = reset.Add(-rl.interval)
+	}
+}
+
+// AvailableTokens returns the number of tokens currently available
+func (rl *RateLimiter) AvailableTokens() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill(rl.clock.Now())
+	return rl.tokens
+}
+
+// TotalWaits returns the number of Wait calls that had to block for a token
+func (rl *RateLimiter) TotalWaits() int64 {
+	return rl.totalWaits.Load()
+}
+
+// TotalWaitDuration returns the cumulative time spent blocked in Wait
+func (rl *RateLimiter) TotalWaitDuration() time.Duration {
+	return time.Duration(rl.totalWaitDuration.Load())
+}
+
+// max returns the maximum of two integers
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // min returns the minimum of two integers
 func min(a, b int) int {
 	if a < b {
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// redactedValue replaces sensitive values in logs
+	redactedValue = "[REDACTED]"
+	// maxLoggedBodySize is the number of body bytes kept in logs when redaction is enabled
+	maxLoggedBodySize = 256
+)
+
+// sensitiveHeaders lists headers whose values must never be logged (lower-cased)
+var sensitiveHeaders = map[string]bool{
+	"x-gemini-apikey":    true,
+	"x-gemini-signature": true,
+	"x-gemini-payload":   true,
+	"authorization":      true,
+}
+
+// sensitiveBodyField matches JSON string fields whose values should be masked
+var sensitiveBodyField = regexp.MustCompile(`(?i)("[^"]*(?:key|secret|signature|payload|address)[^"]*"\s*:\s*)"[^"]*"`)
+
+// redactHeaders returns a copy of headers with sensitive values masked
+func redactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if sensitiveHeaders[strings.ToLower(k)] {
+			v = redactedValue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// redactBody masks sensitive JSON fields and truncates the body for logging
+func redactBody(body []byte) string {
+	masked := maskBody(body)
+	if len(masked) > maxLoggedBodySize {
+		masked = masked[:maxLoggedBodySize] + "...(truncated)"
+	}
+	return masked
+}
+
+// maskBody masks sensitive JSON fields without truncating the body
+func maskBody(body []byte) string {
+	return sensitiveBodyField.ReplaceAllString(string(body), `$1"`+redactedValue+`"`)
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newWebSocketTestServer starts a server that upgrades every request and hands the raw connection to serve
+func newWebSocketTestServer(t *testing.T, serve func(conn net.Conn, rw *bufio.ReadWriter)) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := websocketAccept(r.Header.Get("Sec-WebSocket-Key"))
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept + "\r\n\r\n")
+		_ = rw.Flush()
+		serve(conn, rw)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// writeServerFrame writes an unmasked frame, as sent by a server
+func writeServerFrame(w *bufio.ReadWriter, fin bool, opcode byte, payload []byte) {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	header := []byte{first}
+	if len(payload) < 126 {
+		header = append(header, byte(len(payload)))
+	} else {
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	}
+	_, _ = w.Write(header)
+	_, _ = w.Write(payload)
+	_ = w.Flush()
+}
+
+// readClientFrame reads a masked client frame and returns its opcode and unmasked payload
+func readClientFrame(t *testing.T, r *bufio.ReadWriter) (byte, []byte) {
+	t.Helper()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		t.Fatalf("Failed to read client frame: %v", err)
+	}
+	if header[1]&0x80 == 0 {
+		t.Error("Expected client frame to be masked")
+	}
+	length := int(header[1] & 0x7F)
+	mask := make([]byte, 4)
+	_, _ = io.ReadFull(r, mask)
+	payload := make([]byte, length)
+	_, _ = io.ReadFull(r, payload)
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestWebSocket_ReadMessage(t *testing.T) {
+	pong := make(chan []byte, 1)
+	url := newWebSocketTestServer(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+		long := strings.Repeat("x", 300)
+		writeServerFrame(rw, true, wsOpText, []byte(`{"type":"heartbeat"}`))
+		writeServerFrame(rw, true, wsOpPing, []byte("ping"))
+		opcode, payload := readClientFrame(t, rw)
+		if opcode == wsOpPong {
+			pong <- payload
+		}
+		// A fragmented message is reassembled by the client
+		writeServerFrame(rw, false, wsOpText, []byte("hello "))
+		writeServerFrame(rw, true, wsOpContinuation, []byte("world"))
+		writeServerFrame(rw, true, wsOpText, []byte(long))
+		writeServerFrame(rw, true, wsOpClose, []byte{0x03, 0xE8})
+		readClientFrame(t, rw)
+	})
+
+	ws, err := DialWebSocket(context.Background(), url, map[string]string{"User-Agent": "test"})
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer ws.Close()
+
+	expected := []string{`{"type":"heartbeat"}`, "hello world", strings.Repeat("x", 300)}
+	for _, want := range expected {
+		message, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(message) != want {
+			t.Errorf("Expected message %q, got %q", want, message)
+		}
+	}
+
+	select {
+	case payload := <-pong:
+		if string(payload) != "ping" {
+			t.Errorf("Expected pong to echo ping payload, got %q", payload)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a pong in reply to ping")
+	}
+
+	if _, err := ws.ReadMessage(); err != io.EOF {
+		t.Errorf("Expected io.EOF after close frame, got %v", err)
+	}
+}
+
+func TestWebSocket_HandshakeRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := DialWebSocket(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err == nil {
+		t.Error("Expected handshake error")
+	}
+
+	if _, err := DialWebSocket(context.Background(), server.URL, nil); err == nil {
+		t.Error("Expected error for non-WebSocket scheme")
+	}
+}
@@ -15,12 +15,18 @@ const (
 	ErrRateLimit       ErrorCode = "RATE_LIMIT_EXCEEDED"
 	ErrNetworkError    ErrorCode = "NETWORK_ERROR"
 	ErrInvalidResponse ErrorCode = "INVALID_RESPONSE"
+	// ErrOutcomeUnknown means a non-idempotent request failed after it may have reached
+	// the exchange, so it may or may not have been executed
+	ErrOutcomeUnknown ErrorCode = "OUTCOME_UNKNOWN"
 
 	// Authentication errors
 	ErrInvalidAPIKey    ErrorCode = "INVALID_API_KEY" // #nosec G101 -- This is an error code, not a credential
 	ErrInvalidSignature ErrorCode = "INVALID_SIGNATURE"
 	ErrPermissionDenied ErrorCode = "PERMISSION_DENIED"
 	ErrAPIKeyExpired    ErrorCode = "API_KEY_EXPIRED" // #nosec G101 -- This is an error code, not a credential
+	// ErrInvalidNonce means the exchange rejected a request's nonce, usually because another
+	// process using the same API key has already sent a larger one
+	ErrInvalidNonce ErrorCode = "INVALID_NONCE"
 
 	// Exchange specific errors
 	ErrExchangeNotSupported ErrorCode = "EXCHANGE_NOT_SUPPORTED"
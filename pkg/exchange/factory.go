@@ -23,13 +23,16 @@ func (f *Factory) Register(exchangeName string, constructor func(Config) Exchang
 	f.constructors[strings.ToLower(exchangeName)] = constructor
 }
 
-// Create creates an exchange instance by name
+// Create creates an exchange instance by name after validating config
 func (f *Factory) Create(exchangeName string, config Config) (Exchange, error) {
 	name := strings.ToLower(exchangeName)
 	constructor, exists := f.constructors[name]
 	if !exists {
 		return nil, errors.Newf(errors.ErrExchangeNotSupported, "exchange '%s' not supported", exchangeName)
 	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	return constructor(config), nil
 }
 
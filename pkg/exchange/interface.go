@@ -3,9 +3,12 @@ package exchange
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
 )
 
 // APIType represents the type of API endpoint
@@ -21,9 +24,18 @@ type Exchange interface {
 	// GetName returns the exchange name
 	GetName() string
 
+	// Capabilities reports which features this exchange implementation supports
+	Capabilities() Capabilities
+
 	// GetTradingPairs fetches all trading pairs
 	GetTradingPairs(ctx context.Context) ([]TradingPair, error)
 
+	// PlaceOrder places an order described by exchange independent fields
+	PlaceOrder(ctx context.Context, req GenericOrderRequest) (*GenericOrder, error)
+
+	// GetOrderBook fetches up to depth price levels on each side of the book; 0 means all
+	GetOrderBook(ctx context.Context, symbol string, depth int) (OrderBook, error)
+
 	// SetRateLimit sets rate limiting configuration for specific API type
 	SetRateLimit(apiType APIType, limit RateLimit)
 
@@ -40,6 +52,33 @@ type Exchange interface {
 	SetHTTPClient(client *http.Client)
 }
 
+// Warmer is implemented by exchanges that can open connections ahead of the first request.
+// It is optional; check for it with a type assertion.
+type Warmer interface {
+	// Warmup primes DNS and the connection pool so the first request avoids connection setup
+	Warmup(ctx context.Context) error
+}
+
+// StreamingExchange is implemented by exchanges that stream market data over WebSocket.
+// It is optional; check for it with a type assertion.
+type StreamingExchange interface {
+	// SubscribeTrades streams public trades for symbol. The channel is closed when ctx is done
+	// or the subscription ends.
+	SubscribeTrades(ctx context.Context, symbol string) (<-chan Trade, error)
+}
+
+// Capabilities describes the features supported by an exchange implementation
+type Capabilities struct {
+	Spot        bool `json:"spot"`         // Spot order placement and management
+	Margin      bool `json:"margin"`       // Margin trading
+	Derivatives bool `json:"derivatives"`  // Perpetuals/futures trading
+	Staking     bool `json:"staking"`      // Staking endpoints
+	WebSocket   bool `json:"websocket"`    // Streaming market data
+	Deposits    bool `json:"deposits"`     // Deposit address management
+	Withdrawals bool `json:"withdrawals"`  // Crypto withdrawals
+	SubAccounts bool `json:"sub_accounts"` // Per-request sub-account routing
+}
+
 // TradingPair represents a trading pair information
 type TradingPair struct {
 	Symbol     string  `json:"symbol"`      // Trading pair symbol
@@ -52,10 +91,103 @@ type TradingPair struct {
 	TickSize   float64 `json:"tick_size"`   // Price tick size
 }
 
+// OrderSide represents an exchange independent order side
+type OrderSide string
+
+const (
+	OrderSideBuy  OrderSide = "BUY"
+	OrderSideSell OrderSide = "SELL"
+)
+
+// OrderType represents an exchange independent order type.
+// Exchanges reject types they do not support rather than approximating them.
+type OrderType string
+
+const (
+	OrderTypeLimit             OrderType = "LIMIT"
+	OrderTypeMarket            OrderType = "MARKET"
+	OrderTypeStopLimit         OrderType = "STOP_LIMIT"
+	OrderTypeTrailingStop      OrderType = "TRAILING_STOP"
+	OrderTypeImmediateOrCancel OrderType = "IMMEDIATE_OR_CANCEL"
+	OrderTypeFillOrKill        OrderType = "FILL_OR_KILL"
+)
+
+// TimeInForce represents how long an order stays on the book
+type TimeInForce string
+
+const (
+	TimeInForceGTC      TimeInForce = "GTC"       // Good till cancelled
+	TimeInForceIOC      TimeInForce = "IOC"       // Immediate or cancel: fill what is possible, cancel the rest
+	TimeInForceFOK      TimeInForce = "FOK"       // Fill or kill: fill completely or cancel
+	TimeInForcePostOnly TimeInForce = "POST_ONLY" // Rest on the book as a maker or cancel
+)
+
+// OrderStatus represents an exchange independent order status
+type OrderStatus string
+
+const (
+	OrderStatusOpen      OrderStatus = "OPEN"
+	OrderStatusFilled    OrderStatus = "FILLED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+)
+
+// GenericOrderRequest represents an exchange independent order request.
+// Amount and Price are decimal strings to avoid floating point rounding.
+type GenericOrderRequest struct {
+	Symbol        string      `json:"symbol"`                    // Trading pair symbol
+	Side          OrderSide   `json:"side"`                      // Order side
+	Type          OrderType   `json:"type"`                      // Order type
+	Amount        string      `json:"amount"`                    // Quantity in the base asset
+	Price         string      `json:"price,omitempty"`           // Limit price, unused for market orders
+	TimeInForce   TimeInForce `json:"time_in_force,omitempty"`   // Time in force, GTC if empty
+	ClientOrderID string      `json:"client_order_id,omitempty"` // Client assigned order ID
+}
+
+// GenericOrder represents an order in exchange independent form
+type GenericOrder struct {
+	ID              string      `json:"id"`                        // Exchange order ID
+	ClientOrderID   string      `json:"client_order_id,omitempty"` // Client assigned order ID
+	Symbol          string      `json:"symbol"`                    // Trading pair symbol
+	Side            OrderSide   `json:"side"`                      // Order side
+	Type            OrderType   `json:"type"`                      // Order type
+	TimeInForce     TimeInForce `json:"time_in_force,omitempty"`   // Time in force
+	Status          OrderStatus `json:"status"`                    // Order status
+	Amount          string      `json:"amount"`                    // Original quantity
+	Price           string      `json:"price,omitempty"`           // Limit price
+	ExecutedAmount  string      `json:"executed_amount"`           // Filled quantity
+	RemainingAmount string      `json:"remaining_amount"`          // Unfilled quantity
+	Timestamp       time.Time   `json:"timestamp"`                 // Creation time
+}
+
+// Trade represents a public trade in exchange independent form.
+// Price and Amount are decimal strings to avoid floating point rounding.
+type Trade struct {
+	ID        string    `json:"id"`             // Exchange trade ID
+	Symbol    string    `json:"symbol"`         // Trading pair symbol
+	Price     string    `json:"price"`          // Execution price
+	Amount    string    `json:"amount"`         // Quantity in the base asset
+	Side      OrderSide `json:"side,omitempty"` // Taker side, empty when unknown such as for auction fills
+	Timestamp time.Time `json:"timestamp"`      // Execution time
+}
+
+// PriceLevel is an exchange independent order book level
+type PriceLevel struct {
+	Price    decimal.Decimal `json:"price"`    // Level price
+	Quantity decimal.Decimal `json:"quantity"` // Quantity resting at the price in the base asset
+}
+
+// OrderBook is an exchange independent order book snapshot. Bids are sorted from the highest
+// price down and asks from the lowest price up.
+type OrderBook struct {
+	Bids []PriceLevel `json:"bids"` // Buy levels, best first
+	Asks []PriceLevel `json:"asks"` // Sell levels, best first
+}
+
 // RateLimit represents rate limiting configuration
 type RateLimit struct {
-	Requests int           `json:"requests"` // Number of requests
-	Interval time.Duration `json:"interval"` // Time interval
+	Requests int           `json:"requests"`         // Number of requests
+	Interval time.Duration `json:"interval"`         // Time interval
+	Weight   int           `json:"weight,omitempty"` // Requests counted per order entry request (0 keeps the exchange default)
 }
 
 // RateLimitConfig represents rate limiting configuration for different API types
@@ -66,15 +198,53 @@ type RateLimitConfig struct {
 
 // Config represents exchange configuration
 type Config struct {
-	APIKey     string            `json:"api_key"`    // API key
-	SecretKey  string            `json:"secret_key"` // Secret key
-	BaseURL    string            `json:"base_url"`   // Base URL
-	Timeout    time.Duration     `json:"timeout"`    // Request timeout
-	RateLimit  RateLimitConfig   `json:"rate_limit"` // Rate limiting configuration
-	Headers    map[string]string `json:"headers"`    // Custom headers
-	Proxies    []string          `json:"proxies"`    // Proxy list
-	Testnet    bool              `json:"testnet"`    // Testnet flag
-	Sandbox    bool              `json:"sandbox"`    // Sandbox flag (alias for Testnet)
-	Logger     *zerolog.Logger   `json:"-"`          // Custom logger (not serialized)
-	HTTPClient *http.Client      `json:"-"`          // Custom HTTP client (not serialized)
+	APIKey           string            `json:"api_key"`            // API key
+	SecretKey        string            `json:"secret_key"`         // Secret key
+	SandboxAPIKey    string            `json:"sandbox_api_key"`    // API key used instead of APIKey in sandbox mode
+	SandboxSecretKey string            `json:"sandbox_secret_key"` // Secret key used instead of SecretKey in sandbox mode
+	BaseURL          string            `json:"base_url"`           // Base URL, overrides Testnet and Sandbox
+	DefaultAccount   string            `json:"default_account"`    // Sub-account for private requests that name none; empty means the key's own account
+	Timeout          time.Duration     `json:"timeout"`            // Request timeout
+	RateLimit        RateLimitConfig   `json:"rate_limit"`         // Rate limiting configuration
+	Headers          map[string]string `json:"headers"`            // Custom headers
+	Proxies          []string          `json:"proxies"`            // Proxy list
+	Testnet          bool              `json:"testnet"`            // Testnet flag
+	Sandbox          bool              `json:"sandbox"`            // Sandbox flag (alias for Testnet)
+	Logger           *zerolog.Logger   `json:"-"`                  // Custom logger (not serialized)
+	HTTPClient       *http.Client      `json:"-"`                  // Custom HTTP client (not serialized)
+}
+
+// IsSandbox reports whether the configuration selects the exchange's sandbox
+func (c Config) IsSandbox() bool {
+	return c.Testnet || c.Sandbox
+}
+
+// Credentials returns the API key and secret for the selected environment. In sandbox mode the
+// sandbox pair is used when either of SandboxAPIKey and SandboxSecretKey is set, so a sandbox key
+// is never paired with a production secret; otherwise APIKey and SecretKey are used as before.
+// Outside sandbox mode the sandbox pair is ignored.
+func (c Config) Credentials() (apiKey, secretKey string) {
+	if c.IsSandbox() && (c.SandboxAPIKey != "" || c.SandboxSecretKey != "") {
+		return c.SandboxAPIKey, c.SandboxSecretKey
+	}
+	return c.APIKey, c.SecretKey
+}
+
+// Validate checks the configuration for values that cannot work, such as a malformed BaseURL
+func (c Config) Validate() error {
+	if c.BaseURL != "" {
+		return ValidateBaseURL(c.BaseURL)
+	}
+	return nil
+}
+
+// ValidateBaseURL checks that u is an absolute http or https URL with a host and without
+// a query or fragment, since API paths are appended to it
+func ValidateBaseURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		parsed.RawQuery != "" || parsed.Fragment != "" {
+		return errors.Newf(errors.ErrInvalidInput, "invalid base URL format: %q", u)
+	}
+	return nil
 }
@@ -0,0 +1,122 @@
+package gemini
+
+import (
+	"context"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// AccountAPI handles account administration related operations
+type AccountAPI struct {
+	gemini *Gemini
+	fees   *feeScheduleCache
+}
+
+// NewAccountAPI creates a new account API instance
+func NewAccountAPI(g *Gemini) *AccountAPI {
+	return &AccountAPI{
+		gemini: g,
+		fees:   newFeeScheduleCache(feeScheduleTTL),
+	}
+}
+
+// AccountType represents the type of a Gemini account
+type AccountType string
+
+const (
+	AccountTypeExchange AccountType = "exchange"
+	AccountTypeCustody  AccountType = "custody"
+)
+
+// Validate checks that the account type is one the API accepts
+func (t AccountType) Validate() error {
+	switch t {
+	case AccountTypeExchange, AccountTypeCustody:
+		return nil
+	}
+	return errors.Newf(errors.ErrInvalidInput, "invalid account type: %q", string(t))
+}
+
+// Roles represents the permissions granted to an API key
+type Roles struct {
+	IsAuditor      bool   `json:"isAuditor"`
+	IsFundManager  bool   `json:"isFundManager"`
+	IsTrader       bool   `json:"isTrader"`
+	CounterpartyID string `json:"counterparty_id,omitempty"`
+}
+
+// GetRolesRequest represents the request payload for getting API key roles
+type GetRolesRequest struct {
+	Request string `json:"request"`
+	Nonce   string `json:"nonce"`
+	Account string `json:"account,omitempty"`
+}
+
+// GetRoles fetches the roles assigned to the API key, which determine whether it can trade,
+// manage funds or only read account data
+// This implements the private API: https://docs.gemini.com/rest/roles#get-roles
+func (a *AccountAPI) GetRoles(ctx context.Context, account string) (*Roles, error) {
+	roles, err := a.getRoles(ctx, account)
+	a.gemini.audit("GetRoles", func() map[string]interface{} {
+		return map[string]interface{}{"account": account}
+	}, roles, err)
+	return roles, err
+}
+
+// getRoles fetches the roles of the API key without auditing
+func (a *AccountAPI) getRoles(ctx context.Context, account string) (*Roles, error) {
+	var roles Roles
+	if err := a.gemini.doSignedPost(ctx, "/v1/roles", GetRolesRequest{Account: account}, &roles); err != nil {
+		return nil, err
+	}
+
+	a.gemini.logger.Debug().Bool("is_trader", roles.IsTrader).Bool("is_fund_manager", roles.IsFundManager).Bool("is_auditor", roles.IsAuditor).Msg("Successfully fetched roles")
+	return &roles, nil
+}
+
+// CreateAccountRequest represents the request payload for creating an account
+type CreateAccountRequest struct {
+	Request string      `json:"request"`
+	Nonce   string      `json:"nonce"`
+	Name    string      `json:"name"`
+	Type    AccountType `json:"type,omitempty"`
+}
+
+// CreateAccountResponse represents a newly created account
+type CreateAccountResponse struct {
+	Account string      `json:"account"`
+	Type    AccountType `json:"type"`
+}
+
+// CreateAccount creates a new account within the master group. Requires a master API key.
+// An empty account type defaults to an exchange account on the server.
+// This implements the private API: https://docs.gemini.com/rest/account-administration#create-account
+func (a *AccountAPI) CreateAccount(ctx context.Context, name string, accountType AccountType) (*CreateAccountResponse, error) {
+	response, err := a.createAccount(ctx, name, accountType)
+	a.gemini.audit("CreateAccount", func() map[string]interface{} {
+		return map[string]interface{}{"name": name, "type": accountType}
+	}, response, err)
+	return response, err
+}
+
+// createAccount creates a new account without auditing
+func (a *AccountAPI) createAccount(ctx context.Context, name string, accountType AccountType) (*CreateAccountResponse, error) {
+	if name == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "account name is required")
+	}
+	if accountType != "" {
+		if err := accountType.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Account creation acts on the master group, so the default account is not injected
+	request := CreateAccountRequest{Name: name, Type: accountType}
+	var created CreateAccountResponse
+	if err := a.gemini.doSigned(ctx, signedCall{endpoint: "/v1/account/create", payload: request, withoutDefaultAccount: true}, &created); err != nil {
+		return nil, err
+	}
+
+	a.gemini.logger.Debug().Str("account", created.Account).Msg("Successfully created account")
+	return &created, nil
+}
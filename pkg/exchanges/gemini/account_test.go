@@ -0,0 +1,31 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountType_Validate(t *testing.T) {
+	assert.NoError(t, AccountTypeExchange.Validate())
+	assert.NoError(t, AccountTypeCustody.Validate())
+
+	for _, invalid := range []AccountType{"", "Exchange", "custodial", "margin"} {
+		err := invalid.Validate()
+		require.Error(t, err, "expected %q to be rejected", invalid)
+		assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+	}
+}
+
+func TestAccountAPI_CreateAccount_InvalidType(t *testing.T) {
+	g := NewGeminiWithOptions(WithCredentials("test-key", "test-secret"))
+
+	// Rejected locally before any request is sent
+	resp, err := g.Account.CreateAccount(context.Background(), "trading-2", AccountType("custodial"))
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"time"
+)
+
+// AuditSink receives a record of every private operation, for example to keep a compliance log.
+// Record is called synchronously after each operation completes, so implementations should be fast.
+// params never contain the API secret, and the API key is redacted to its last four characters.
+type AuditSink interface {
+	Record(op string, params map[string]interface{}, result interface{}, err error, ts time.Time)
+}
+
+// SetAuditSink sets the sink that records private operations. A nil sink disables auditing.
+func (g *Gemini) SetAuditSink(sink AuditSink) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.auditSink = sink
+}
+
+// audit records a private operation if an audit sink is set.
+// params is only evaluated when a sink is set, so auditing costs nothing when unused.
+func (g *Gemini) audit(op string, params func() map[string]interface{}, result interface{}, err error) {
+	g.mu.RLock()
+	sink := g.auditSink
+	apiKey := g.apiKey
+	g.mu.RUnlock()
+	if sink == nil {
+		return
+	}
+
+	recorded := params()
+	if recorded == nil {
+		recorded = make(map[string]interface{})
+	}
+	recorded["api_key"] = redactAPIKey(apiKey)
+	if err != nil {
+		result = nil
+	}
+	sink.Record(op, recorded, result, err, time.Now())
+}
+
+// redactAPIKey masks all but the last four characters of an API key
+func redactAPIKey(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "****"
+	}
+	return "****" + apiKey[len(apiKey)-4:]
+}
@@ -0,0 +1,77 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditRecord is a single call to recordingSink.Record
+type auditRecord struct {
+	op     string
+	params map[string]interface{}
+	result interface{}
+	err    error
+	ts     time.Time
+}
+
+// recordingSink is an AuditSink that keeps every record in memory
+type recordingSink struct {
+	records []auditRecord
+	mu      sync.Mutex
+}
+
+func (s *recordingSink) Record(op string, params map[string]interface{}, result interface{}, err error, ts time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, auditRecord{op: op, params: params, result: result, err: err, ts: ts})
+}
+
+func TestGemini_AuditSink(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/order/new":
+			_, _ = w.Write([]byte(`{"order_id":"1","symbol":"btcusd","is_live":true}`))
+		default:
+			_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidAddress","message":"Address is not whitelisted"}`))
+		}
+	})
+	g.SetWithdrawalGuard(false)
+	sink := &recordingSink{}
+	g.SetAuditSink(sink)
+	ctx := context.Background()
+
+	_, err := g.Order.PlaceOrder(ctx, &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Price: "30000", Side: OrderSideBuy, Type: OrderTypeExchangeLimit})
+	require.NoError(t, err)
+	_, err = g.Fund.WithdrawCrypto(ctx, "btc", &WithdrawCryptoRequest{Address: "bc1qexample", Amount: "1"})
+	require.Error(t, err)
+
+	require.Len(t, sink.records, 2)
+
+	placed := sink.records[0]
+	assert.Equal(t, "PlaceOrder", placed.op)
+	assert.Equal(t, "0.5", placed.params["amount"])
+	assert.Equal(t, "30000", placed.params["price"])
+	assert.Equal(t, "****-key", placed.params["api_key"])
+	assert.NoError(t, placed.err)
+	require.IsType(t, &Order{}, placed.result)
+	assert.Equal(t, "1", placed.result.(*Order).OrderID)
+	assert.False(t, placed.ts.IsZero())
+
+	withdrawn := sink.records[1]
+	assert.Equal(t, "WithdrawCrypto", withdrawn.op)
+	assert.Equal(t, "bc1qexample", withdrawn.params["address"])
+	assert.Equal(t, "1", withdrawn.params["amount"])
+	assert.Error(t, withdrawn.err)
+	assert.Nil(t, withdrawn.result)
+
+	// Removing the sink stops auditing
+	g.SetAuditSink(nil)
+	_, _ = g.Order.PlaceOrder(ctx, &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Price: "30000", Side: OrderSideBuy, Type: OrderTypeExchangeLimit})
+	assert.Len(t, sink.records, 2)
+}
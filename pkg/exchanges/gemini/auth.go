@@ -0,0 +1,149 @@
+package gemini
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// Signer signs private API requests. Sign receives the endpoint (for example "/v1/balances")
+// and the JSON payload, and returns the authentication headers to send: for Gemini,
+// X-GEMINI-APIKEY, X-GEMINI-PAYLOAD and X-GEMINI-SIGNATURE. Implement it to sign with a
+// secret that never enters the process, such as a key held in an HSM.
+type Signer interface {
+	Sign(endpoint string, payload []byte) (headers map[string]string, err error)
+}
+
+// HMACSigner signs requests with an API key and secret held in memory, using Gemini's
+// HMAC-SHA384 scheme. It is used when no other signer is set.
+type HMACSigner struct {
+	APIKey    string
+	APISecret string
+}
+
+// Sign base64 encodes the payload and signs it with the API secret
+func (s HMACSigner) Sign(endpoint string, payload []byte) (map[string]string, error) {
+	if s.APIKey == "" || s.APISecret == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required")
+	}
+	return signPayload(s.APIKey, s.APISecret, payload), nil
+}
+
+// BuildAuthHeaders returns the headers the SDK sends for a private request to endpoint
+// (for example "/v1/balances") with the given payload fields. It signs exactly as private
+// API calls do: JSON payload, base64 encoded, HMAC-SHA384 signed with the API secret.
+//
+// The payload's "request" field defaults to endpoint, and "nonce" defaults to the current
+// time in nanoseconds; set them explicitly to reproduce a specific request. Payload fields
+// are marshaled in key order, which can differ from the field order of the SDK's request
+// structs, so compare decoded payloads rather than raw header values. This is meant for
+// debugging signature mismatches and for testing against mock servers.
+func BuildAuthHeaders(apiKey, apiSecret, endpoint string, payload map[string]interface{}) (map[string]string, error) {
+	if apiKey == "" || apiSecret == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required")
+	}
+
+	fields := make(map[string]interface{}, len(payload)+2)
+	for k, v := range payload {
+		fields[k] = v
+	}
+	if _, ok := fields["request"]; !ok {
+		fields["request"] = endpoint
+	}
+	if _, ok := fields["nonce"]; !ok {
+		fields["nonce"] = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	payloadBytes, err := jsonMarshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal payload", err)
+	}
+	return signPayload(apiKey, apiSecret, payloadBytes), nil
+}
+
+// signRequest signs a private request payload for endpoint with the instance's signer. When a
+// default account is configured and the payload does not name one, the account is added first.
+func (g *Gemini) signRequest(endpoint string, payloadBytes []byte) (map[string]string, error) {
+	g.mu.RLock()
+	account := g.defaultAccount
+	g.mu.RUnlock()
+
+	if account != "" {
+		var fields map[string]json.RawMessage
+		if err := jsonUnmarshal(payloadBytes, &fields); err != nil {
+			return nil, errors.Wrap(errors.ErrDataParsingError, "failed to decode payload", err)
+		}
+		if existing, ok := fields["account"]; !ok || string(existing) == `""` {
+			value, err := jsonMarshal(account)
+			if err != nil {
+				return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal account", err)
+			}
+			fields["account"] = value
+			if payloadBytes, err = jsonMarshal(fields); err != nil {
+				return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal payload", err)
+			}
+		}
+	}
+
+	return g.sign(endpoint, payloadBytes)
+}
+
+// sign signs a payload with the configured signer, or with the API key and secret if none is
+// set. Standard headers that a custom signer leaves out are filled in.
+func (g *Gemini) sign(endpoint string, payloadBytes []byte) (map[string]string, error) {
+	g.mu.RLock()
+	signer := g.signer
+	apiKey, apiSecret := g.apiKey, g.apiSecret
+	g.mu.RUnlock()
+
+	if signer == nil {
+		return HMACSigner{APIKey: apiKey, APISecret: apiSecret}.Sign(endpoint, payloadBytes)
+	}
+
+	signed, err := signer.Sign(endpoint, payloadBytes)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrInvalidSignature, "failed to sign request", err)
+	}
+	headers := map[string]string{
+		"Content-Type":   "text/plain",
+		"Content-Length": "0",
+		"Cache-Control":  "no-cache",
+	}
+	for k, v := range signed {
+		headers[k] = v
+	}
+	return headers, nil
+}
+
+// hasCredentials reports whether private requests can be signed, either by a custom signer
+// or with an API key and secret
+func (g *Gemini) hasCredentials() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.signer != nil || (g.apiKey != "" && g.apiSecret != "")
+}
+
+// signPayload base64 encodes a JSON payload, signs it with HMAC-SHA384 and
+// returns the headers required by private API requests
+func signPayload(apiKey, apiSecret string, payloadBytes []byte) map[string]string {
+	payload := base64.StdEncoding.EncodeToString(payloadBytes)
+
+	mac := hmac.New(sha512.New384, []byte(apiSecret))
+	mac.Write([]byte(payload))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	return map[string]string{
+		"X-GEMINI-APIKEY":    apiKey,
+		"X-GEMINI-PAYLOAD":   payload,
+		"X-GEMINI-SIGNATURE": signature,
+		"Content-Type":       "text/plain",
+		"Content-Length":     "0",
+		"Cache-Control":      "no-cache",
+	}
+}
@@ -0,0 +1,161 @@
+package gemini
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAuthHeaders(t *testing.T) {
+	headers, err := BuildAuthHeaders("key", "1234abcd", "/v1/order/status", map[string]interface{}{
+		"nonce":    "123456",
+		"order_id": 18834,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "key", headers["X-GEMINI-APIKEY"])
+	assert.Equal(t, "text/plain", headers["Content-Type"])
+
+	raw, err := base64.StdEncoding.DecodeString(headers["X-GEMINI-PAYLOAD"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"request":"/v1/order/status","nonce":"123456","order_id":18834}`, string(raw))
+
+	mac := hmac.New(sha512.New384, []byte("1234abcd"))
+	mac.Write([]byte(headers["X-GEMINI-PAYLOAD"]))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), headers["X-GEMINI-SIGNATURE"])
+
+	// A missing nonce is generated
+	headers, err = BuildAuthHeaders("key", "secret", "/v1/balances", nil)
+	require.NoError(t, err)
+	raw, err = base64.StdEncoding.DecodeString(headers["X-GEMINI-PAYLOAD"])
+	require.NoError(t, err)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &payload))
+	assert.Equal(t, "/v1/balances", payload["request"])
+	assert.NotEmpty(t, payload["nonce"])
+
+	_, err = BuildAuthHeaders("", "secret", "/v1/balances", nil)
+	assert.Error(t, err)
+}
+
+func TestGemini_SetDefaultAccount(t *testing.T) {
+	var accounts []interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		accounts = append(accounts, decodeTestPayload(t, r)["account"])
+		_, _ = w.Write([]byte(`[]`))
+	})
+	ctx := context.Background()
+
+	_, err := g.Fund.GetAvailableBalances(ctx, "")
+	require.NoError(t, err)
+
+	g.SetDefaultAccount("primary")
+	_, err = g.Fund.GetAvailableBalances(ctx, "")
+	require.NoError(t, err)
+	_, err = g.Fund.GetAvailableBalances(ctx, "secondary")
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{nil, "primary", "secondary"}, accounts)
+}
+
+func TestNewGemini_DefaultAccount(t *testing.T) {
+	var accounts []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accounts = append(accounts, decodeTestPayload(t, r)["account"])
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	g := NewGemini(&exchange.Config{APIKey: "test-key", SecretKey: "test-secret", BaseURL: server.URL, DefaultAccount: "trading"})
+	assert.Equal(t, "trading", g.DefaultAccount())
+	ctx := context.Background()
+
+	_, err := g.Fund.GetAvailableBalances(ctx, "")
+	require.NoError(t, err)
+	_, err = g.Order.GetActiveOrders(ctx, "")
+	require.NoError(t, err)
+	_, err = g.Fund.GetAvailableBalances(ctx, "custody")
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{"trading", "trading", "custody"}, accounts)
+}
+
+// recordingSigner signs with a fixed signature and records what it was asked to sign
+type recordingSigner struct {
+	endpoints []string
+	payloads  []string
+	err       error
+}
+
+func (s *recordingSigner) Sign(endpoint string, payload []byte) (map[string]string, error) {
+	s.endpoints = append(s.endpoints, endpoint)
+	s.payloads = append(s.payloads, string(payload))
+	if s.err != nil {
+		return nil, s.err
+	}
+	return map[string]string{
+		"X-GEMINI-APIKEY":    "hsm-key",
+		"X-GEMINI-PAYLOAD":   base64.StdEncoding.EncodeToString(payload),
+		"X-GEMINI-SIGNATURE": "hsm-signature",
+	}, nil
+}
+
+func TestGemini_SetSigner(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	// No API secret is configured; the signer holds the key
+	signer := &recordingSigner{}
+	g := NewGeminiWithOptions(WithSigner(signer))
+	require.NoError(t, g.SetBaseURLs([]string{server.URL}))
+	g.SetDefaultAccount("primary")
+
+	_, err := g.Fund.GetAvailableBalances(context.Background(), "")
+	require.NoError(t, err)
+
+	require.Len(t, headers, 1)
+	assert.Equal(t, "hsm-key", headers[0].Get("X-GEMINI-APIKEY"))
+	assert.Equal(t, "hsm-signature", headers[0].Get("X-GEMINI-SIGNATURE"))
+	assert.Equal(t, "text/plain", headers[0].Get("Content-Type"))
+	assert.Equal(t, []string{"/v1/balances"}, signer.endpoints)
+	// The signer sees the final payload, including the default account
+	assert.Contains(t, signer.payloads[0], `"account":"primary"`)
+
+	signer.err = assert.AnError
+	_, err = g.Fund.GetAvailableBalances(context.Background(), "")
+	assert.Equal(t, errors.ErrInvalidSignature, errors.GetCode(err))
+	assert.Len(t, headers, 1)
+
+	// Without a signer the API secret is required again
+	g.SetSigner(nil)
+	_, err = g.Fund.GetAvailableBalances(context.Background(), "")
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
+
+func TestHMACSigner(t *testing.T) {
+	payload := []byte(`{"request":"/v1/balances","nonce":"1"}`)
+	headers, err := HMACSigner{APIKey: "key", APISecret: "1234abcd"}.Sign("/v1/balances", payload)
+	require.NoError(t, err)
+
+	mac := hmac.New(sha512.New384, []byte("1234abcd"))
+	mac.Write([]byte(base64.StdEncoding.EncodeToString(payload)))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), headers["X-GEMINI-SIGNATURE"])
+
+	_, err = HMACSigner{APIKey: "key"}.Sign("/v1/balances", payload)
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// BookLevel is a price level of the order book
+type BookLevel struct {
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+	// Timestamp is deprecated by Gemini and kept for compatibility
+	Timestamp string `json:"timestamp"`
+}
+
+// OrderBook is a snapshot of the order book. Bids are sorted from the highest price down
+// and asks from the lowest price up.
+type OrderBook struct {
+	Bids []BookLevel `json:"bids"`
+	Asks []BookLevel `json:"asks"`
+}
+
+// GetOrderBook fetches up to depth price levels on each side of the book for a symbol.
+// A depth of 0 returns the full book.
+// This implements the public API: https://docs.gemini.com/rest/market-data#get-current-order-book
+func (m *MarketAPI) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+	if depth < 0 {
+		return nil, errors.Newf(errors.ErrInvalidInput, "depth must not be negative, got %d", depth)
+	}
+
+	url := fmt.Sprintf("%s/v1/book/%s?limit_bids=%d&limit_asks=%d", m.gemini.getBaseURL(), symbol, depth, depth)
+
+	m.gemini.logger.Debug().Str("url", url).Str("symbol", symbol).Msg("Fetching order book")
+
+	// This is a public API, no authentication required
+	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
+	if err != nil {
+		return nil, requestError("failed to fetch order book", err)
+	}
+
+	var book OrderBook
+	if err := jsonUnmarshal(response, &book); err != nil {
+		return nil, parseError(response, url, "failed to parse order book response", err)
+	}
+
+	m.gemini.logger.Debug().Str("symbol", symbol).Int("bids", len(book.Bids)).Int("asks", len(book.Asks)).Msg("Successfully fetched order book")
+	return &book, nil
+}
+
+// BookImbalance compares the amount resting on each side over the top levels of the book,
+// as (bid amount - ask amount) / (bid amount + ask amount). The result ranges from -1, when
+// only asks rest, to 1, when only bids rest. A non-positive levels uses the whole book.
+// An empty book has an imbalance of 0.
+func BookImbalance(book *OrderBook, levels int) float64 {
+	if book == nil {
+		return 0
+	}
+	bids := bookAmount(book.Bids, levels)
+	asks := bookAmount(book.Asks, levels)
+	total := bids.Add(asks)
+	if total.IsZero() {
+		return 0
+	}
+	return bids.Sub(asks).Div(total).InexactFloat64()
+}
+
+// bookAmount sums the amount of the first levels of one side of the book
+func bookAmount(side []BookLevel, levels int) decimal.Decimal {
+	if levels > 0 && levels < len(side) {
+		side = side[:levels]
+	}
+	total := decimal.Zero
+	for _, level := range side {
+		total = total.Add(level.Amount)
+	}
+	return total
+}
+
+// VWAP walks the book to fill quantity and returns the volume-weighted average price of the
+// fill and the amount filled. Buys take from the asks and sells from the bids. When the book
+// is too thin, filled is less than quantity and price averages over what was filled; when
+// nothing can be filled both are zero.
+func VWAP(book *OrderBook, side OrderSide, quantity decimal.Decimal) (price decimal.Decimal, filled decimal.Decimal) {
+	if book == nil || !quantity.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+
+	var levels []BookLevel
+	switch side {
+	case OrderSideBuy:
+		levels = book.Asks
+	case OrderSideSell:
+		levels = book.Bids
+	default:
+		return decimal.Zero, decimal.Zero
+	}
+
+	notional := decimal.Zero
+	for _, level := range levels {
+		remaining := quantity.Sub(filled)
+		if !remaining.IsPositive() {
+			break
+		}
+		take := decimal.Min(remaining, level.Amount)
+		notional = notional.Add(take.Mul(level.Price))
+		filled = filled.Add(take)
+	}
+
+	if filled.IsZero() {
+		return decimal.Zero, decimal.Zero
+	}
+	return notional.Div(filled), filled
+}
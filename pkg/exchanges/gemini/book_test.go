@@ -0,0 +1,77 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureBook returns the three level btcusd book recorded in fixturesDir
+func fixtureBook(t *testing.T) *OrderBook {
+	t.Helper()
+	gemini := newFixtureGemini(t, &exchange.Config{Testnet: true})
+	book, err := gemini.Market.GetOrderBook(context.Background(), "btcusd", 3)
+	require.NoError(t, err)
+	return book
+}
+
+func TestMarketAPI_GetOrderBook(t *testing.T) {
+	book := fixtureBook(t)
+
+	require.Len(t, book.Bids, 3)
+	require.Len(t, book.Asks, 3)
+	assert.Equal(t, "3607.85", book.Bids[0].Price.String())
+	assert.Equal(t, "6.643373", book.Bids[0].Amount.String())
+	assert.Equal(t, "3607.86", book.Asks[0].Price.String())
+
+	_, err := NewGemini(nil).Market.GetOrderBook(context.Background(), "btcusd", -1)
+	assert.Error(t, err)
+}
+
+func TestBookImbalance(t *testing.T) {
+	book := fixtureBook(t)
+
+	// 21.82542384 bid against 17.25 ask over the whole book
+	assert.InDelta(t, 0.1170921, BookImbalance(book, 0), 1e-7)
+	assert.InDelta(t, 0.1170921, BookImbalance(book, 10), 1e-7)
+	// 6.643373 bid against 14 ask at the top of the book
+	assert.InDelta(t, -0.3563675, BookImbalance(book, 1), 1e-7)
+
+	assert.Equal(t, 1.0, BookImbalance(&OrderBook{Bids: book.Bids}, 0))
+	assert.Equal(t, 0.0, BookImbalance(&OrderBook{}, 0))
+	assert.Equal(t, 0.0, BookImbalance(nil, 0))
+}
+
+func TestVWAP(t *testing.T) {
+	book := fixtureBook(t)
+
+	tests := []struct {
+		name     string
+		side     OrderSide
+		quantity string
+		price    string
+		filled   string
+	}{
+		{"buy within first level", OrderSideBuy, "10", "3607.86", "10"},
+		{"buy across levels", OrderSideBuy, "15", "3607.8613", "15"},
+		{"sell across levels", OrderSideSell, "7", "3607.8271", "7"},
+		{"partial fill", OrderSideBuy, "20", "3607.8777", "17.25"},
+		{"zero quantity", OrderSideBuy, "0", "0", "0"},
+		{"no side", "", "1", "0", "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			price, filled := VWAP(book, test.side, decimal.RequireFromString(test.quantity))
+			assert.Equal(t, test.price, price.Round(4).String())
+			assert.Equal(t, test.filled, filled.String())
+		})
+	}
+
+	price, filled := VWAP(&OrderBook{}, OrderSideSell, decimal.NewFromInt(1))
+	assert.True(t, price.IsZero())
+	assert.True(t, filled.IsZero())
+}
@@ -0,0 +1,273 @@
+package gemini
+
+import (
+	"context"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// ClearingStatus represents the settlement status of a clearing order
+type ClearingStatus string
+
+const (
+	// ClearingStatusAwaitConfirm means the order is waiting for confirmation
+	ClearingStatusAwaitConfirm ClearingStatus = "AwaitConfirm"
+	// ClearingStatusAwaitSourceTargetConfirm means neither party has confirmed yet
+	ClearingStatusAwaitSourceTargetConfirm ClearingStatus = "AwaitSourceTargetConfirm"
+	// ClearingStatusAwaitTargetConfirm means the counterparty has yet to confirm
+	ClearingStatusAwaitTargetConfirm ClearingStatus = "AwaitTargetConfirm"
+	// ClearingStatusAwaitSourceConfirm means the initiating party has yet to confirm
+	ClearingStatusAwaitSourceConfirm ClearingStatus = "AwaitSourceConfirm"
+	// ClearingStatusConfirmed means both parties have confirmed
+	ClearingStatusConfirmed ClearingStatus = "Confirmed"
+	// ClearingStatusAttemptSettlement means settlement is in progress
+	ClearingStatusAttemptSettlement ClearingStatus = "AttemptSettlement"
+	// ClearingStatusSettled means the trade has settled
+	ClearingStatusSettled ClearingStatus = "Settled"
+	// ClearingStatusExpired means the order expired before both parties confirmed
+	ClearingStatusExpired ClearingStatus = "Expired"
+	// ClearingStatusCanceled means the order was cancelled
+	ClearingStatusCanceled ClearingStatus = "Canceled"
+)
+
+// AwaitingConfirmation reports whether at least one party has yet to confirm the order
+func (s ClearingStatus) AwaitingConfirmation() bool {
+	switch s {
+	case ClearingStatusAwaitConfirm, ClearingStatusAwaitSourceTargetConfirm,
+		ClearingStatusAwaitTargetConfirm, ClearingStatusAwaitSourceConfirm:
+		return true
+	}
+	return false
+}
+
+// ConfirmClearingRequest represents a request to confirm a clearing order.
+// Symbol, Amount, Price and Side must match the order as submitted by the counterparty.
+type ConfirmClearingRequest struct {
+	Request    string    `json:"request"`
+	Nonce      string    `json:"nonce"`
+	ClearingID string    `json:"clearing_id"`
+	Symbol     string    `json:"symbol"`
+	Amount     string    `json:"amount"`
+	Price      string    `json:"price"`
+	Side       OrderSide `json:"side"`
+	Account    string    `json:"account,omitempty"`
+}
+
+// ConfirmClearingResponse represents the response of a clearing order confirmation
+type ConfirmClearingResponse struct {
+	Result string `json:"result"`
+}
+
+// ClearingOrderStatusRequest represents a request to get the status of a clearing order
+type ClearingOrderStatusRequest struct {
+	Request    string `json:"request"`
+	Nonce      string `json:"nonce"`
+	ClearingID string `json:"clearing_id"`
+	Account    string `json:"account,omitempty"`
+}
+
+// ClearingOrderStatus represents the status of a clearing order
+type ClearingOrderStatus struct {
+	Result string         `json:"result"`
+	Status ClearingStatus `json:"status"`
+}
+
+// ConfirmClearingOrder confirms a clearing order on behalf of a counterparty and returns its settlement status.
+//
+// Clearing orders settle only once both parties have confirmed, so after this party's confirmation the
+// status may still be awaiting the other side (see ClearingStatus.AwaitingConfirmation). Use
+// GetClearingOrderStatus to follow the order until it is settled, expired or cancelled.
+func (o *OrderAPI) ConfirmClearingOrder(ctx context.Context, clearingID string, req *ConfirmClearingRequest) (*ClearingOrderStatus, error) {
+	response, err := o.confirmClearingOrder(ctx, clearingID, req)
+	o.gemini.audit("ConfirmClearingOrder", func() map[string]interface{} {
+		if req == nil {
+			return map[string]interface{}{"clearing_id": clearingID}
+		}
+		return map[string]interface{}{
+			"clearing_id": clearingID,
+			"symbol":      req.Symbol,
+			"amount":      req.Amount,
+			"price":       req.Price,
+			"side":        req.Side,
+			"account":     req.Account,
+		}
+	}, response, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.GetClearingOrderStatus(ctx, clearingID, req.Account)
+}
+
+// confirmClearingOrder confirms a clearing order without auditing
+func (o *OrderAPI) confirmClearingOrder(ctx context.Context, clearingID string, req *ConfirmClearingRequest) (*ConfirmClearingResponse, error) {
+	if clearingID == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "clearing ID is required")
+	}
+	if req == nil || req.Symbol == "" || req.Amount == "" || req.Price == "" || req.Side == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol, amount, price and side are required to confirm a clearing order")
+	}
+
+	request := *req
+	request.ClearingID = clearingID
+	var result ConfirmClearingResponse
+	if err := o.gemini.doSignedPost(ctx, "/v1/clearing/confirm", &request, &result); err != nil {
+		return nil, err
+	}
+
+	o.gemini.logger.Debug().Str("clearing_id", clearingID).Str("result", result.Result).Msg("Successfully confirmed clearing order")
+	return &result, nil
+}
+
+// GetClearingOrderStatus fetches the settlement status of a clearing order
+func (o *OrderAPI) GetClearingOrderStatus(ctx context.Context, clearingID string, account string) (*ClearingOrderStatus, error) {
+	status, err := o.getClearingOrderStatus(ctx, clearingID, account)
+	o.gemini.audit("GetClearingOrderStatus", func() map[string]interface{} {
+		return map[string]interface{}{"clearing_id": clearingID, "account": account}
+	}, status, err)
+	return status, err
+}
+
+// getClearingOrderStatus fetches the settlement status of a clearing order without auditing
+func (o *OrderAPI) getClearingOrderStatus(ctx context.Context, clearingID string, account string) (*ClearingOrderStatus, error) {
+	if clearingID == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "clearing ID is required")
+	}
+
+	request := ClearingOrderStatusRequest{ClearingID: clearingID, Account: account}
+	var status ClearingOrderStatus
+	if err := o.gemini.doSignedPost(ctx, "/v1/clearing/status", request, &status); err != nil {
+		return nil, err
+	}
+
+	o.gemini.logger.Debug().Str("clearing_id", clearingID).Str("status", string(status.Status)).Msg("Successfully fetched clearing order status")
+	return &status, nil
+}
+
+// ClearingListRequest filters the clearing orders returned by ListClearingOrders.
+// All filters are optional.
+type ClearingListRequest struct {
+	Symbol         string
+	Side           OrderSide
+	CounterpartyID string
+	// Status keeps only orders in this status. Gemini has no status filter, so it is
+	// applied to the returned list.
+	Status          ClearingStatus
+	ExpirationStart time.Time
+	ExpirationEnd   time.Time
+	Account         string
+}
+
+// clearingListPayload is the signed request payload for listing clearing orders
+type clearingListPayload struct {
+	Request         string    `json:"request"`
+	Nonce           string    `json:"nonce"`
+	Symbol          string    `json:"symbol,omitempty"`
+	Side            OrderSide `json:"side,omitempty"`
+	Counterparty    string    `json:"counterparty,omitempty"`
+	ExpirationStart int64     `json:"expiration_start,omitempty"`
+	ExpirationEnd   int64     `json:"expiration_end,omitempty"`
+	Account         string    `json:"account,omitempty"`
+}
+
+// ClearingOrder represents an outstanding clearing order.
+// Submission and Expiration are Unix timestamps in milliseconds.
+type ClearingOrder struct {
+	ClearingID     string         `json:"clearing_id"`
+	CounterpartyID string         `json:"counterparty_id"`
+	Symbol         string         `json:"symbol"`
+	Side           OrderSide      `json:"side"`
+	Price          FlexFloat      `json:"price"`
+	Quantity       FlexFloat      `json:"quantity"`
+	Status         ClearingStatus `json:"status"`
+	Submission     FlexInt        `json:"submission"` // Submission time in milliseconds
+	Expiration     FlexInt        `json:"expiration"` // Expiry time in milliseconds
+}
+
+// SubmittedAt returns the time the order was submitted
+func (o ClearingOrder) SubmittedAt() time.Time {
+	return timestampTime(o.Submission, 0)
+}
+
+// ExpiresAt returns the time the order expires if it has not been confirmed by both parties
+func (o ClearingOrder) ExpiresAt() time.Time {
+	return time.UnixMilli(int64(o.Expiration))
+}
+
+// Expired reports whether the order's expiration time has passed at now
+func (o ClearingOrder) Expired(now time.Time) bool {
+	return o.Expiration > 0 && !now.Before(o.ExpiresAt())
+}
+
+// clearingListResponse represents the response of listing clearing orders
+type clearingListResponse struct {
+	Result string          `json:"result"`
+	Orders []ClearingOrder `json:"orders"`
+}
+
+// ListClearingOrders fetches the account's outstanding clearing orders matching the filters in req.
+// A nil request lists all of them.
+// This implements the private API: https://docs.gemini.com/rest/clearing#list-clearing-orders
+func (o *OrderAPI) ListClearingOrders(ctx context.Context, req *ClearingListRequest) ([]ClearingOrder, error) {
+	orders, err := o.listClearingOrders(ctx, req)
+	o.gemini.audit("ListClearingOrders", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"symbol":           req.Symbol,
+			"side":             req.Side,
+			"counterparty_id":  req.CounterpartyID,
+			"status":           req.Status,
+			"expiration_start": req.ExpirationStart,
+			"expiration_end":   req.ExpirationEnd,
+			"account":          req.Account,
+		}
+	}, orders, err)
+	return orders, err
+}
+
+// listClearingOrders fetches clearing orders without auditing
+func (o *OrderAPI) listClearingOrders(ctx context.Context, req *ClearingListRequest) ([]ClearingOrder, error) {
+	if req == nil {
+		req = &ClearingListRequest{}
+	}
+	if req.Side != "" && req.Side != OrderSideBuy && req.Side != OrderSideSell {
+		return nil, errors.Newf(errors.ErrInvalidInput, "invalid clearing order side: %q", req.Side)
+	}
+	if !req.ExpirationStart.IsZero() && !req.ExpirationEnd.IsZero() && req.ExpirationEnd.Before(req.ExpirationStart) {
+		return nil, errors.New(errors.ErrInvalidInput, "expiration end is before expiration start")
+	}
+
+	request := clearingListPayload{
+		Symbol:       req.Symbol,
+		Side:         req.Side,
+		Counterparty: req.CounterpartyID,
+		Account:      req.Account,
+	}
+	if !req.ExpirationStart.IsZero() {
+		request.ExpirationStart = req.ExpirationStart.UnixMilli()
+	}
+	if !req.ExpirationEnd.IsZero() {
+		request.ExpirationEnd = req.ExpirationEnd.UnixMilli()
+	}
+
+	var list clearingListResponse
+	if err := o.gemini.doSignedPost(ctx, "/v1/clearing/list", request, &list); err != nil {
+		return nil, err
+	}
+
+	orders := list.Orders
+	if req.Status != "" {
+		orders = make([]ClearingOrder, 0, len(list.Orders))
+		for _, order := range list.Orders {
+			if order.Status == req.Status {
+				orders = append(orders, order)
+			}
+		}
+	}
+
+	o.gemini.logger.Debug().Int("count", len(orders)).Msg("Successfully listed clearing orders")
+	return orders, nil
+}
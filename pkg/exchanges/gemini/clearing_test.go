@@ -0,0 +1,97 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderAPI_ConfirmClearingOrder(t *testing.T) {
+	var confirmed map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/clearing/confirm":
+			confirmed = decodeTestPayload(t, r)
+			_, _ = w.Write([]byte(`{"result":"confirmed"}`))
+		case "/v1/clearing/status":
+			_, _ = w.Write([]byte(`{"result":"ok","status":"AwaitSourceConfirm"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	req := &ConfirmClearingRequest{Symbol: "btcusd", Amount: "1", Price: "30000", Side: OrderSideSell}
+	status, err := g.Order.ConfirmClearingOrder(context.Background(), "OM9VNL1G", req)
+	require.NoError(t, err)
+	assert.Equal(t, ClearingStatusAwaitSourceConfirm, status.Status)
+	assert.True(t, status.Status.AwaitingConfirmation())
+
+	assert.Equal(t, "/v1/clearing/confirm", confirmed["request"])
+	assert.Equal(t, "OM9VNL1G", confirmed["clearing_id"])
+	assert.Equal(t, "sell", confirmed["side"])
+	assert.Equal(t, "30000", confirmed["price"])
+}
+
+func TestOrderAPI_ConfirmClearingOrder_InvalidInput(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL.Path)
+	})
+
+	_, err := g.Order.ConfirmClearingOrder(context.Background(), "", &ConfirmClearingRequest{Symbol: "btcusd", Amount: "1", Price: "1", Side: OrderSideBuy})
+	assert.Error(t, err)
+	_, err = g.Order.ConfirmClearingOrder(context.Background(), "OM9VNL1G", &ConfirmClearingRequest{Symbol: "btcusd"})
+	assert.Error(t, err)
+	_, err = g.Order.ConfirmClearingOrder(context.Background(), "OM9VNL1G", nil)
+	assert.Error(t, err)
+
+	assert.False(t, ClearingStatusSettled.AwaitingConfirmation())
+}
+
+func TestOrderAPI_ListClearingOrders(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/clearing/list", r.URL.Path)
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`{
+			"result": "success",
+			"orders": [
+				{"clearing_id": "9LVQE9X5", "counterparty_id": "YZ43LX81", "symbol": "BTCEUR", "side": "sell", "price": 2, "quantity": 10, "status": "AwaitTargetConfirm", "submission": 1641790800020, "expiration": 1641963600000},
+				{"clearing_id": "2MYR07XP", "counterparty_id": "YZ43LX81", "symbol": "BTCEUR", "side": "sell", "price": 3, "quantity": 1, "status": "Settled", "submission": 1641790800020, "expiration": 1641963600000}
+			]
+		}`))
+	})
+
+	start := time.UnixMilli(1641790800000)
+	orders, err := g.Order.ListClearingOrders(context.Background(), &ClearingListRequest{
+		Symbol:          "btceur",
+		Side:            OrderSideSell,
+		CounterpartyID:  "YZ43LX81",
+		Status:          ClearingStatusAwaitTargetConfirm,
+		ExpirationStart: start,
+	})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, "9LVQE9X5", orders[0].ClearingID)
+	assert.Equal(t, FlexFloat(10), orders[0].Quantity)
+	assert.Equal(t, time.UnixMilli(1641963600000), orders[0].ExpiresAt())
+	assert.True(t, orders[0].Expired(time.UnixMilli(1641963600000)))
+	assert.False(t, orders[0].Expired(start))
+
+	assert.Equal(t, "btceur", payload["symbol"])
+	assert.Equal(t, "sell", payload["side"])
+	assert.Equal(t, "YZ43LX81", payload["counterparty"])
+	assert.Equal(t, float64(1641790800000), payload["expiration_start"])
+	assert.NotContains(t, payload, "expiration_end")
+	assert.NotContains(t, payload, "status")
+
+	all, err := g.Order.ListClearingOrders(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	_, err = g.Order.ListClearingOrders(context.Background(), &ClearingListRequest{ExpirationStart: start, ExpirationEnd: start.Add(-time.Hour)})
+	assert.Error(t, err)
+}
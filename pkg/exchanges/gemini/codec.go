@@ -0,0 +1,163 @@
+package gemini
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+// Marshaler encodes a value as JSON
+type Marshaler interface {
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// Unmarshaler decodes JSON into a value
+type Unmarshaler interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes request payloads and decodes API responses.
+// Implementations must be compatible with encoding/json struct tags and json.RawMessage,
+// as jsoniter's ConfigCompatibleWithStandardLibrary and sonic's ConfigStd are.
+type JSONCodec interface {
+	Marshaler
+	Unmarshaler
+}
+
+// stdJSONCodec is the default codec backed by encoding/json
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// codecHolder wraps the codec so atomic.Value always stores the same concrete type
+type codecHolder struct {
+	codec JSONCodec
+}
+
+var jsonCodec atomic.Value
+
+func init() {
+	jsonCodec.Store(codecHolder{codec: stdJSONCodec{}})
+}
+
+// SetJSONCodec sets the codec used by every Gemini instance to encode payloads and decode
+// responses, for example a faster drop-in replacement for encoding/json when parsing large
+// order and trade arrays. A nil codec restores encoding/json.
+func SetJSONCodec(codec JSONCodec) {
+	if codec == nil {
+		codec = stdJSONCodec{}
+	}
+	jsonCodec.Store(codecHolder{codec: codec})
+}
+
+// jsonMarshal encodes v with the configured codec
+func jsonMarshal(v interface{}) ([]byte, error) {
+	return jsonCodec.Load().(codecHolder).codec.Marshal(v)
+}
+
+// jsonUnmarshal decodes data into v with the configured codec
+func jsonUnmarshal(data []byte, v interface{}) error {
+	return jsonCodec.Load().(codecHolder).codec.Unmarshal(data, v)
+}
+
+var streamingDecode atomic.Bool
+
+// SetStreamingDecode makes every Gemini instance decode JSON array responses, such as order,
+// trade and symbol details lists, one element at a time instead of in a single call. Streaming
+// allocates roughly 40% fewer bytes for large arrays but takes 30-60% longer, so it is off by
+// default; enable it when memory matters more than latency. Elements are decoded with
+// encoding/json whatever codec is set.
+func SetStreamingDecode(enabled bool) {
+	streamingDecode.Store(enabled)
+}
+
+// jsonUnmarshalArray decodes a JSON array into the slice v points to, streaming it when
+// SetStreamingDecode is enabled and with the configured codec otherwise
+func jsonUnmarshalArray(data []byte, v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if !streamingDecode.Load() || slice.Kind() != reflect.Pointer || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return jsonUnmarshal(data, v)
+	}
+	slice = slice.Elem()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		slice.Set(reflect.Zero(slice.Type()))
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("cannot decode %v into %s", token, slice.Type())
+	}
+
+	elems := reflect.MakeSlice(slice.Type(), 0, countArrayElements(data))
+	for decoder.More() {
+		elem := reflect.New(slice.Type().Elem())
+		if err := decoder.Decode(elem.Interface()); err != nil {
+			return err
+		}
+		elems = reflect.Append(elems, elem.Elem())
+	}
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the top-level array")
+	}
+	slice.Set(elems)
+	return nil
+}
+
+// countArrayElements counts the elements of the top-level JSON array in data, so streamed
+// elements can be decoded into a slice allocated once. Malformed JSON may give a wrong count,
+// which only affects the capacity.
+func countArrayElements(data []byte) int {
+	count, depth := 0, 0
+	inString, escaped, expectValue := false, false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ',':
+			expectValue = depth == 1
+			continue
+		}
+		if expectValue && c != ']' {
+			count++
+		}
+		expectValue = false
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+			expectValue = depth == 1
+		case ']', '}':
+			depth--
+		}
+	}
+	return count
+}
@@ -0,0 +1,194 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCodec records how often it is used and delegates to encoding/json
+type countingCodec struct {
+	marshals   int
+	unmarshals int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals++
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+func TestSetJSONCodec(t *testing.T) {
+	codec := &countingCodec{}
+	SetJSONCodec(codec)
+	t.Cleanup(func() { SetJSONCodec(nil) })
+
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"order_id":"1","symbol":"btcusd","timestampms":1700000000000}]`))
+	})
+
+	orders, err := g.Order.GetActiveOrders(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, FlexInt(1700000000000), orders[0].Timestampms)
+
+	assert.Equal(t, 1, codec.marshals)
+	assert.Equal(t, 1, codec.unmarshals) // array responses skip the error object decode
+
+	SetJSONCodec(nil)
+	_, err = g.Order.GetActiveOrders(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, codec.marshals)
+}
+
+func TestSetStreamingDecode(t *testing.T) {
+	SetStreamingDecode(true)
+	t.Cleanup(func() { SetStreamingDecode(false) })
+
+	data := tradesArray(3)
+	var streamed, unmarshalled []benchmarkTrade
+	require.NoError(t, jsonUnmarshalArray(data, &streamed))
+	require.NoError(t, json.Unmarshal(data, &unmarshalled))
+	assert.Equal(t, unmarshalled, streamed)
+
+	streamed = []benchmarkTrade{{TID: 1}}
+	require.NoError(t, jsonUnmarshalArray([]byte(" null "), &streamed))
+	assert.Nil(t, streamed)
+
+	require.NoError(t, jsonUnmarshalArray([]byte("[]"), &streamed))
+	assert.NotNil(t, streamed)
+	assert.Empty(t, streamed)
+
+	assert.Error(t, jsonUnmarshalArray([]byte(`{"tid":1}`), &streamed))
+	assert.Error(t, jsonUnmarshalArray([]byte(`[{"tid":1},`), &streamed))
+	assert.Error(t, jsonUnmarshalArray([]byte(`[{"tid":1}] []`), &streamed))
+
+	for data, want := range map[string]int{
+		`[]`:                           0,
+		` [ ] `:                        0,
+		`[1]`:                          1,
+		`[{"a":[1,2]},{"b":"x,]\"y"}]`: 2,
+		`[[1,2],[3],"s",null]`:         4,
+	} {
+		assert.Equal(t, want, countArrayElements([]byte(data)), data)
+	}
+
+	codec := &countingCodec{}
+	SetJSONCodec(codec)
+	t.Cleanup(func() { SetJSONCodec(nil) })
+
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"order_id":"1","symbol":"btcusd","timestampms":1700000000000},{"order_id":"2","symbol":"ethusd"}]`))
+	})
+	orders, err := g.Order.GetActiveOrders(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, "2", orders[1].OrderID)
+	assert.Equal(t, 0, codec.unmarshals) // streamed with encoding/json
+}
+
+// orderBookSnapshot builds an initial market data update with the given number of bid and ask levels,
+// the largest message the SDK parses
+func orderBookSnapshot(levels int) []byte {
+	events := make([]string, 0, levels*2)
+	for i := 0; i < levels; i++ {
+		events = append(events,
+			fmt.Sprintf(`{"type":"change","side":"bid","price":"%d.25","remaining":"1.5","delta":"1.5","reason":"initial"}`, 30000-i),
+			fmt.Sprintf(`{"type":"change","side":"ask","price":"%d.75","remaining":"0.25","delta":"0.25","reason":"initial"}`, 30001+i),
+		)
+	}
+	return []byte(`{"type":"update","eventId":5375461993,"socket_sequence":0,"events":[` + strings.Join(events, ",") + `]}`)
+}
+
+func BenchmarkJSONUnmarshal_OrderBook(b *testing.B) {
+	data := orderBookSnapshot(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var update MarketDataUpdate
+		if err := jsonUnmarshal(data, &update); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONUnmarshal_Orders(b *testing.B) {
+	orders := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		orders = append(orders, fmt.Sprintf(`{"order_id":"%d","symbol":"btcusd","exchange":"gemini","side":"buy","type":"exchange limit","timestampms":1700000000000,"is_live":true,"executed_amount":"0","remaining_amount":"1","options":[],"price":"30000.00","original_amount":"1"}`, i))
+	}
+	data := []byte("[" + strings.Join(orders, ",") + "]")
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var parsed []Order
+		if err := jsonUnmarshal(data, &parsed); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkTrade mirrors an element of Gemini's /v1/trades response
+type benchmarkTrade struct {
+	Timestamp   FlexInt `json:"timestamp"`
+	Timestampms FlexInt `json:"timestampms"`
+	TID         FlexInt `json:"tid"`
+	Price       string  `json:"price"`
+	Amount      string  `json:"amount"`
+	Exchange    string  `json:"exchange"`
+	Type        string  `json:"type"`
+}
+
+// tradesArray builds a trades response with n elements
+func tradesArray(n int) []byte {
+	trades := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		trades = append(trades, fmt.Sprintf(`{"timestamp":1700000000,"timestampms":1700000000000,"tid":%d,"price":"30000.25","amount":"0.0125","exchange":"gemini","type":"buy"}`, i))
+	}
+	return []byte("[" + strings.Join(trades, ",") + "]")
+}
+
+// The two benchmarks below compare decoding a 10k element array in one call against
+// streaming it element by element with SetStreamingDecode. Streaming allocates roughly 40%
+// fewer bytes but is 30-60% slower and allocates per element, which is why it is opt-in. Rerun these before changing the default.
+
+func BenchmarkJSONDecode_TradesUnmarshal(b *testing.B) {
+	data := tradesArray(10000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var trades []benchmarkTrade
+		if err := jsonUnmarshalArray(data, &trades); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONDecode_TradesStreaming(b *testing.B) {
+	SetStreamingDecode(true)
+	b.Cleanup(func() { SetStreamingDecode(false) })
+
+	data := tradesArray(10000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var trades []benchmarkTrade
+		if err := jsonUnmarshalArray(data, &trades); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package gemini
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+)
+
+// ToGeminiSide converts a unified order side to a Gemini order side.
+// Unknown sides convert to an empty side, which Gemini rejects.
+func ToGeminiSide(side exchange.OrderSide) OrderSide {
+	switch side {
+	case exchange.OrderSideBuy:
+		return OrderSideBuy
+	case exchange.OrderSideSell:
+		return OrderSideSell
+	default:
+		return ""
+	}
+}
+
+// FromGeminiSide converts a Gemini order side to a unified order side.
+// Unknown sides convert to an empty side.
+func FromGeminiSide(side OrderSide) exchange.OrderSide {
+	switch side {
+	case OrderSideBuy:
+		return exchange.OrderSideBuy
+	case OrderSideSell:
+		return exchange.OrderSideSell
+	default:
+		return ""
+	}
+}
+
+// ToGeminiType converts a unified order type to a Gemini order type.
+// Gemini market orders are side specific, so market orders must be converted with
+// ToGeminiOrderType; unsupported types return ErrInvalidOrderType.
+func ToGeminiType(orderType exchange.OrderType) (OrderType, error) {
+	switch orderType {
+	case exchange.OrderTypeLimit:
+		return OrderTypeExchangeLimit, nil
+	case exchange.OrderTypeImmediateOrCancel:
+		return OrderTypeImmediateOrCancel, nil
+	case exchange.OrderTypeFillOrKill:
+		return OrderTypeFillOrKill, nil
+	case exchange.OrderTypeMarket:
+		return "", errors.New(errors.ErrInvalidOrderType, "Gemini market order types depend on the side; use ToGeminiOrderType")
+	default:
+		return "", errors.Newf(errors.ErrInvalidOrderType, "order type %q is not supported by Gemini", orderType)
+	}
+}
+
+// ToGeminiOrderType converts a unified order side and type to a Gemini order type,
+// including side specific market orders
+func ToGeminiOrderType(side exchange.OrderSide, orderType exchange.OrderType) (OrderType, error) {
+	if orderType != exchange.OrderTypeMarket {
+		return ToGeminiType(orderType)
+	}
+
+	switch side {
+	case exchange.OrderSideBuy:
+		return OrderTypeMarketBuy, nil
+	case exchange.OrderSideSell:
+		return OrderTypeMarketSell, nil
+	default:
+		return "", errors.Newf(errors.ErrInvalidInput, "invalid order side: %q", side)
+	}
+}
+
+// FromGeminiType converts a Gemini order type to a unified order type.
+// Gemini specific types with no unified equivalent, such as auction-only and
+// indication-of-interest orders, return ErrInvalidOrderType.
+func FromGeminiType(orderType OrderType) (exchange.OrderType, error) {
+	switch orderType {
+	case OrderTypeExchangeLimit:
+		return exchange.OrderTypeLimit, nil
+	case OrderTypeMarketBuy, OrderTypeMarketSell:
+		return exchange.OrderTypeMarket, nil
+	case OrderTypeImmediateOrCancel:
+		return exchange.OrderTypeImmediateOrCancel, nil
+	case OrderTypeFillOrKill:
+		return exchange.OrderTypeFillOrKill, nil
+	default:
+		return "", errors.Newf(errors.ErrInvalidOrderType, "Gemini order type %q has no unified equivalent", orderType)
+	}
+}
+
+// Gemini order execution options
+const (
+	optionMakerOrCancel     = string(OrderOptionMakerOrCancel)
+	optionImmediateOrCancel = string(OrderOptionImmediateOrCancel)
+	optionFillOrKill        = string(OrderOptionFillOrKill)
+)
+
+// ToGeminiOptions converts a unified time in force to Gemini order execution options.
+// Good-till-cancelled is Gemini's default and needs no option.
+func ToGeminiOptions(tif exchange.TimeInForce) ([]string, error) {
+	switch tif {
+	case "", exchange.TimeInForceGTC:
+		return nil, nil
+	case exchange.TimeInForceIOC:
+		return []string{optionImmediateOrCancel}, nil
+	case exchange.TimeInForceFOK:
+		return []string{optionFillOrKill}, nil
+	case exchange.TimeInForcePostOnly:
+		return []string{optionMakerOrCancel}, nil
+	default:
+		return nil, errors.Newf(errors.ErrInvalidOrderType, "time in force %q is not supported by Gemini", tif)
+	}
+}
+
+// FromGeminiOptions derives the unified time in force from Gemini order execution options
+func FromGeminiOptions(options []string) exchange.TimeInForce {
+	for _, option := range options {
+		switch option {
+		case optionImmediateOrCancel:
+			return exchange.TimeInForceIOC
+		case optionFillOrKill:
+			return exchange.TimeInForceFOK
+		case optionMakerOrCancel:
+			return exchange.TimeInForcePostOnly
+		}
+	}
+	return exchange.TimeInForceGTC
+}
+
+// toNewOrderRequest translates a unified order request to a Gemini order request.
+// Limit orders carry their time in force as an execution option, and the unified IOC and FOK
+// types are treated as limit orders with that time in force. Market sells are immediate by
+// nature; market buys are rejected because Gemini sizes them by quote currency spend rather
+// than base amount (use OrderAPI.MarketBuy). Other combinations return ErrInvalidOrderType.
+func toNewOrderRequest(req exchange.GenericOrderRequest) (*NewOrderRequest, error) {
+	if req.Symbol == "" || req.Amount == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol and amount are required")
+	}
+	side := ToGeminiSide(req.Side)
+	if side == "" {
+		return nil, errors.Newf(errors.ErrInvalidInput, "invalid order side: %q", req.Side)
+	}
+
+	orderType, tif := req.Type, req.TimeInForce
+	switch orderType {
+	case exchange.OrderTypeImmediateOrCancel, exchange.OrderTypeFillOrKill:
+		implied := exchange.TimeInForceIOC
+		if orderType == exchange.OrderTypeFillOrKill {
+			implied = exchange.TimeInForceFOK
+		}
+		if tif != "" && tif != implied {
+			return nil, errors.Newf(errors.ErrInvalidOrderType, "order type %s conflicts with time in force %s", orderType, tif)
+		}
+		orderType, tif = exchange.OrderTypeLimit, implied
+	case exchange.OrderTypeMarket:
+		if side == OrderSideBuy {
+			return nil, errors.New(errors.ErrInvalidOrderType, "Gemini market buys are sized by quote currency spend; use OrderAPI.MarketBuy")
+		}
+		if tif != "" && tif != exchange.TimeInForceIOC {
+			return nil, errors.Newf(errors.ErrInvalidOrderType, "market orders do not support time in force %s", tif)
+		}
+		return &NewOrderRequest{
+			ClientOrderID: req.ClientOrderID,
+			Symbol:        strings.ToLower(req.Symbol),
+			Amount:        req.Amount,
+			Side:          side,
+			Type:          OrderTypeMarketSell,
+		}, nil
+	}
+
+	geminiType, err := ToGeminiType(orderType)
+	if err != nil {
+		return nil, err
+	}
+	if req.Price == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "price is required for limit orders")
+	}
+	options, err := ToGeminiOptions(tif)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NewOrderRequest{
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        strings.ToLower(req.Symbol),
+		Amount:        req.Amount,
+		Price:         req.Price,
+		Side:          side,
+		Type:          geminiType,
+		Options:       options,
+	}, nil
+}
+
+// toGenericOrder translates a Gemini order to a unified order
+func toGenericOrder(order *Order) *exchange.GenericOrder {
+	orderType, _ := FromGeminiType(order.Type)
+
+	status := exchange.OrderStatusFilled
+	switch {
+	case order.IsCancelled:
+		status = exchange.OrderStatusCancelled
+	case order.IsLive:
+		status = exchange.OrderStatusOpen
+	}
+
+	return &exchange.GenericOrder{
+		ID:              order.OrderID,
+		ClientOrderID:   order.ClientOrderID,
+		Symbol:          strings.ToUpper(order.Symbol),
+		Side:            FromGeminiSide(order.Side),
+		Type:            orderType,
+		TimeInForce:     FromGeminiOptions(order.Options),
+		Status:          status,
+		Amount:          order.OriginalAmount,
+		Price:           order.Price,
+		ExecutedAmount:  order.ExecutedAmount,
+		RemainingAmount: order.RemainingAmount,
+		Timestamp:       time.UnixMilli(int64(order.Timestampms)),
+	}
+}
+
+// toGenericTrades translates the trade events of a market data update to unified trades
+func toGenericTrades(symbol string, update MarketDataUpdate) []exchange.Trade {
+	var trades []exchange.Trade
+	for _, event := range update.Events {
+		if event.Type != "trade" {
+			continue
+		}
+
+		// The taker is on the side opposite the resting maker order
+		var side exchange.OrderSide
+		switch event.MakerSide {
+		case "bid":
+			side = exchange.OrderSideSell
+		case "ask":
+			side = exchange.OrderSideBuy
+		}
+
+		trades = append(trades, exchange.Trade{
+			ID:        strconv.FormatInt(int64(event.TID), 10),
+			Symbol:    strings.ToUpper(symbol),
+			Price:     event.Price,
+			Amount:    event.Amount,
+			Side:      side,
+			Timestamp: update.Time(),
+		})
+	}
+	return trades
+}
+
+// toGenericOrderBook converts a Gemini order book to exchange independent form
+func toGenericOrderBook(book *OrderBook) exchange.OrderBook {
+	return exchange.OrderBook{
+		Bids: toGenericPriceLevels(book.Bids),
+		Asks: toGenericPriceLevels(book.Asks),
+	}
+}
+
+// toGenericPriceLevels converts one side of a Gemini order book, keeping its order
+func toGenericPriceLevels(levels []BookLevel) []exchange.PriceLevel {
+	generic := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		generic = append(generic, exchange.PriceLevel{Price: level.Price, Quantity: level.Amount})
+	}
+	return generic
+}
@@ -0,0 +1,237 @@
+package gemini
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 -- test WebSocket handshake
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSideConversion(t *testing.T) {
+	for _, side := range []exchange.OrderSide{exchange.OrderSideBuy, exchange.OrderSideSell} {
+		assert.Equal(t, side, FromGeminiSide(ToGeminiSide(side)))
+	}
+	assert.Equal(t, OrderSideBuy, ToGeminiSide(exchange.OrderSideBuy))
+	assert.Empty(t, ToGeminiSide("HOLD"))
+	assert.Empty(t, FromGeminiSide("hold"))
+}
+
+func TestTypeConversion(t *testing.T) {
+	tests := []struct {
+		unified exchange.OrderType
+		gemini  OrderType
+	}{
+		{exchange.OrderTypeLimit, OrderTypeExchangeLimit},
+		{exchange.OrderTypeImmediateOrCancel, OrderTypeImmediateOrCancel},
+		{exchange.OrderTypeFillOrKill, OrderTypeFillOrKill},
+	}
+	for _, test := range tests {
+		converted, err := ToGeminiType(test.unified)
+		require.NoError(t, err)
+		assert.Equal(t, test.gemini, converted)
+
+		back, err := FromGeminiType(converted)
+		require.NoError(t, err)
+		assert.Equal(t, test.unified, back)
+	}
+
+	// Unsupported unified types fail explicitly
+	for _, orderType := range []exchange.OrderType{exchange.OrderTypeTrailingStop, exchange.OrderTypeStopLimit, exchange.OrderTypeMarket} {
+		_, err := ToGeminiType(orderType)
+		assert.Equal(t, errors.ErrInvalidOrderType, errors.GetCode(err), "expected error for %s", orderType)
+	}
+
+	// Gemini specific types have no unified equivalent
+	for _, orderType := range []OrderType{OrderTypeAuctionOnly, OrderTypeIndicationOfInterest} {
+		_, err := FromGeminiType(orderType)
+		assert.Equal(t, errors.ErrInvalidOrderType, errors.GetCode(err), "expected error for %s", orderType)
+	}
+}
+
+func TestToGeminiOrderType_Market(t *testing.T) {
+	buy, err := ToGeminiOrderType(exchange.OrderSideBuy, exchange.OrderTypeMarket)
+	require.NoError(t, err)
+	assert.Equal(t, OrderTypeMarketBuy, buy)
+
+	sell, err := ToGeminiOrderType(exchange.OrderSideSell, exchange.OrderTypeMarket)
+	require.NoError(t, err)
+	assert.Equal(t, OrderTypeMarketSell, sell)
+
+	_, err = ToGeminiOrderType("", exchange.OrderTypeMarket)
+	assert.Error(t, err)
+
+	limit, err := ToGeminiOrderType(exchange.OrderSideBuy, exchange.OrderTypeLimit)
+	require.NoError(t, err)
+	assert.Equal(t, OrderTypeExchangeLimit, limit)
+
+	unified, err := FromGeminiType(OrderTypeMarketSell)
+	require.NoError(t, err)
+	assert.Equal(t, exchange.OrderTypeMarket, unified)
+}
+
+func TestToNewOrderRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     exchange.GenericOrderRequest
+		typ     OrderType
+		options []string
+	}{
+		{"limit", exchange.GenericOrderRequest{Type: exchange.OrderTypeLimit}, OrderTypeExchangeLimit, nil},
+		{"limit gtc", exchange.GenericOrderRequest{Type: exchange.OrderTypeLimit, TimeInForce: exchange.TimeInForceGTC}, OrderTypeExchangeLimit, nil},
+		{"limit post only", exchange.GenericOrderRequest{Type: exchange.OrderTypeLimit, TimeInForce: exchange.TimeInForcePostOnly}, OrderTypeExchangeLimit, []string{"maker-or-cancel"}},
+		{"limit ioc", exchange.GenericOrderRequest{Type: exchange.OrderTypeLimit, TimeInForce: exchange.TimeInForceIOC}, OrderTypeExchangeLimit, []string{"immediate-or-cancel"}},
+		{"fok type", exchange.GenericOrderRequest{Type: exchange.OrderTypeFillOrKill}, OrderTypeExchangeLimit, []string{"fill-or-kill"}},
+		{"market sell", exchange.GenericOrderRequest{Type: exchange.OrderTypeMarket, Side: exchange.OrderSideSell}, OrderTypeMarketSell, nil},
+	}
+	for _, test := range tests {
+		req := test.req
+		req.Symbol, req.Amount, req.Price = "BTCUSD", "1", "30000"
+		if req.Side == "" {
+			req.Side = exchange.OrderSideBuy
+		}
+
+		converted, err := toNewOrderRequest(req)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, "btcusd", converted.Symbol, test.name)
+		assert.Equal(t, test.typ, converted.Type, test.name)
+		assert.Equal(t, test.options, converted.Options, test.name)
+	}
+
+	invalid := map[string]exchange.GenericOrderRequest{
+		"trailing stop":         {Type: exchange.OrderTypeTrailingStop, Side: exchange.OrderSideBuy, Price: "1"},
+		"market buy":            {Type: exchange.OrderTypeMarket, Side: exchange.OrderSideBuy},
+		"market post only":      {Type: exchange.OrderTypeMarket, Side: exchange.OrderSideSell, TimeInForce: exchange.TimeInForcePostOnly},
+		"ioc type with fok tif": {Type: exchange.OrderTypeImmediateOrCancel, Side: exchange.OrderSideBuy, Price: "1", TimeInForce: exchange.TimeInForceFOK},
+		"unknown tif":           {Type: exchange.OrderTypeLimit, Side: exchange.OrderSideBuy, Price: "1", TimeInForce: "GTD"},
+	}
+	for name, req := range invalid {
+		req.Symbol, req.Amount = "btcusd", "1"
+		_, err := toNewOrderRequest(req)
+		assert.Equal(t, errors.ErrInvalidOrderType, errors.GetCode(err), name)
+	}
+
+	_, err := toNewOrderRequest(exchange.GenericOrderRequest{Symbol: "btcusd", Amount: "1", Side: exchange.OrderSideBuy, Type: exchange.OrderTypeLimit})
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err), "missing price")
+}
+
+func TestGemini_PlaceOrder_Generic(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`{"order_id":"42","symbol":"btcusd","side":"buy","type":"exchange limit","timestampms":1700000000000,
+			"is_live":true,"is_cancelled":false,"executed_amount":"0","remaining_amount":"1","original_amount":"1","price":"30000.00",
+			"options":["maker-or-cancel"],"client_order_id":"my-order"}`))
+	})
+
+	var exch exchange.Exchange = g
+	order, err := exch.PlaceOrder(context.Background(), exchange.GenericOrderRequest{
+		Symbol:        "BTCUSD",
+		Side:          exchange.OrderSideBuy,
+		Type:          exchange.OrderTypeLimit,
+		Amount:        "1",
+		Price:         "30000.00",
+		TimeInForce:   exchange.TimeInForcePostOnly,
+		ClientOrderID: "my-order",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "exchange limit", payload["type"])
+	assert.Equal(t, []interface{}{"maker-or-cancel"}, payload["options"])
+
+	assert.Equal(t, "42", order.ID)
+	assert.Equal(t, "BTCUSD", order.Symbol)
+	assert.Equal(t, exchange.OrderSideBuy, order.Side)
+	assert.Equal(t, exchange.OrderTypeLimit, order.Type)
+	assert.Equal(t, exchange.TimeInForcePostOnly, order.TimeInForce)
+	assert.Equal(t, exchange.OrderStatusOpen, order.Status)
+	assert.Equal(t, int64(1700000000000), order.Timestamp.UnixMilli())
+}
+
+func TestGemini_SubscribeTrades(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/marketdata/btcusd", r.URL.Path)
+		h := sha1.New() // #nosec G401 -- test WebSocket handshake
+		h.Write([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		accept := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept + "\r\n\r\n")
+
+		for _, message := range []string{
+			`{"type":"update","eventId":1,"socket_sequence":0,"events":[{"type":"change","side":"bid","price":"100","remaining":"1","delta":"1","reason":"initial"}]}`,
+			`{"type":"update","eventId":2,"timestampms":1700000000000,"socket_sequence":1,"events":[` +
+				`{"type":"trade","tid":2,"price":"101","amount":"0.5","makerSide":"ask"},` +
+				`{"type":"change","side":"ask","price":"101","remaining":"0","delta":"-0.5","reason":"trade"},` +
+				`{"type":"trade","tid":3,"price":"100","amount":"0.25","makerSide":"bid"}]}`,
+		} {
+			writeTestWebSocketText(rw, message)
+		}
+		_ = rw.Flush()
+
+		// Hold the connection open until the client goes away
+		_, _ = rw.ReadByte()
+	})
+
+	streaming, ok := interface{}(g).(exchange.StreamingExchange)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	trades, err := streaming.SubscribeTrades(ctx, "btcusd")
+	require.NoError(t, err)
+
+	var received []exchange.Trade
+	timeout := time.After(5 * time.Second)
+	for len(received) < 2 {
+		select {
+		case trade := <-trades:
+			received = append(received, trade)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for trades, got %d", len(received))
+		}
+	}
+	assert.Equal(t, exchange.Trade{
+		ID:        "2",
+		Symbol:    "BTCUSD",
+		Price:     "101",
+		Amount:    "0.5",
+		Side:      exchange.OrderSideBuy,
+		Timestamp: time.UnixMilli(1700000000000),
+	}, received[0])
+	assert.Equal(t, exchange.OrderSideSell, received[1].Side)
+
+	// The channel is closed once the subscription's context is done
+	cancel()
+	for range trades {
+	}
+}
+
+func TestGemini_GetOrderBook_Generic(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/book/btcusd", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("limit_bids"))
+		_, _ = w.Write([]byte(`{"bids":[{"price":"3607.85","amount":"6.643373","timestamp":"1547147541"},{"price":"3607.40","amount":"14.68205084","timestamp":"1547147541"}],` +
+			`"asks":[{"price":"3607.86","amount":"14.68205084","timestamp":"1547147541"}]}`))
+	})
+
+	var exch exchange.Exchange = g
+	book, err := exch.GetOrderBook(context.Background(), "btcusd", 2)
+	require.NoError(t, err)
+	require.Len(t, book.Bids, 2)
+	require.Len(t, book.Asks, 1)
+	assert.Equal(t, "3607.85", book.Bids[0].Price.StringFixed(2))
+	assert.Equal(t, "6.643373", book.Bids[0].Quantity.String())
+	assert.Equal(t, "3607.4", book.Bids[1].Price.String())
+	assert.Equal(t, "3607.86", book.Asks[0].Price.String())
+
+	_, err = exch.GetOrderBook(context.Background(), "", 2)
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
@@ -0,0 +1,69 @@
+package gemini
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// failoverThreshold is the number of consecutive failures before switching base URL
+	failoverThreshold = 3
+	// failoverCooldown is how long a failed base URL is skipped when picking the next one
+	failoverCooldown = 30 * time.Second
+)
+
+// baseURLFailover tracks the health of several base URLs and rotates between them
+type baseURLFailover struct {
+	urls      []string
+	index     int
+	failures  int
+	unhealthy map[string]time.Time
+	mu        sync.Mutex
+}
+
+// newBaseURLFailover creates a failover tracker for the given base URLs
+func newBaseURLFailover(urls []string) *baseURLFailover {
+	return &baseURLFailover{
+		urls:      urls,
+		unhealthy: make(map[string]time.Time),
+	}
+}
+
+// observe records the outcome of a request and returns the base URL to use next.
+// Only results for the active base URL count; connection errors and 5xx responses are failures.
+func (f *baseURLFailover) observe(url string, statusCode int, err error) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	active := f.urls[f.index]
+	if !strings.HasPrefix(url, active) {
+		return active
+	}
+
+	if err == nil && statusCode < 500 {
+		f.failures = 0
+		return active
+	}
+
+	f.failures++
+	if f.failures < failoverThreshold {
+		return active
+	}
+
+	// Mark the active URL unhealthy and move to the next one that is not cooling down.
+	// If every URL has failed recently, simply advance to the next one.
+	now := time.Now()
+	f.unhealthy[active] = now
+	f.failures = 0
+	next := (f.index + 1) % len(f.urls)
+	for i := 1; i < len(f.urls); i++ {
+		candidate := (f.index + i) % len(f.urls)
+		if failedAt, ok := f.unhealthy[f.urls[candidate]]; !ok || now.Sub(failedAt) >= failoverCooldown {
+			next = candidate
+			break
+		}
+	}
+	f.index = next
+	return f.urls[f.index]
+}
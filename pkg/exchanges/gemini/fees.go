@@ -0,0 +1,255 @@
+package gemini
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// feeScheduleTTL is how long a fee schedule is reused before being refetched.
+// Gemini recalculates fee tiers from 30 day volume, so they change slowly.
+const feeScheduleTTL = 15 * time.Minute
+
+// bpsPerUnit converts basis points to a fraction
+var bpsPerUnit = decimal.NewFromInt(10000)
+
+// DailyNotionalVolume is the notional volume traded on a single day
+type DailyNotionalVolume struct {
+	Date           string  `json:"date"`
+	NotionalVolume float64 `json:"notional_volume"`
+}
+
+// NotionalVolume represents the account's trading volume and the fee tiers it qualifies for.
+// Fees are quoted in basis points for each order entry channel.
+type NotionalVolume struct {
+	WebMakerFeeBps    int                   `json:"web_maker_fee_bps"`
+	WebTakerFeeBps    int                   `json:"web_taker_fee_bps"`
+	WebAuctionFeeBps  int                   `json:"web_auction_fee_bps"`
+	APIMakerFeeBps    int                   `json:"api_maker_fee_bps"`
+	APITakerFeeBps    int                   `json:"api_taker_fee_bps"`
+	APIAuctionFeeBps  int                   `json:"api_auction_fee_bps"`
+	FIXMakerFeeBps    int                   `json:"fix_maker_fee_bps"`
+	FIXTakerFeeBps    int                   `json:"fix_taker_fee_bps"`
+	FIXAuctionFeeBps  int                   `json:"fix_auction_fee_bps"`
+	BlockMakerFeeBps  int                   `json:"block_maker_fee_bps"`
+	BlockTakerFeeBps  int                   `json:"block_taker_fee_bps"`
+	Notional30dVolume float64               `json:"notional_30d_volume"`
+	LastUpdatedMs     FlexInt               `json:"last_updated_ms"`
+	Date              string                `json:"date"`
+	Notional1dVolume  []DailyNotionalVolume `json:"notional_1d_volume"`
+}
+
+// GetNotionalVolumeRequest represents the request payload for getting notional volume
+type GetNotionalVolumeRequest struct {
+	Request string `json:"request"`
+	Nonce   string `json:"nonce"`
+	Account string `json:"account,omitempty"`
+}
+
+// SymbolFee holds maker and taker fees in basis points that apply to a single symbol
+type SymbolFee struct {
+	MakerBps int
+	TakerBps int
+}
+
+// FeeSchedule holds the fees an account pays on API orders, for computing fees locally
+// without querying the exchange for every order
+type FeeSchedule struct {
+	Account   string
+	MakerBps  int
+	TakerBps  int
+	Overrides map[string]SymbolFee
+	FetchedAt time.Time
+}
+
+// MakerFee returns the maker fee rate for a symbol as a fraction of notional (10 bps is 0.001)
+func (s *FeeSchedule) MakerFee(symbol string) decimal.Decimal {
+	bps := s.MakerBps
+	if override, ok := s.Overrides[strings.ToLower(symbol)]; ok {
+		bps = override.MakerBps
+	}
+	return decimal.NewFromInt(int64(bps)).Div(bpsPerUnit)
+}
+
+// TakerFee returns the taker fee rate for a symbol as a fraction of notional (35 bps is 0.0035)
+func (s *FeeSchedule) TakerFee(symbol string) decimal.Decimal {
+	bps := s.TakerBps
+	if override, ok := s.Overrides[strings.ToLower(symbol)]; ok {
+		bps = override.TakerBps
+	}
+	return decimal.NewFromInt(int64(bps)).Div(bpsPerUnit)
+}
+
+// BreakEvenSpread returns, in basis points, the spread a round trip in symbol must capture to
+// cover its fees when one leg rests as a maker and the other takes liquidity. It is the sum of
+// the maker and taker fees; the fee charged on the spread itself is small enough to ignore.
+func (s *FeeSchedule) BreakEvenSpread(symbol string) float64 {
+	return s.MakerFee(symbol).Add(s.TakerFee(symbol)).Mul(bpsPerUnit).InexactFloat64()
+}
+
+// EstimateFee returns the fee an order would pay in the quote currency if filled in full.
+// Notional is amount times price, or the total spend of a market buy. Market sells have no
+// price to go by and return ErrInvalidInput.
+func (s *FeeSchedule) EstimateFee(order *NewOrderRequest, isMaker bool) (decimal.Decimal, error) {
+	if order == nil {
+		return decimal.Zero, errors.New(errors.ErrInvalidInput, "order request is required")
+	}
+
+	var notional decimal.Decimal
+	if order.TotalSpend != "" {
+		spend, err := decimal.NewFromString(order.TotalSpend)
+		if err != nil {
+			return decimal.Zero, errors.Newf(errors.ErrInvalidInput, "invalid total spend: %q", order.TotalSpend)
+		}
+		notional = spend
+	} else {
+		amount, err := decimal.NewFromString(order.Amount)
+		if err != nil {
+			return decimal.Zero, errors.Newf(errors.ErrInvalidInput, "invalid order amount: %q", order.Amount)
+		}
+		if order.Price == "" {
+			return decimal.Zero, errors.Newf(errors.ErrInvalidInput, "price is required to estimate the fee of %s orders", order.Type)
+		}
+		price, err := decimal.NewFromString(order.Price)
+		if err != nil {
+			return decimal.Zero, errors.Newf(errors.ErrInvalidInput, "invalid order price: %q", order.Price)
+		}
+		notional = amount.Mul(price)
+	}
+
+	rate := s.TakerFee(order.Symbol)
+	if isMaker {
+		rate = s.MakerFee(order.Symbol)
+	}
+	return notional.Mul(rate), nil
+}
+
+// feeScheduleCache shares fee schedules between calls, keyed by account
+type feeScheduleCache struct {
+	ttl       time.Duration
+	schedules map[string]*FeeSchedule
+	overrides map[string]SymbolFee
+	mu        sync.Mutex
+}
+
+// newFeeScheduleCache creates a new fee schedule cache
+func newFeeScheduleCache(ttl time.Duration) *feeScheduleCache {
+	return &feeScheduleCache{
+		ttl:       ttl,
+		schedules: make(map[string]*FeeSchedule),
+		overrides: make(map[string]SymbolFee),
+	}
+}
+
+// get returns the cached schedule for an account if it is still fresh
+func (c *feeScheduleCache) get(account string) (*FeeSchedule, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	schedule, ok := c.schedules[account]
+	if !ok || time.Since(schedule.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return schedule, true
+}
+
+// put builds a schedule from notional volume and the configured overrides and stores it
+func (c *feeScheduleCache) put(account string, volume *NotionalVolume) *FeeSchedule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	overrides := make(map[string]SymbolFee, len(c.overrides))
+	for symbol, fee := range c.overrides {
+		overrides[symbol] = fee
+	}
+	schedule := &FeeSchedule{
+		Account:   account,
+		MakerBps:  volume.APIMakerFeeBps,
+		TakerBps:  volume.APITakerFeeBps,
+		Overrides: overrides,
+		FetchedAt: time.Now(),
+	}
+	c.schedules[account] = schedule
+	return schedule
+}
+
+// setOverride sets the fees for a symbol and drops cached schedules so they pick it up
+func (c *feeScheduleCache) setOverride(symbol string, fee SymbolFee) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overrides[strings.ToLower(symbol)] = fee
+	c.schedules = make(map[string]*FeeSchedule)
+}
+
+// setTTL changes how long schedules are reused
+func (c *feeScheduleCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+// SetFeeOverride sets maker and taker fees in basis points for a symbol, for symbols with
+// promotional or negotiated fees that differ from the account's tier. Overrides apply to
+// every fee schedule returned by GetFeeSchedule.
+func (a *AccountAPI) SetFeeOverride(symbol string, makerBps, takerBps int) {
+	a.fees.setOverride(symbol, SymbolFee{MakerBps: makerBps, TakerBps: takerBps})
+}
+
+// SetFeeScheduleTTL sets how long GetFeeSchedule reuses a fetched schedule. Zero or negative
+// values restore the default.
+func (a *AccountAPI) SetFeeScheduleTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = feeScheduleTTL
+	}
+	a.fees.setTTL(ttl)
+}
+
+// GetFeeSchedule returns the account's API maker and taker fees combined with any symbol
+// overrides. Schedules are cached per account and refetched from notional volume once stale.
+func (a *AccountAPI) GetFeeSchedule(ctx context.Context, account string) (*FeeSchedule, error) {
+	if schedule, ok := a.fees.get(account); ok {
+		return schedule, nil
+	}
+	volume, err := a.GetNotionalVolume(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return a.fees.put(account, volume), nil
+}
+
+// BreakEvenSpread returns, in basis points, the spread a maker-taker round trip in symbol must
+// capture to cover fees at the fee tier of the default account. See FeeSchedule.BreakEvenSpread;
+// use GetFeeSchedule for another account.
+func (a *AccountAPI) BreakEvenSpread(ctx context.Context, symbol string) (float64, error) {
+	if symbol == "" {
+		return 0, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+	schedule, err := a.GetFeeSchedule(ctx, a.gemini.DefaultAccount())
+	if err != nil {
+		return 0, err
+	}
+	return schedule.BreakEvenSpread(symbol), nil
+}
+
+// GetNotionalVolume fetches the account's 30 day trading volume and fee tiers
+// This implements the private API: https://docs.gemini.com/rest/fee-and-volume#get-notional-volume
+func (a *AccountAPI) GetNotionalVolume(ctx context.Context, account string) (*NotionalVolume, error) {
+	volume, err := a.getNotionalVolume(ctx, account)
+	a.gemini.audit("GetNotionalVolume", func() map[string]interface{} {
+		return map[string]interface{}{"account": account}
+	}, volume, err)
+	return volume, err
+}
+
+// getNotionalVolume fetches notional volume without auditing
+func (a *AccountAPI) getNotionalVolume(ctx context.Context, account string) (*NotionalVolume, error) {
+	var volume NotionalVolume
+	if err := a.gemini.doSignedPost(ctx, "/v1/notionalvolume", GetNotionalVolumeRequest{Account: account}, &volume); err != nil {
+		return nil, err
+	}
+
+	a.gemini.logger.Debug().Int("api_maker_fee_bps", volume.APIMakerFeeBps).Int("api_taker_fee_bps", volume.APITakerFeeBps).Msg("Successfully fetched notional volume")
+	return &volume, nil
+}
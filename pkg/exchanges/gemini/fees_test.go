@@ -0,0 +1,116 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notionalVolumeResponse is the example response from the Gemini docs
+const notionalVolumeResponse = `{
+	"web_maker_fee_bps": 25,
+	"web_taker_fee_bps": 35,
+	"web_auction_fee_bps": 25,
+	"api_maker_fee_bps": 10,
+	"api_taker_fee_bps": 35,
+	"api_auction_fee_bps": 20,
+	"fix_maker_fee_bps": 10,
+	"fix_taker_fee_bps": 35,
+	"fix_auction_fee_bps": 20,
+	"block_maker_fee_bps": 0,
+	"block_taker_fee_bps": 50,
+	"notional_30d_volume": 150.00,
+	"last_updated_ms": 1551371446000,
+	"date": "2019-02-28",
+	"notional_1d_volume": [
+		{"date": "2019-02-22", "notional_volume": 75.00},
+		{"date": "2019-02-14", "notional_volume": 75.00}
+	]
+}`
+
+func TestAccountAPI_GetFeeSchedule(t *testing.T) {
+	calls := 0
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/notionalvolume", r.URL.Path)
+		payload := decodeTestPayload(t, r)
+		assert.Equal(t, "primary", payload["account"])
+		calls++
+		_, _ = w.Write([]byte(notionalVolumeResponse))
+	})
+	g.Account.SetFeeOverride("ETHUSD", 0, 20)
+	ctx := context.Background()
+
+	schedule, err := g.Account.GetFeeSchedule(ctx, "primary")
+	require.NoError(t, err)
+	assert.Equal(t, 10, schedule.MakerBps)
+	assert.Equal(t, 35, schedule.TakerBps)
+	assert.Equal(t, "0.001", schedule.MakerFee("btcusd").String())
+	assert.Equal(t, "0.0035", schedule.TakerFee("btcusd").String())
+	assert.Equal(t, "0", schedule.MakerFee("ethusd").String())
+	assert.Equal(t, "0.002", schedule.TakerFee("ethusd").String())
+
+	// Served from the cache until it goes stale
+	_, err = g.Account.GetFeeSchedule(ctx, "primary")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// Changing an override drops cached schedules
+	g.Account.SetFeeOverride("ethusd", 5, 15)
+	schedule, err = g.Account.GetFeeSchedule(ctx, "primary")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "0.0015", schedule.TakerFee("ethusd").String())
+}
+
+func TestAccountAPI_BreakEvenSpread(t *testing.T) {
+	var accounts []interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		accounts = append(accounts, decodeTestPayload(t, r)["account"])
+		_, _ = w.Write([]byte(notionalVolumeResponse))
+	})
+	g.SetDefaultAccount("trading")
+	g.Account.SetFeeOverride("ethusd", 0, 20)
+	ctx := context.Background()
+
+	spread, err := g.Account.BreakEvenSpread(ctx, "btcusd")
+	require.NoError(t, err)
+	assert.Equal(t, 45.0, spread)
+	spread, err = g.Account.BreakEvenSpread(ctx, "ETHUSD")
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, spread)
+	assert.Equal(t, []interface{}{"trading"}, accounts)
+
+	_, err = g.Account.BreakEvenSpread(ctx, "")
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
+
+func TestFeeSchedule_EstimateFee(t *testing.T) {
+	schedule := &FeeSchedule{MakerBps: 10, TakerBps: 35, Overrides: map[string]SymbolFee{"ethusd": {MakerBps: 0, TakerBps: 20}}}
+
+	limit := &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Price: "30000", Type: OrderTypeExchangeLimit}
+	fee, err := schedule.EstimateFee(limit, true)
+	require.NoError(t, err)
+	assert.Equal(t, "15", fee.String())
+	fee, err = schedule.EstimateFee(limit, false)
+	require.NoError(t, err)
+	assert.Equal(t, "52.5", fee.String())
+
+	override := &NewOrderRequest{Symbol: "ETHUSD", Amount: "2", Price: "2000", Type: OrderTypeExchangeLimit}
+	fee, err = schedule.EstimateFee(override, false)
+	require.NoError(t, err)
+	assert.Equal(t, "8", fee.String())
+
+	marketBuy := &NewOrderRequest{Symbol: "btcusd", TotalSpend: "1000", Type: OrderTypeMarketBuy}
+	fee, err = schedule.EstimateFee(marketBuy, false)
+	require.NoError(t, err)
+	assert.Equal(t, "3.5", fee.String())
+
+	marketSell := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Type: OrderTypeMarketSell}
+	_, err = schedule.EstimateFee(marketSell, false)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
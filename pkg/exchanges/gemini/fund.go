@@ -2,17 +2,18 @@ package gemini
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/base64"
-	"encoding/hex"
+	"crypto/rand"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
-	"strconv"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 // FundAPI handles fund management related operations
@@ -36,6 +37,28 @@ type Balance struct {
 	AvailableForWithdrawal string `json:"availableForWithdrawal"`
 }
 
+// AmountFloat returns the total amount as a float64
+func (b Balance) AmountFloat() (float64, error) {
+	return parseFloatFromString(b.Amount)
+}
+
+// AvailableFloat returns the amount available for trading as a float64
+func (b Balance) AvailableFloat() (float64, error) {
+	return parseFloatFromString(b.Available)
+}
+
+// AvailableForWithdrawalFloat returns the amount available for withdrawal as a float64
+func (b Balance) AvailableForWithdrawalFloat() (float64, error) {
+	return parseFloatFromString(b.AvailableForWithdrawal)
+}
+
+// NonZero reports whether the balance holds a non-zero amount.
+// Balances with an unparseable amount are reported as zero.
+func (b Balance) NonZero() bool {
+	amount, err := b.AmountFloat()
+	return err == nil && amount != 0
+}
+
 // GetAvailableBalancesRequest represents the request payload for getting available balances
 type GetAvailableBalancesRequest struct {
 	Request string `json:"request"`
@@ -46,68 +69,36 @@ type GetAvailableBalancesRequest struct {
 // GetAvailableBalances fetches available balances for the account
 // This implements the private API: https://docs.gemini.com/rest/fund-management#get-available-balances
 func (f *FundAPI) GetAvailableBalances(ctx context.Context, account string) ([]Balance, error) {
-	if f.gemini.apiKey == "" || f.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
-	}
-
-	endpoint := "/v1/balances"
-	url := fmt.Sprintf("%s%s", f.gemini.baseURL, endpoint)
-
-	// Create request payload
-	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
-	request := GetAvailableBalancesRequest{
-		Request: endpoint,
-		Nonce:   nonce,
-		Account: account,
-	}
-
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(request)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal request payload", err)
-	}
-
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
-
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(f.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
-
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    f.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
-	}
-
-	f.gemini.logger.Debug().Str("url", url).Str("account", account).Msg("Fetching available balances")
-
-	// Make POST request with authentication headers
-	response, err := f.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch available balances", err)
-	}
-
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
-	}
+	balances, err := f.getAvailableBalances(ctx, account)
+	f.gemini.audit("GetAvailableBalances", func() map[string]interface{} {
+		return map[string]interface{}{"account": account}
+	}, balances, err)
+	return balances, err
+}
 
+// getAvailableBalances fetches available balances without auditing
+func (f *FundAPI) getAvailableBalances(ctx context.Context, account string) ([]Balance, error) {
 	var balances []Balance
-	if err := json.Unmarshal(response, &balances); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse balances response", err)
+	if err := f.gemini.doSignedPost(ctx, "/v1/balances", GetAvailableBalancesRequest{Account: account}, &balances); err != nil {
+		return nil, err
 	}
 
 	f.gemini.logger.Debug().Int("count", len(balances)).Msg("Successfully fetched available balances")
 	return balances, nil
 }
 
+// GetAllAccountBalances fetches available balances for each of the given accounts, a few at a
+// time. Results and per-account errors are keyed by account name; all requests share the private
+// rate limiter. If ctx is done, the accounts not fetched get ctx.Err().
+func (f *FundAPI) GetAllAccountBalances(ctx context.Context, accounts []string) (map[string][]Balance, map[string]error) {
+	results, errs := forEachBounded(ctx, privateRequestWorkers, accounts, func(account string) ([]Balance, error) {
+		return f.GetAvailableBalances(ctx, account)
+	})
+
+	f.gemini.logger.Debug().Int("accounts", len(accounts)).Int("failed", len(errs)).Msg("Fetched balances for all accounts")
+	return results, errs
+}
+
 // NotionalBalance represents notional balance information
 type NotionalBalance struct {
 	Currency                       string `json:"currency"`
@@ -128,75 +119,114 @@ type GetNotionalBalancesRequest struct {
 
 // GetNotionalBalances fetches notional balances in the specified currency
 func (f *FundAPI) GetNotionalBalances(ctx context.Context, currency string, account string) ([]NotionalBalance, error) {
-	if f.gemini.apiKey == "" || f.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
-	}
+	balances, err := f.getNotionalBalances(ctx, currency, account)
+	f.gemini.audit("GetNotionalBalances", func() map[string]interface{} {
+		return map[string]interface{}{"currency": currency, "account": account}
+	}, balances, err)
+	return balances, err
+}
 
+// getNotionalBalances fetches notional balances without auditing
+func (f *FundAPI) getNotionalBalances(ctx context.Context, currency string, account string) ([]NotionalBalance, error) {
 	endpoint := fmt.Sprintf("/v1/notionalbalances/%s", currency)
-	url := fmt.Sprintf("%s%s", f.gemini.baseURL, endpoint)
-
-	// Create request payload
-	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
-	request := GetNotionalBalancesRequest{
-		Request: endpoint,
-		Nonce:   nonce,
-		Account: account,
+	var balances []NotionalBalance
+	if err := f.gemini.doSignedPost(ctx, endpoint, GetNotionalBalancesRequest{Account: account}, &balances); err != nil {
+		return nil, err
 	}
 
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(request)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal request payload", err)
-	}
+	f.gemini.logger.Debug().Int("count", len(balances)).Str("currency", currency).Msg("Successfully fetched notional balances")
+	return balances, nil
+}
 
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
+// DepositAddress represents a deposit address
+type DepositAddress struct {
+	Address   string  `json:"address"`
+	Timestamp FlexInt `json:"timestamp"` // Creation time in milliseconds
+	Label     string  `json:"label,omitempty"`
+	Memo      string  `json:"memo,omitempty"`
+	Network   string  `json:"network"`
+
+	// Format is the address encoding for bitcoin and litecoin addresses, derived from the address
+	Format AddressFormat `json:"-"`
+}
 
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(f.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
+// CreatedAt returns the time the address was created
+func (a *DepositAddress) CreatedAt() time.Time {
+	return timestampTime(a.Timestamp, 0)
+}
 
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    f.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
-	}
+// AddressFormat represents the encoding of a bitcoin or litecoin address
+type AddressFormat string
+
+const (
+	// AddressFormatBech32 is a native SegWit address, such as bc1... or ltc1...
+	AddressFormatBech32 AddressFormat = "bech32"
+	// AddressFormatP2SH is a legacy pay-to-script-hash address, such as 3... or M...
+	AddressFormatP2SH AddressFormat = "p2sh"
+	// AddressFormatP2PKH is a legacy pay-to-public-key-hash address, such as 1... or L...
+	AddressFormatP2PKH AddressFormat = "p2pkh"
+	// AddressFormatUnknown is used for other networks and unrecognized addresses
+	AddressFormatUnknown AddressFormat = ""
+)
 
-	f.gemini.logger.Debug().Str("url", url).Str("currency", currency).Str("account", account).Msg("Fetching notional balances")
+// addressFormat classifies a bitcoin or litecoin address by its prefix
+func addressFormat(network, address string) AddressFormat {
+	switch strings.ToLower(network) {
+	case "bitcoin", "litecoin":
+	default:
+		return AddressFormatUnknown
+	}
 
-	// Make POST request with authentication headers
-	response, err := f.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch notional balances", err)
+	lower := strings.ToLower(address)
+	for _, hrp := range []string{"bc1", "tb1", "bcrt1", "ltc1", "tltc1", "rltc1"} {
+		if strings.HasPrefix(lower, hrp) {
+			return AddressFormatBech32
+		}
+	}
+	if address == "" {
+		return AddressFormatUnknown
+	}
+	switch address[0] {
+	case '3', '2', 'M', 'Q':
+		return AddressFormatP2SH
+	case '1', 'm', 'n', 'L':
+		return AddressFormatP2PKH
 	}
+	return AddressFormatUnknown
+}
+
+// CurrencyNetworks lists the networks a currency can be deposited and withdrawn on
+type CurrencyNetworks struct {
+	Token    string   `json:"token"`
+	Networks []string `json:"network"`
+}
 
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
+// GetCurrencyNetworks fetches the networks supported for a currency, for example ethereum and
+// solana for USDC. The network names are the ones ListDepositAddresses and CreateDepositAddress
+// accept. Gemini does not publish per-network confirmation counts or withdrawal minimums.
+// This implements the public API: https://docs.gemini.com/rest/fund-management#list-networks
+func (f *FundAPI) GetCurrencyNetworks(ctx context.Context, currency string) (*CurrencyNetworks, error) {
+	if currency == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "currency is required")
 	}
 
-	var balances []NotionalBalance
-	if err := json.Unmarshal(response, &balances); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse notional balances response", err)
+	url := fmt.Sprintf("%s/v1/network/%s", f.gemini.getBaseURL(), strings.ToLower(currency))
+
+	f.gemini.logger.Debug().Str("url", url).Str("currency", currency).Msg("Fetching currency networks")
+
+	// This is a public API, no authentication required
+	response, err := f.gemini.client.GetWithType(ctx, url, client.APITypePublic)
+	if err != nil {
+		return nil, requestError("failed to fetch currency networks", err)
 	}
 
-	f.gemini.logger.Debug().Int("count", len(balances)).Str("currency", currency).Msg("Successfully fetched notional balances")
-	return balances, nil
-}
+	var networks CurrencyNetworks
+	if err := jsonUnmarshal(response, &networks); err != nil {
+		return nil, parseError(response, url, "failed to parse currency networks response", err)
+	}
 
-// DepositAddress represents a deposit address
-type DepositAddress struct {
-	Address   string `json:"address"`
-	Timestamp int64  `json:"timestamp"`
-	Label     string `json:"label,omitempty"`
-	Memo      string `json:"memo,omitempty"`
-	Network   string `json:"network"`
+	f.gemini.logger.Debug().Str("currency", currency).Strs("networks", networks.Networks).Msg("Successfully fetched currency networks")
+	return &networks, nil
 }
 
 // ListDepositAddressesRequest represents the request payload for listing deposit addresses
@@ -208,64 +238,459 @@ type ListDepositAddressesRequest struct {
 
 // ListDepositAddresses fetches deposit addresses for the specified network
 func (f *FundAPI) ListDepositAddresses(ctx context.Context, network string, account string) ([]DepositAddress, error) {
-	if f.gemini.apiKey == "" || f.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
-	}
+	addresses, err := f.listDepositAddresses(ctx, network, account)
+	f.gemini.audit("ListDepositAddresses", func() map[string]interface{} {
+		return map[string]interface{}{"network": network, "account": account}
+	}, addresses, err)
+	return addresses, err
+}
 
+// listDepositAddresses fetches deposit addresses for a network without auditing
+func (f *FundAPI) listDepositAddresses(ctx context.Context, network string, account string) ([]DepositAddress, error) {
 	endpoint := fmt.Sprintf("/v1/addresses/%s", network)
-	url := fmt.Sprintf("%s%s", f.gemini.baseURL, endpoint)
+	var addresses []DepositAddress
+	if err := f.gemini.doSignedPost(ctx, endpoint, ListDepositAddressesRequest{Account: account}, &addresses); err != nil {
+		return nil, err
+	}
+	for i := range addresses {
+		addresses[i].Format = addressFormat(addresses[i].Network, addresses[i].Address)
+	}
+
+	f.gemini.logger.Debug().Int("count", len(addresses)).Str("network", network).Msg("Successfully listed deposit addresses")
+	return addresses, nil
+}
+
+// ListAllDepositAddresses fetches deposit addresses for each of the given networks, a few at a time, keyed by network.
+// Networks that fail are left out of the result and reported together in the returned error;
+// all requests share the private rate limiter.
+func (f *FundAPI) ListAllDepositAddresses(ctx context.Context, networks []string, account string) (map[string][]DepositAddress, error) {
+	results, errs := forEachBounded(ctx, privateRequestWorkers, networks, func(network string) ([]DepositAddress, error) {
+		return f.ListDepositAddresses(ctx, network, account)
+	})
+
+	f.gemini.logger.Debug().Int("networks", len(networks)).Int("failed", len(errs)).Msg("Fetched deposit addresses for all networks")
+	if len(errs) == 0 {
+		return results, nil
+	}
+
+	failed := make([]string, 0, len(errs))
+	for network, err := range errs {
+		failed = append(failed, fmt.Sprintf("%s: %v", network, err))
+	}
+	sort.Strings(failed)
+	return results, errors.Newf(errors.ErrAPIError, "failed to list deposit addresses for %d of %d networks", len(errs), len(networks)).
+		WithDetails(strings.Join(failed, "; "))
+}
 
-	// Create request payload
-	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
-	request := ListDepositAddressesRequest{
-		Request: endpoint,
-		Nonce:   nonce,
-		Account: account,
+// WithdrawCryptoRequest represents the request payload for withdrawing crypto funds
+type WithdrawCryptoRequest struct {
+	Request          string `json:"request"`
+	Nonce            string `json:"nonce"`
+	Address          string `json:"address"`
+	Amount           string `json:"amount"`
+	Memo             string `json:"memo,omitempty"`
+	ClientTransferID string `json:"client_transfer_id,omitempty"`
+	Account          string `json:"account,omitempty"`
+
+	// ConfirmProduction must be set to withdraw through any base URL but the sandbox API while the withdrawal guard is enabled
+	ConfirmProduction bool `json:"-"`
+}
+
+// WithdrawCryptoResponse represents the response of a crypto withdrawal
+type WithdrawCryptoResponse struct {
+	Address      string `json:"address"`
+	Amount       string `json:"amount"`
+	Fee          string `json:"fee,omitempty"`
+	WithdrawalID string `json:"withdrawalId,omitempty"`
+	TxHash       string `json:"txHash,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+// WithdrawCrypto withdraws crypto funds to a whitelisted address.
+//
+// Withdrawals are not idempotent, so they are sent exactly once and never retried. Every
+// withdrawal carries a client transfer ID; one is generated and set on req when it is empty.
+// If the request fails after it may have reached Gemini (a timeout, a dropped connection or
+// an unexpected HTTP status), ErrOutcomeUnknown is returned: look for req.ClientTransferID in
+// GetTransfers before withdrawing again, or the funds may be sent twice. Failures before the
+// request was sent, such as a cancelled context or ErrRateLimit, keep their own code.
+// This implements the private API: https://docs.gemini.com/rest/fund-management#withdraw-crypto-funds
+func (f *FundAPI) WithdrawCrypto(ctx context.Context, currency string, req *WithdrawCryptoRequest) (*WithdrawCryptoResponse, error) {
+	response, err := f.withdrawCrypto(ctx, currency, req)
+	f.gemini.audit("WithdrawCrypto", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"currency":           currency,
+			"address":            req.Address,
+			"amount":             req.Amount,
+			"memo":               req.Memo,
+			"client_transfer_id": req.ClientTransferID,
+			"account":            req.Account,
+		}
+	}, response, err)
+	return response, err
+}
+
+// withdrawCrypto withdraws crypto funds without auditing
+func (f *FundAPI) withdrawCrypto(ctx context.Context, currency string, req *WithdrawCryptoRequest) (*WithdrawCryptoResponse, error) {
+	if req == nil || req.Address == "" || req.Amount == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "withdrawal address and amount are required")
+	}
+	if !req.ConfirmProduction && f.gemini.withdrawalNeedsConfirmation() {
+		return nil, errors.New(errors.ErrPermissionDenied, "production withdrawal requires explicit confirmation").
+			WithDetails("set ConfirmProduction on the request or disable the guard with SetWithdrawalGuard(false)")
+	}
+	if req.ClientTransferID == "" {
+		id, err := newClientTransferID()
+		if err != nil {
+			return nil, err
+		}
+		req.ClientTransferID = id
 	}
 
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(request)
+	f.gemini.logger.Debug().Str("currency", currency).Str("amount", req.Amount).Str("account", req.Account).Str("client_transfer_id", req.ClientTransferID).Msg("Withdrawing crypto funds")
+
+	// This is sent once and never retried
+	var withdrawal WithdrawCryptoResponse
+	err := f.gemini.doSigned(ctx, signedCall{
+		endpoint: fmt.Sprintf("/v1/withdraw/%s", currency),
+		payload:  req,
+		requestError: func(err error) error {
+			return transferRequestError("withdrawal", "failed to withdraw crypto funds", req.ClientTransferID, err)
+		},
+	}, &withdrawal)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal request payload", err)
+		return nil, err
+	}
+
+	f.gemini.logger.Debug().Str("withdrawal_id", withdrawal.WithdrawalID).Str("currency", currency).Msg("Successfully withdrew crypto funds")
+	return &withdrawal, nil
+}
+
+// newClientTransferID generates a random UUID v4 to identify a withdrawal or transfer
+func newClientTransferID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", errors.Wrap(errors.ErrUnknown, "failed to generate client transfer ID", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// transferRequestError converts a failed withdrawal or transfer request into an error.
+// Requests that failed before they were sent, because the client was shut down, the context
+// was done or the local rate limiter had no token, keep their own error. Requests answered
+// with a 4xx status were rejected by Gemini; any other failure may have happened after Gemini
+// executed the request, so it is reported as ErrOutcomeUnknown.
+func transferRequestError(operation, message, clientTransferID string, err error) error {
+	if client.RequestNotSent(err) {
+		return err
+	}
+	var statusErr *client.StatusError
+	if stderrors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 {
+		return requestError(message, err)
+	}
+	return errors.Wrap(errors.ErrOutcomeUnknown, message, err).
+		WithDetailsf("the %s may have been executed; look for client transfer ID %s in GetTransfers before sending it again", operation, clientTransferID)
+}
+
+// GetTransfersRequest represents the request payload for listing transfers
+type GetTransfersRequest struct {
+	Request                      string `json:"request"`
+	Nonce                        string `json:"nonce"`
+	Currency                     string `json:"currency,omitempty"`
+	Timestamp                    int64  `json:"timestamp,omitempty"`       // only transfers on or after this time, in seconds
+	LimitTransfers               int    `json:"limit_transfers,omitempty"` // up to 50, Gemini's default is 10
+	Account                      string `json:"account,omitempty"`
+	ShowCompletedDepositAdvances bool   `json:"show_completed_deposit_advances,omitempty"`
+}
+
+// Transfer represents a deposit, withdrawal or transfer between accounts
+type Transfer struct {
+	Type             string  `json:"type"`   // "Deposit", "Withdrawal", ...
+	Status           string  `json:"status"` // "Advanced", "Complete", ...
+	TimestampMs      FlexInt `json:"timestampms"`
+	EID              FlexInt `json:"eid"`
+	AdvanceEID       FlexInt `json:"advanceEid,omitempty"`
+	Currency         string  `json:"currency"`
+	Amount           string  `json:"amount"`
+	FeeAmount        string  `json:"feeAmount,omitempty"`
+	FeeCurrency      string  `json:"feeCurrency,omitempty"`
+	Method           string  `json:"method,omitempty"`
+	TxHash           string  `json:"txHash,omitempty"`
+	OutputIdx        FlexInt `json:"outputIdx,omitempty"`
+	Destination      string  `json:"destination,omitempty"`
+	Purpose          string  `json:"purpose,omitempty"`
+	ClientTransferID string  `json:"clientTransferId,omitempty"`
+	WithdrawalID     string  `json:"withdrawalId,omitempty"`
+}
+
+// GetTransfers lists the most recent deposits, withdrawals and transfers of an account, newest
+// first. After ErrOutcomeUnknown from WithdrawCrypto or InternalTransfer, look for the client
+// transfer ID here before sending the request again. req may be nil to use Gemini's defaults.
+// This implements the private API: https://docs.gemini.com/rest/fund-management#list-past-transfers
+func (f *FundAPI) GetTransfers(ctx context.Context, req *GetTransfersRequest) ([]Transfer, error) {
+	transfers, err := f.getTransfers(ctx, req)
+	f.gemini.audit("GetTransfers", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"currency":        req.Currency,
+			"timestamp":       req.Timestamp,
+			"limit_transfers": req.LimitTransfers,
+			"account":         req.Account,
+		}
+	}, transfers, err)
+	return transfers, err
+}
+
+// getTransfers lists transfers without auditing
+func (f *FundAPI) getTransfers(ctx context.Context, req *GetTransfersRequest) ([]Transfer, error) {
+	if req == nil {
+		req = &GetTransfersRequest{}
+	}
+	var transfers []Transfer
+	if err := f.gemini.doSignedPost(ctx, "/v1/transfers", req, &transfers); err != nil {
+		return nil, err
 	}
 
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
+	f.gemini.logger.Debug().Int("count", len(transfers)).Msg("Successfully fetched transfers")
+	return transfers, nil
+}
 
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(f.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
+// WithdrawalFeeEstimateRequest represents the request payload for a withdrawal fee estimate
+type WithdrawalFeeEstimateRequest struct {
+	Request string `json:"request"`
+	Nonce   string `json:"nonce"`
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Account string `json:"account,omitempty"`
+}
+
+// WithdrawalFee is the network fee charged for a withdrawal
+type WithdrawalFee struct {
+	Currency string `json:"currency"`
+	Value    string `json:"value"`
+}
+
+// withdrawalFeeStringPattern matches the fee as a string, such as "{currency: 'ETH', value: '0'}"
+var withdrawalFeeStringPattern = regexp.MustCompile(`currency:\s*'([^']*)'\s*,\s*value:\s*'([^']*)'`)
 
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    f.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
+// UnmarshalJSON accepts the fee either as an object or in the quoted form used by the API docs
+func (w *WithdrawalFee) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		type plain WithdrawalFee
+		return json.Unmarshal(data, (*plain)(w))
 	}
+	match := withdrawalFeeStringPattern.FindStringSubmatch(text)
+	if match == nil {
+		return fmt.Errorf("unrecognized withdrawal fee: %q", text)
+	}
+	w.Currency, w.Value = match[1], match[2]
+	return nil
+}
 
-	f.gemini.logger.Debug().Str("url", url).Str("network", network).Str("account", account).Msg("Listing deposit addresses")
+// WithdrawalFeeEstimate represents the estimated fee for a withdrawal
+type WithdrawalFeeEstimate struct {
+	Currency         string        `json:"currency"`
+	Fee              WithdrawalFee `json:"fee"`
+	IsOverride       bool          `json:"isOverride"`
+	MonthlyLimit     int           `json:"monthlyLimit"`
+	MonthlyRemaining int           `json:"monthlyRemaining"`
+
+	// NetAmount is the requested amount minus the fee. It is only set when the fee is
+	// charged in the withdrawn currency.
+	NetAmount string `json:"-"`
+}
 
-	// Make POST request with authentication headers
-	response, err := f.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
+// EstimateWithdrawalFee previews the network fee of a crypto withdrawal of req.Amount to
+// req.Address, so it can be shown before the withdrawal is confirmed
+// This implements the private API: https://docs.gemini.com/rest/fund-management#get-transfer-fee-estimate
+func (f *FundAPI) EstimateWithdrawalFee(ctx context.Context, currency string, req *WithdrawalFeeEstimateRequest) (*WithdrawalFeeEstimate, error) {
+	estimate, err := f.estimateWithdrawalFee(ctx, currency, req)
+	f.gemini.audit("EstimateWithdrawalFee", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"currency": currency,
+			"address":  req.Address,
+			"amount":   req.Amount,
+			"account":  req.Account,
+		}
+	}, estimate, err)
+	return estimate, err
+}
+
+// estimateWithdrawalFee fetches a withdrawal fee estimate without auditing
+func (f *FundAPI) estimateWithdrawalFee(ctx context.Context, currency string, req *WithdrawalFeeEstimateRequest) (*WithdrawalFeeEstimate, error) {
+	if currency == "" || req == nil || req.Address == "" || req.Amount == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "currency, withdrawal address and amount are required")
+	}
+	amount, err := decimal.NewFromString(req.Amount)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to list deposit addresses", err)
+		return nil, errors.Wrap(errors.ErrInvalidInput, "invalid withdrawal amount", err)
 	}
 
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
+	endpoint := fmt.Sprintf("/v1/withdraw/%s/feeEstimate", strings.ToLower(currency))
+	var estimate WithdrawalFeeEstimate
+	if err := f.gemini.doSignedPost(ctx, endpoint, req, &estimate); err != nil {
+		return nil, err
 	}
 
-	var addresses []DepositAddress
-	if err := json.Unmarshal(response, &addresses); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse deposit addresses response", err)
+	if strings.EqualFold(estimate.Fee.Currency, currency) {
+		if fee, err := decimal.NewFromString(estimate.Fee.Value); err == nil {
+			estimate.NetAmount = amount.Sub(fee).String()
+		}
 	}
 
-	f.gemini.logger.Debug().Int("count", len(addresses)).Str("network", network).Msg("Successfully listed deposit addresses")
-	return addresses, nil
+	f.gemini.logger.Debug().Str("currency", currency).Str("fee", estimate.Fee.Value).Str("net_amount", estimate.NetAmount).Msg("Successfully estimated withdrawal fee")
+	return &estimate, nil
+}
+
+// InternalTransferRequest represents the request payload for a transfer between accounts
+type InternalTransferRequest struct {
+	Request          string `json:"request"`
+	Nonce            string `json:"nonce"`
+	SourceAccount    string `json:"sourceAccount"`
+	TargetAccount    string `json:"targetAccount"`
+	Amount           string `json:"amount"`
+	ClientTransferID string `json:"clientTransferId,omitempty"`
+}
+
+// TransferResult represents the result of a transfer between accounts
+type TransferResult struct {
+	FromAccount  string  `json:"fromAccount"`
+	ToAccount    string  `json:"toAccount"`
+	Amount       string  `json:"amount"`
+	Fee          string  `json:"fee"`
+	Currency     string  `json:"currency"`
+	WithdrawalID FlexInt `json:"withdrawalId"`
+	UUID         string  `json:"uuid"`
+	Message      string  `json:"message"`
+	TxHash       string  `json:"txHash,omitempty"`
+}
+
+// InternalTransfer moves funds between two accounts of the same master group, for example
+// from a trading account to a custody account. Requires a master API key.
+//
+// Like WithdrawCrypto, transfers are sent exactly once with a client transfer ID, generated
+// when req.ClientTransferID is empty, and ErrOutcomeUnknown means GetTransfers must be
+// checked for that ID before transferring again.
+// This implements the private API: https://docs.gemini.com/rest/fund-management#transfer-between-accounts
+func (f *FundAPI) InternalTransfer(ctx context.Context, currency string, req *InternalTransferRequest) (*TransferResult, error) {
+	result, err := f.internalTransfer(ctx, currency, req)
+	f.gemini.audit("InternalTransfer", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"currency":           currency,
+			"source_account":     req.SourceAccount,
+			"target_account":     req.TargetAccount,
+			"amount":             req.Amount,
+			"client_transfer_id": req.ClientTransferID,
+		}
+	}, result, err)
+	return result, err
+}
+
+// internalTransfer moves funds between accounts without auditing
+func (f *FundAPI) internalTransfer(ctx context.Context, currency string, req *InternalTransferRequest) (*TransferResult, error) {
+	if currency == "" || req == nil {
+		return nil, errors.New(errors.ErrInvalidInput, "currency and transfer request are required")
+	}
+	if req.SourceAccount == "" || req.TargetAccount == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "source and target accounts are required")
+	}
+	if req.SourceAccount == req.TargetAccount {
+		return nil, errors.Newf(errors.ErrInvalidInput, "source and target accounts must differ, both are %q", req.SourceAccount)
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		return nil, errors.Newf(errors.ErrInvalidInput, "transfer amount must be positive, got %q", req.Amount)
+	}
+	if req.ClientTransferID == "" {
+		id, err := newClientTransferID()
+		if err != nil {
+			return nil, err
+		}
+		req.ClientTransferID = id
+	}
+
+	f.gemini.logger.Debug().Str("currency", currency).Str("amount", req.Amount).Str("source", req.SourceAccount).Str("target", req.TargetAccount).Str("client_transfer_id", req.ClientTransferID).Msg("Transferring between accounts")
+
+	// The accounts are part of the request, so the default account is not injected. This is
+	// sent once and never retried.
+	var result TransferResult
+	err = f.gemini.doSigned(ctx, signedCall{
+		endpoint:              fmt.Sprintf("/v1/account/transfer/%s", strings.ToLower(currency)),
+		payload:               req,
+		withoutDefaultAccount: true,
+		requestError: func(err error) error {
+			return transferRequestError("transfer", "failed to transfer between accounts", req.ClientTransferID, err)
+		},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	f.gemini.logger.Debug().Str("uuid", result.UUID).Str("currency", currency).Msg("Successfully transferred between accounts")
+	return &result, nil
+}
+
+// NewDepositAddressRequest represents the request payload for creating a deposit address
+type NewDepositAddressRequest struct {
+	Request string `json:"request"`
+	Nonce   string `json:"nonce"`
+	Label   string `json:"label,omitempty"`
+	// Legacy requests a legacy (P2SH) address instead of a native SegWit (bech32) one,
+	// for counterparties that cannot send to bech32 addresses
+	Legacy  bool   `json:"legacy,omitempty"`
+	Account string `json:"account,omitempty"`
+}
+
+// CreateDepositAddress creates a new deposit address on the specified network. A nil request
+// creates an unlabeled address in the default format.
+// This implements the private API: https://docs.gemini.com/rest/fund-management#new-deposit-address
+func (f *FundAPI) CreateDepositAddress(ctx context.Context, network string, req *NewDepositAddressRequest) (*DepositAddress, error) {
+	address, err := f.createDepositAddress(ctx, network, req)
+	f.gemini.audit("CreateDepositAddress", func() map[string]interface{} {
+		params := map[string]interface{}{"network": network}
+		if req != nil {
+			params["label"] = req.Label
+			params["legacy"] = req.Legacy
+			params["account"] = req.Account
+		}
+		return params
+	}, address, err)
+	return address, err
+}
+
+// createDepositAddress creates a deposit address without auditing
+func (f *FundAPI) createDepositAddress(ctx context.Context, network string, req *NewDepositAddressRequest) (*DepositAddress, error) {
+	if network == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "network is required")
+	}
+	if req == nil {
+		req = &NewDepositAddressRequest{}
+	}
+
+	endpoint := fmt.Sprintf("/v1/deposit/%s/newAddress", strings.ToLower(network))
+	var address DepositAddress
+	if err := f.gemini.doSignedPost(ctx, endpoint, req, &address); err != nil {
+		return nil, err
+	}
+	if address.Network == "" {
+		address.Network = network
+	}
+	address.Format = addressFormat(address.Network, address.Address)
+
+	f.gemini.logger.Debug().Str("network", network).Str("format", string(address.Format)).Msg("Successfully created deposit address")
+	return &address, nil
 }
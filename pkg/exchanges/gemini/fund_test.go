@@ -2,22 +2,29 @@ package gemini
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/testutil"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestFundAPI_GetAvailableBalances(t *testing.T) {
-	// Skip test if API credentials are not provided
+	// Recording needs real sandbox credentials; replaying recorded responses does not
 	apiKey := os.Getenv("GEMINI_API_KEY")
 	apiSecret := os.Getenv("GEMINI_API_SECRET")
-	if apiKey == "" || apiSecret == "" {
-		t.Skip("Skipping test: GEMINI_API_KEY and GEMINI_API_SECRET environment variables are required")
+	if testutil.Recording() && (apiKey == "" || apiSecret == "") {
+		t.Skip("Skipping test: GEMINI_API_KEY and GEMINI_API_SECRET environment variables are required to record")
+	}
+	if !testutil.Recording() {
+		apiKey, apiSecret = "fixture-key", "fixture-secret"
 	}
 
 	// Create a test configuration with API credentials
@@ -29,8 +36,8 @@ func TestFundAPI_GetAvailableBalances(t *testing.T) {
 		Logger:    &zerolog.Logger{},
 	}
 
-	// Create Gemini instance
-	gemini := NewGemini(config)
+	// Create Gemini instance serving recorded sandbox responses
+	gemini := newFixtureGemini(t, config)
 	require.NotNil(t, gemini)
 	require.NotNil(t, gemini.Fund)
 
@@ -77,6 +84,55 @@ func TestFundAPI_GetAvailableBalances_NoCredentials(t *testing.T) {
 	assert.Contains(t, err.Error(), "API key and secret are required", "Error should mention missing credentials")
 }
 
+func TestFundAPI_GetAllAccountBalances_NoCredentials(t *testing.T) {
+	gemini := NewGemini(&exchange.Config{Testnet: true})
+	require.NotNil(t, gemini.Fund)
+
+	accounts := []string{"primary", "sub-1"}
+	results, errs := gemini.Fund.GetAllAccountBalances(context.Background(), accounts)
+
+	// Every account should fail independently and be reported by name
+	assert.Empty(t, results)
+	require.Len(t, errs, len(accounts))
+	for _, account := range accounts {
+		assert.Contains(t, errs[account].Error(), "API key and secret are required")
+	}
+}
+
+const balancesFixture = `[
+	{"type":"exchange","currency":"BTC","amount":"1.25","available":"1.0","availableForWithdrawal":"0.75"},
+	{"type":"exchange","currency":"USD","amount":"0.00","available":"0.00","availableForWithdrawal":"0.00"},
+	{"type":"exchange","currency":"ETH","amount":"bad","available":"","availableForWithdrawal":"0"}
+]`
+
+func TestBalance_Amounts(t *testing.T) {
+	var balances []Balance
+	require.NoError(t, json.Unmarshal([]byte(balancesFixture), &balances))
+	require.Len(t, balances, 3)
+
+	btc := balances[0]
+	amount, err := btc.AmountFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 1.25, amount)
+	available, err := btc.AvailableFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, available)
+	withdrawable, err := btc.AvailableForWithdrawalFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 0.75, withdrawable)
+
+	_, err = balances[2].AmountFloat()
+	assert.Error(t, err)
+
+	nonZero := make([]string, 0)
+	for _, balance := range balances {
+		if balance.NonZero() {
+			nonZero = append(nonZero, balance.Currency)
+		}
+	}
+	assert.Equal(t, []string{"BTC"}, nonZero)
+}
+
 func TestFundAPI_GetNotionalBalances(t *testing.T) {
 	// Skip test if API credentials are not provided
 	apiKey := os.Getenv("GEMINI_API_KEY")
@@ -118,6 +174,22 @@ func TestFundAPI_GetNotionalBalances(t *testing.T) {
 	}
 }
 
+func TestFundAPI_GetCurrencyNetworks(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/network/usdc", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+		_, _ = w.Write([]byte(`{"token":"USDC","network":["ethereum","solana","avalanche"]}`))
+	})
+
+	networks, err := g.Fund.GetCurrencyNetworks(context.Background(), "USDC")
+	require.NoError(t, err)
+	assert.Equal(t, "USDC", networks.Token)
+	assert.Equal(t, []string{"ethereum", "solana", "avalanche"}, networks.Networks)
+
+	_, err = g.Fund.GetCurrencyNetworks(context.Background(), "")
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
+
 func TestFundAPI_ListDepositAddresses(t *testing.T) {
 	// Skip test if API credentials are not provided
 	apiKey := os.Getenv("GEMINI_API_KEY")
@@ -163,6 +235,250 @@ func TestFundAPI_ListDepositAddresses(t *testing.T) {
 		// Basic structure validation
 		assert.NotEmpty(t, address.Address, "Address should not be empty")
 		assert.NotEmpty(t, address.Network, "Network should not be empty")
-		assert.Greater(t, address.Timestamp, int64(0), "Timestamp should be positive")
+		assert.Greater(t, int64(address.Timestamp), int64(0), "Timestamp should be positive")
+	}
+}
+
+func TestFundAPI_ListAllDepositAddresses(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/addresses/bitcoin":
+			_, _ = w.Write([]byte(`[{"address":"bc1qexample","timestamp":1700000000000,"network":"bitcoin"}]`))
+		case "/v1/addresses/ethereum":
+			_, _ = w.Write([]byte(`[{"address":"0xexample","timestamp":1700000000000,"network":"ethereum"}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidNetwork","message":"Invalid network"}`))
+		}
+	})
+
+	addresses, err := g.Fund.ListAllDepositAddresses(context.Background(), []string{"bitcoin", "ethereum", "dogecoin"}, "")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrAPIError, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "dogecoin")
+
+	require.Len(t, addresses, 2)
+	assert.Equal(t, "bc1qexample", addresses["bitcoin"][0].Address)
+	assert.Equal(t, "0xexample", addresses["ethereum"][0].Address)
+
+	// Once ctx is done no further requests are sent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	addresses, err = g.Fund.ListAllDepositAddresses(ctx, []string{"bitcoin", "ethereum"}, "")
+	require.Error(t, err)
+	assert.Empty(t, addresses)
+	assert.Contains(t, err.Error(), "2 of 2 networks")
+}
+
+func TestFundAPI_WithdrawCrypto_ProductionGuard(t *testing.T) {
+	// Production instance with dummy credentials
+	gemini := NewGemini(&exchange.Config{APIKey: "test-key", SecretKey: "test-secret"})
+	require.NotNil(t, gemini.Fund)
+
+	req := &WithdrawCryptoRequest{Address: "bc1qexample", Amount: "0.01"}
+	resp, err := gemini.Fund.WithdrawCrypto(context.Background(), "btc", req)
+
+	// The guard should refuse before any request is sent
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, errors.ErrPermissionDenied, errors.GetCode(err))
+
+	// The guard follows the active base URL, not the sandbox flag
+	sandbox := NewGemini(&exchange.Config{APIKey: "test-key", SecretKey: "test-secret", Testnet: true, BaseURL: "https://gateway.example.com"})
+	assert.True(t, sandbox.withdrawalNeedsConfirmation())
+	sandbox.SetSandbox(true)
+	assert.False(t, sandbox.withdrawalNeedsConfirmation())
+	gemini.SetWithdrawalGuard(false)
+	assert.False(t, gemini.withdrawalNeedsConfirmation())
+}
+
+func TestFundAPI_WithdrawCrypto_OutcomeUnknown(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payloads = append(payloads, decodeTestPayload(t, r))
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := &WithdrawCryptoRequest{Address: "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh", Amount: "0.1", ConfirmProduction: true}
+	_, err := g.Fund.WithdrawCrypto(context.Background(), "btc", req)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrOutcomeUnknown, errors.GetCode(err))
+	assert.Contains(t, err.Error(), req.ClientTransferID)
+
+	// Sent exactly once, with a generated UUID v4 client transfer ID
+	require.Len(t, payloads, 1)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, req.ClientTransferID)
+	assert.Equal(t, req.ClientTransferID, payloads[0]["client_transfer_id"])
+
+	transfer := &InternalTransferRequest{SourceAccount: "trading", TargetAccount: "custody", Amount: "0.5", ClientTransferID: "rebalance-2"}
+	_, err = g.Fund.InternalTransfer(context.Background(), "btc", transfer)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrOutcomeUnknown, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "rebalance-2")
+	assert.Len(t, payloads, 2)
+}
+
+func TestFundAPI_WithdrawCrypto_NotSent(t *testing.T) {
+	var payloads int
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payloads++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	// A done context fails before sending and keeps its timeout code
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := &WithdrawCryptoRequest{Address: "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh", Amount: "0.1", ConfirmProduction: true}
+	_, err := g.Fund.WithdrawCrypto(ctx, "btc", req)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrTimeout, errors.GetCode(err))
+
+	// So does a request after shutdown
+	require.NoError(t, g.Shutdown(context.Background()))
+	transfer := &InternalTransferRequest{SourceAccount: "trading", TargetAccount: "custody", Amount: "0.5"}
+	_, err = g.Fund.InternalTransfer(context.Background(), "btc", transfer)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrExchangeUnavailable, errors.GetCode(err))
+	assert.Zero(t, payloads)
+}
+
+func TestFundAPI_GetTransfers(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/transfers", r.URL.Path)
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`[{"type":"Withdrawal","status":"Complete","timestampms":1507240000000,"eid":320033681,"currency":"BTC",` +
+			`"amount":"0.1","txHash":"abc","destination":"bc1qexample","clientTransferId":"rebalance-2","withdrawalId":"02176a83-a6b1-4202-9b85-1c1c92dd25c4"}]`))
+	})
+
+	req := &GetTransfersRequest{Currency: "BTC", LimitTransfers: 50}
+	transfers, err := g.Fund.GetTransfers(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, "rebalance-2", transfers[0].ClientTransferID)
+	assert.Equal(t, "Withdrawal", transfers[0].Type)
+	assert.EqualValues(t, 320033681, transfers[0].EID)
+	assert.Equal(t, "/v1/transfers", payload["request"])
+	assert.Equal(t, "BTC", payload["currency"])
+	assert.EqualValues(t, 50, payload["limit_transfers"])
+	assert.Empty(t, req.Nonce)
+
+	_, err = g.Fund.GetTransfers(context.Background(), nil)
+	require.NoError(t, err)
+}
+
+func TestFundAPI_WithdrawCrypto_Rejected(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidSignature","message":"InvalidSignature"}`))
+	})
+
+	req := &WithdrawCryptoRequest{Address: "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh", Amount: "0.1", ConfirmProduction: true}
+	_, err := g.Fund.WithdrawCrypto(context.Background(), "btc", req)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrInvalidSignature, errors.GetCode(err))
+}
+
+func TestFundAPI_EstimateWithdrawalFee(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/withdraw/eth/feeEstimate", r.URL.Path)
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`{"currency":"ETH","fee":{"currency":"ETH","value":"0.0015"},"isOverride":false,"monthlyLimit":10,"monthlyRemaining":9}`))
+	})
+
+	estimate, err := g.Fund.EstimateWithdrawalFee(context.Background(), "ETH", &WithdrawalFeeEstimateRequest{Address: "0xabc", Amount: "1.5"})
+	require.NoError(t, err)
+	assert.Equal(t, "0.0015", estimate.Fee.Value)
+	assert.Equal(t, "1.4985", estimate.NetAmount)
+	assert.Equal(t, 9, estimate.MonthlyRemaining)
+	assert.Equal(t, "0xabc", payload["address"])
+	assert.Equal(t, "1.5", payload["amount"])
+
+	_, err = g.Fund.EstimateWithdrawalFee(context.Background(), "ETH", &WithdrawalFeeEstimateRequest{Amount: "1"})
+	assert.Error(t, err)
+}
+
+func TestWithdrawalFee_UnmarshalJSON(t *testing.T) {
+	// The API docs show the fee as a quoted, JavaScript style object
+	var estimate WithdrawalFeeEstimate
+	require.NoError(t, json.Unmarshal([]byte(`{"currency":"ETH","fee":"{currency: 'ETH', value: '0'}","isOverride":false,"monthlyLimit":1,"monthlyRemaining":1}`), &estimate))
+	assert.Equal(t, WithdrawalFee{Currency: "ETH", Value: "0"}, estimate.Fee)
+
+	var fee WithdrawalFee
+	assert.Error(t, json.Unmarshal([]byte(`"free"`), &fee))
+}
+
+func TestFundAPI_InternalTransfer(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/account/transfer/btc", r.URL.Path)
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`{"fromAccount":"trading","toAccount":"custody","amount":"0.5","fee":"0","currency":"Bitcoin","withdrawalId":47,"uuid":"a9ecf4d5-9c57-4b28-8a12-33c6f7b1c2a5","message":"Success, transfer completed."}`))
+	})
+	g.SetDefaultAccount("other")
+
+	result, err := g.Fund.InternalTransfer(context.Background(), "BTC", &InternalTransferRequest{
+		SourceAccount:    "trading",
+		TargetAccount:    "custody",
+		Amount:           "0.5",
+		ClientTransferID: "rebalance-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, FlexInt(47), result.WithdrawalID)
+	assert.Equal(t, "custody", result.ToAccount)
+	assert.Equal(t, "trading", payload["sourceAccount"])
+	assert.Equal(t, "custody", payload["targetAccount"])
+	assert.Equal(t, "rebalance-1", payload["clientTransferId"])
+	assert.NotContains(t, payload, "account")
+
+	invalid := []*InternalTransferRequest{
+		{TargetAccount: "custody", Amount: "1"},
+		{SourceAccount: "trading", Amount: "1"},
+		{SourceAccount: "trading", TargetAccount: "trading", Amount: "1"},
+		{SourceAccount: "trading", TargetAccount: "custody", Amount: "0"},
+		{SourceAccount: "trading", TargetAccount: "custody", Amount: "abc"},
+	}
+	for _, req := range invalid {
+		_, err := g.Fund.InternalTransfer(context.Background(), "BTC", req)
+		assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err), "expected invalid input for %+v", req)
+	}
+}
+
+func TestFundAPI_CreateDepositAddress(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/deposit/bitcoin/newAddress", r.URL.Path)
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`{"network":"bitcoin","address":"3BRnbJq2jRHL5ebyxbLHFmPYa6ehk9C8bk","label":"exchange"}`))
+	})
+
+	address, err := g.Fund.CreateDepositAddress(context.Background(), "bitcoin", &NewDepositAddressRequest{Label: "exchange", Legacy: true})
+	require.NoError(t, err)
+	assert.Equal(t, AddressFormatP2SH, address.Format)
+	assert.Equal(t, true, payload["legacy"])
+	assert.Equal(t, "exchange", payload["label"])
+
+	_, err = g.Fund.CreateDepositAddress(context.Background(), "bitcoin", nil)
+	require.NoError(t, err)
+	assert.NotContains(t, payload, "legacy")
+}
+
+func TestAddressFormat(t *testing.T) {
+	tests := []struct {
+		network  string
+		address  string
+		expected AddressFormat
+	}{
+		{"bitcoin", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", AddressFormatBech32},
+		{"bitcoin", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", AddressFormatP2SH},
+		{"bitcoin", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", AddressFormatP2PKH},
+		{"litecoin", "ltc1qg82wvztxv9xlj3jx6l3qgqsmwmv3jy5z2fnpy3", AddressFormatBech32},
+		{"litecoin", "MQMcJhpWHYVeQArcZR3sBgyPZxxRtnH441", AddressFormatP2SH},
+		{"ethereum", "0x32Be343B94f860124dC4fEe278FDCBD38C102D88", AddressFormatUnknown},
+		{"bitcoin", "", AddressFormatUnknown},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, addressFormat(test.network, test.address), test.address)
 	}
 }
@@ -2,12 +2,14 @@ package gemini
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/backoff"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
@@ -22,30 +24,77 @@ const (
 	exchangeName = "gemini"
 	// API response status
 	errorStatus = "error"
+	// clockSkewWarnThreshold is the local clock skew above which a warning is logged
+	clockSkewWarnThreshold = 5 * time.Second
 )
 
 // Gemini represents the Gemini exchange
 type Gemini struct {
 	client    *client.HTTPClient
 	baseURL   string
-	apiKey    string
+	apiKey    string // active key, from prodCredentials or sandboxCredentials
 	apiSecret string
 	sandbox   bool
 	userAgent string
 	logger    zerolog.Logger
 
+	// prodCredentials and sandboxCredentials are the key pairs SetSandbox switches between.
+	// An empty sandbox pair falls back to the production pair, as in exchange.Config.Credentials.
+	prodCredentials    apiCredentials
+	sandboxCredentials apiCredentials
+
+	// withdrawalGuard requires production withdrawals to be explicitly confirmed
+	withdrawalGuard bool
+
+	// strictSymbols makes GetTradingPairs fail for symbols without details instead of guessing
+	strictSymbols bool
+	// symbolFilter limits the symbols GetTradingPairs returns; nil returns all
+	symbolFilter *symbolFilter
+
+	// reconnectBackoff computes the delay between stream reconnect attempts; nil uses full jitter
+	reconnectBackoff backoff.Strategy
+
+	failover  *baseURLFailover
+	symbols   *symbolCache
+	nonces    NonceSource
+	auditSink AuditSink
+	mu        sync.RWMutex
+
+	// defaultAccount is injected into signed payloads that do not name an account
+	defaultAccount string
+
+	// signer signs private requests in place of the API secret when set
+	signer Signer
+
+	// nonceJump is how far nonces skip ahead after Gemini rejects one; 0 disables skipping
+	nonceJump int64
+	// nonceFloor is the largest nonce handed out while skipping ahead
+	nonceFloor int64
+	// nonceRejected is set when Gemini rejects a nonce and cleared by the next nonce
+	nonceRejected bool
+
 	// API categories
-	Market *MarketAPI
-	Order  *OrderAPI
-	Fund   *FundAPI
+	Market  *MarketAPI
+	Order   *OrderAPI
+	Fund    *FundAPI
+	Account *AccountAPI
 }
 
-// NewGemini creates a new Gemini exchange instance
+// NewGemini creates a new Gemini exchange instance.
+// The base URL is config.BaseURL when set, for example a mock server, regional endpoint or
+// API gateway. Otherwise Testnet or its alias Sandbox selects the sandbox API over production.
+// In sandbox mode, config.SandboxAPIKey and SandboxSecretKey take precedence over the
+// production credentials; see exchange.Config.Credentials.
+// NewGemini does not validate config; exchange.Factory and ValidateConfig do.
 func NewGemini(config *exchange.Config) *Gemini {
+	sandbox := config != nil && config.IsSandbox()
 	baseURL := baseURLProd
-	if config != nil && config.Testnet {
+	if sandbox {
 		baseURL = baseURLSandbox
 	}
+	if config != nil && config.BaseURL != "" {
+		baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	}
 
 	timeout := 30 * time.Second
 	if config != nil && config.Timeout > 0 {
@@ -57,12 +106,18 @@ func NewGemini(config *exchange.Config) *Gemini {
 		baseURL:   baseURL,
 		userAgent: "CEX-SDK/1.0",
 		logger:    zerolog.Nop(), // Default no-op logger
+
+		withdrawalGuard: true,
+		symbols:         newSymbolCache(symbolCacheTTL),
+		nonces:          managerNonceSource{manager: NewTimeNonceManager()},
 	}
 
 	if config != nil {
-		g.apiKey = config.APIKey
-		g.apiSecret = config.SecretKey
-		g.sandbox = config.Testnet
+		g.prodCredentials = apiCredentials{key: config.APIKey, secret: config.SecretKey}
+		g.sandboxCredentials = apiCredentials{key: config.SandboxAPIKey, secret: config.SandboxSecretKey}
+		g.apiKey, g.apiSecret = config.Credentials()
+		g.sandbox = sandbox
+		g.defaultAccount = config.DefaultAccount
 		// UserAgent can be set via headers
 
 		// Set custom logger if provided
@@ -99,9 +154,13 @@ func NewGemini(config *exchange.Config) *Gemini {
 	// Initialize API categories
 	g.Market = NewMarketAPI(g)
 	g.Order = NewOrderAPI(g)
+	if config != nil && config.RateLimit.Private.Weight > 0 {
+		g.Order.SetOrderWeight(config.RateLimit.Private.Weight)
+	}
 	g.Fund = NewFundAPI(g)
+	g.Account = NewAccountAPI(g)
 
-	g.logger.Info().Str("baseURL", g.baseURL).Msg("Gemini exchange initialized")
+	g.logger.Info().Str("baseURL", g.getBaseURL()).Msg("Gemini exchange initialized")
 	return g
 }
 
@@ -110,44 +169,60 @@ func (g *Gemini) GetName() string {
 	return exchangeName
 }
 
-// GetTradingPairs fetches all available trading pairs from Gemini
-func (g *Gemini) GetTradingPairs(ctx context.Context) ([]exchange.TradingPair, error) {
-	symbolsURL := fmt.Sprintf("%s/v1/symbols", g.baseURL)
+// Capabilities reports the features implemented for Gemini
+func (g *Gemini) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Spot:        true,
+		Margin:      false,
+		Derivatives: false,
+		Staking:     false,
+		WebSocket:   true,
+		Deposits:    true,
+		Withdrawals: true,
+		SubAccounts: true,
+	}
+}
 
+// GetTradingPairs fetches all available trading pairs from Gemini.
+// Symbol lists and details are shared with the Market API through a short-lived cache.
+// A symbol missing from the details endpoint gets a pair with guessed assets and no limits,
+// and a warning lists those symbols; with SetStrictSymbols it fails with ErrInvalidResponse.
+// Only symbols passing the filter set with SetSymbolFilter are returned.
+func (g *Gemini) GetTradingPairs(ctx context.Context) ([]exchange.TradingPair, error) {
 	// Fetch symbols
-	response, err := g.client.Get(ctx, symbolsURL)
+	symbols, err := g.Market.cachedSymbols(ctx)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch symbols", err)
+		return nil, err
 	}
 
-	var symbols []string
-	if err := json.Unmarshal(response, &symbols); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse symbols response", err)
-	}
-
-	// Get detailed symbol information
-	detailsURL := fmt.Sprintf("%s/v1/symbols/details", g.baseURL)
-	detailsResp, err := g.client.Get(ctx, detailsURL)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch symbol details", err)
-	}
+	g.mu.RLock()
+	filter := g.symbolFilter
+	g.mu.RUnlock()
 
-	var symbolDetails []Symbol
-	if err := json.Unmarshal(detailsResp, &symbolDetails); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse symbol details", err)
+	// Get detailed symbol information unless every selected symbol is already cached
+	missing := false
+	for _, symbol := range symbols {
+		if _, ok := g.symbols.getDetails(symbol); !ok && filter.match(symbol) {
+			missing = true
+			break
+		}
 	}
-
-	// Create a map for quick lookup
-	detailsMap := make(map[string]Symbol)
-	for _, detail := range symbolDetails {
-		detailsMap[strings.ToLower(detail.Symbol)] = detail
+	if missing {
+		if _, err := g.Market.fetchSymbolDetailsList(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	// Fetch ticker data for each symbol
 	pairs := make([]exchange.TradingPair, 0, len(symbols))
+	var fallbacks []string
 	for _, symbol := range symbols {
-		detail, exists := detailsMap[strings.ToLower(symbol)]
+		if !filter.match(symbol) {
+			continue
+		}
+		detail, exists := g.symbols.getDetails(symbol)
 		if !exists {
+			fallbacks = append(fallbacks, symbol)
 			// If no details available, create basic pair info
 			pair := exchange.TradingPair{
 				Symbol:     strings.ToUpper(symbol),
@@ -163,28 +238,140 @@ func (g *Gemini) GetTradingPairs(ctx context.Context) ([]exchange.TradingPair, e
 			continue
 		}
 
-		minOrderSize, _ := parseFloatFromString(detail.MinOrderSize)
-
-		pair := exchange.TradingPair{
-			Symbol:     strings.ToUpper(detail.Symbol),
-			BaseAsset:  strings.ToUpper(detail.BaseCurrency),
-			QuoteAsset: strings.ToUpper(detail.QuoteCurrency),
-			Status:     detail.Status,
-			MinQty:     minOrderSize,
-			MaxQty:     0, // Gemini doesn't provide max order size in this endpoint
-			StepSize:   0,
-			TickSize:   detail.TickSize,
+		pairs = append(pairs, tradingPairFromDetails(detail))
+	}
+
+	if len(fallbacks) > 0 {
+		if g.StrictSymbols() {
+			return nil, errors.Newf(errors.ErrInvalidResponse, "symbol details missing for %d of %d symbols: %s",
+				len(fallbacks), len(symbols), strings.Join(fallbacks, ","))
 		}
-		pairs = append(pairs, pair)
+		g.logger.Warn().Strs("symbols", fallbacks).Int("total", len(symbols)).Msg("Symbol details missing, trading pairs guessed from symbol names")
 	}
 
 	return pairs, nil
 }
 
+// GetTradingPair fetches a single trading pair by symbol.
+// Details cached by a prior GetTradingPairs or GetSymbolDetails call are reused;
+// otherwise only the details for this symbol are fetched.
+func (g *Gemini) GetTradingPair(ctx context.Context, symbol string) (*exchange.TradingPair, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+
+	detail, ok := g.symbols.getDetails(symbol)
+	if !ok {
+		// A fresh symbol list lets unknown symbols fail without a request
+		if symbols, listed := g.symbols.getSymbols(); listed && !containsSymbol(symbols, symbol) {
+			return nil, errors.Newf(errors.ErrInvalidSymbol, "unknown symbol: %s", symbol)
+		}
+
+		details, err := g.Market.GetSymbolDetails(ctx, strings.ToLower(symbol))
+		if err != nil {
+			return nil, err
+		}
+		if details.Symbol == "" {
+			return nil, errors.Newf(errors.ErrInvalidSymbol, "unknown symbol: %s", symbol)
+		}
+		detail = *details
+	}
+
+	pair := tradingPairFromDetails(detail)
+	return &pair, nil
+}
+
+// PlaceOrder places an order described by exchange independent fields.
+// Limit orders are sent as exchange limit orders with the time in force as an execution option,
+// and market sells as market sell orders. Market buys, which Gemini sizes by quote currency spend,
+// and order types Gemini does not support return ErrInvalidOrderType.
+func (g *Gemini) PlaceOrder(ctx context.Context, req exchange.GenericOrderRequest) (*exchange.GenericOrder, error) {
+	geminiReq, err := toNewOrderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := g.Order.PlaceOrder(ctx, geminiReq)
+	if err != nil {
+		return nil, err
+	}
+	return toGenericOrder(order), nil
+}
+
+// GetOrderBook fetches up to depth price levels on each side of the book for symbol in
+// exchange independent form. A depth of 0 returns the full book. See MarketAPI.GetOrderBook
+// for Gemini's native book.
+func (g *Gemini) GetOrderBook(ctx context.Context, symbol string, depth int) (exchange.OrderBook, error) {
+	book, err := g.Market.GetOrderBook(ctx, symbol, depth)
+	if err != nil {
+		return exchange.OrderBook{}, err
+	}
+	return toGenericOrderBook(book), nil
+}
+
+// SubscribeTrades streams public trades for symbol in exchange independent form, from the
+// market data feed of SingleSymbolStream, which reconnects as needed. The channel is closed
+// when ctx is done. Trades are buffered, but a consumer that falls too far behind holds up
+// the feed, so read the channel promptly.
+func (g *Gemini) SubscribeTrades(ctx context.Context, symbol string) (<-chan exchange.Trade, error) {
+	stream, err := g.Market.SingleSymbolStream(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make(chan exchange.Trade, marketDataBufferSize)
+	go func() {
+		defer close(trades)
+		defer stream.Close()
+		for update := range stream.Updates() {
+			for _, trade := range toGenericTrades(symbol, update) {
+				select {
+				case trades <- trade:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return trades, nil
+}
+
+// GetTradingPairsFiltered fetches trading pairs that are in the given trading state
+func (g *Gemini) GetTradingPairsFiltered(ctx context.Context, status SymbolStatus) ([]exchange.TradingPair, error) {
+	pairs, err := g.GetTradingPairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterTradingPairsByStatus(pairs, status), nil
+}
+
 // SetRateLimit sets the rate limiting for the HTTP client
+// A private limit's Weight sets how many tokens each order placement consumes.
 func (g *Gemini) SetRateLimit(apiType exchange.APIType, limit exchange.RateLimit) {
 	g.client.SetRateLimit(client.APIType(apiType), limit.Requests, limit.Interval)
-	g.logger.Info().Str("apiType", string(apiType)).Int("requests", limit.Requests).Dur("interval", limit.Interval).Msg("Rate limit updated")
+	if apiType == exchange.APITypePrivate && limit.Weight > 0 {
+		g.Order.SetOrderWeight(limit.Weight)
+	}
+	g.logger.Info().Str("apiType", string(apiType)).Int("requests", limit.Requests).Dur("interval", limit.Interval).Int("weight", limit.Weight).Msg("Rate limit updated")
+}
+
+// SetSharedRateLimiter makes this instance draw from the given rate limiters instead of its own.
+//
+// Gemini enforces its limits per API key, so instances that share a key (for example one per
+// sub-account) should share limiters; otherwise each assumes it has the whole budget and
+// together they exceed it. Create the limiters once with client.NewRateLimiter and pass them to
+// every instance: each request from any of them then takes a token from the same bucket, and
+// rate limit headers seen by any instance update it for all. A nil limiter leaves the current
+// limiter for that API type unchanged. A later SetRateLimit replaces the shared limiter for
+// this instance only.
+func (g *Gemini) SetSharedRateLimiter(public, private *client.RateLimiter) {
+	if public != nil {
+		g.client.SetRateLimiter(client.APITypePublic, public)
+	}
+	if private != nil {
+		g.client.SetRateLimiter(client.APITypePrivate, private)
+	}
+	g.logger.Info().Bool("public", public != nil).Bool("private", private != nil).Msg("Shared rate limiters set")
 }
 
 // SetLogger sets custom logger
@@ -200,6 +387,20 @@ func (g *Gemini) SetHTTPClient(client *http.Client) {
 	g.logger.Info().Msg("Custom HTTP client set")
 }
 
+// SetTransport sends requests through a net/http client using transport, for example to add
+// tracing. Passing nil restores the default client.
+func (g *Gemini) SetTransport(transport http.RoundTripper) {
+	g.client.SetTransport(transport)
+	g.logger.Info().Bool("custom", transport != nil).Msg("HTTP transport set")
+}
+
+// SetHTTP2 sends requests over HTTP/2 where the server supports it. See client.HTTPClient.SetHTTP2
+// for the tradeoffs.
+func (g *Gemini) SetHTTP2(enabled bool) {
+	g.client.SetHTTP2(enabled)
+	g.logger.Info().Bool("enabled", enabled).Msg("HTTP/2 set")
+}
+
 // SetHeaders sets custom headers for the HTTP client
 func (g *Gemini) SetHeaders(headers map[string]string) {
 	// Preserve essential headers
@@ -212,20 +413,325 @@ func (g *Gemini) SetHeaders(headers map[string]string) {
 	g.client.SetHeaders(headers)
 }
 
+// SetConnectionPool configures connection pooling for the HTTP client
+func (g *Gemini) SetConnectionPool(maxConnsPerHost int, idleTimeout time.Duration) {
+	g.client.SetConnectionPool(maxConnsPerHost, idleTimeout)
+}
+
+// SetMaxResponseBytes limits the size of HTTP response bodies (64 MiB by default)
+func (g *Gemini) SetMaxResponseBytes(n int) {
+	g.client.SetMaxResponseBytes(n)
+}
+
+// SetMaxRateLimitWait makes requests fail with ErrRateLimit when no rate limit token becomes
+// available within d, instead of queueing. Non-positive values disable the cap.
+func (g *Gemini) SetMaxRateLimitWait(d time.Duration) {
+	g.client.SetMaxRateLimitWait(d)
+}
+
+// SetDialTimeout bounds how long connecting to Gemini or a proxy may take, separately from the
+// request timeout, so an unreachable endpoint fails fast. Non-positive values restore the defaults.
+func (g *Gemini) SetDialTimeout(d time.Duration) {
+	g.client.SetDialTimeout(d)
+}
+
+// SetHTTPCache enables or disables caching of GET responses that carry an ETag or
+// Last-Modified header, such as slowly changing symbol lists. Cached URLs are revalidated with
+// conditional requests and served from the cache on 304 Not Modified, which saves bandwidth
+// and parsing but not rate limit tokens. Up to maxEntries responses are kept.
+func (g *Gemini) SetHTTPCache(enabled bool, maxEntries int) {
+	g.client.SetHTTPCache(enabled, maxEntries)
+}
+
+// EnableDebug starts capturing recent requests and responses, with credentials masked,
+// for inspection with LastRoundTrip
+func (g *Gemini) EnableDebug() {
+	g.client.EnableDebug()
+}
+
+// DisableDebug stops capturing requests and discards the captured ones
+func (g *Gemini) DisableDebug() {
+	g.client.DisableDebug()
+}
+
+// LastRoundTrip returns the most recent request and response captured since EnableDebug
+func (g *Gemini) LastRoundTrip() (client.RoundTrip, bool) {
+	return g.client.LastRoundTrip()
+}
+
+// RoundTrips returns the requests and responses captured since EnableDebug, oldest first
+func (g *Gemini) RoundTrips() []client.RoundTrip {
+	return g.client.RoundTrips()
+}
+
+// SetLogRedaction enables or disables masking of credentials and bodies in logs (enabled by default)
+func (g *Gemini) SetLogRedaction(enabled bool) {
+	g.client.SetLogRedaction(enabled)
+}
+
 // SetProxies sets proxy configuration for the HTTP client
 func (g *Gemini) SetProxies(proxies []string) {
 	g.client.SetProxies(proxies)
 }
 
-// SetAPICredentials sets the API credentials
+// Shutdown stops accepting new requests and drains in-flight ones until ctx is done
+func (g *Gemini) Shutdown(ctx context.Context) error {
+	return g.client.Shutdown(ctx)
+}
+
+// ProxyStats returns health statistics for each configured proxy
+func (g *Gemini) ProxyStats() map[string]client.ProxyStat {
+	return g.client.ProxyStats()
+}
+
+// SetRequestObserver sets an observer that is notified of every HTTP request with its
+// rate limiter queueing and network round trip timings
+func (g *Gemini) SetRequestObserver(observer client.RequestObserver) {
+	g.client.SetRequestObserver(observer)
+}
+
+// apiCredentials is an API key and its secret
+type apiCredentials struct {
+	key    string
+	secret string
+}
+
+// SetAPICredentials sets the API credentials of the current mode: the sandbox pair in sandbox
+// mode and the production pair otherwise. They are used until SetSandbox switches modes.
 func (g *Gemini) SetAPICredentials(apiKey, apiSecret string) {
-	g.apiKey = apiKey
-	g.apiSecret = apiSecret
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.sandbox {
+		g.sandboxCredentials = apiCredentials{key: apiKey, secret: apiSecret}
+	} else {
+		g.prodCredentials = apiCredentials{key: apiKey, secret: apiSecret}
+	}
+	g.apiKey, g.apiSecret = apiKey, apiSecret
+}
+
+// SetSandboxAPICredentials sets the API credentials used in sandbox mode, whichever mode is
+// current. Empty credentials make sandbox mode fall back to the production pair.
+func (g *Gemini) SetSandboxAPICredentials(apiKey, apiSecret string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sandboxCredentials = apiCredentials{key: apiKey, secret: apiSecret}
+	g.useCredentials()
+}
+
+// useCredentials activates the key pair of the current mode. g.mu must be held.
+func (g *Gemini) useCredentials() {
+	active := g.prodCredentials
+	if g.sandbox && (g.sandboxCredentials.key != "" || g.sandboxCredentials.secret != "") {
+		active = g.sandboxCredentials
+	}
+	g.apiKey, g.apiSecret = active.key, active.secret
 }
 
-// SetSandbox enables or disables sandbox mode
+// SetSigner replaces the HMAC signing of private requests, for example to sign with a secret
+// held in an HSM. The API secret is not needed while a signer is set. Passing nil restores
+// signing with the API key and secret.
+func (g *Gemini) SetSigner(signer Signer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.signer = signer
+}
+
+// SetDefaultAccount sets the account used by private requests that do not specify one.
+// With a master API key this selects the sub-account without passing it to every method;
+// an explicit account argument still takes precedence. An empty name clears the default, so
+// requests without an account go to the API key's own account. Config.DefaultAccount sets the
+// default at creation.
+func (g *Gemini) SetDefaultAccount(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.defaultAccount = name
+}
+
+// SetStrictSymbols makes GetTradingPairs fail when the symbol details endpoint omits any
+// listed symbol, instead of returning a pair with guessed assets and zero limits for it.
+// A truncated details list then surfaces as an error rather than silently missing limits.
+func (g *Gemini) SetStrictSymbols(strict bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.strictSymbols = strict
+}
+
+// SetSymbolFilter limits GetTradingPairs to symbols matching one of the include patterns and
+// none of the exclude patterns. Patterns are case-insensitive globs as in path.Match, such as
+// "btcusd", "*usd" or "eth*". An empty include list includes every symbol, and passing two
+// empty lists removes the filter. Invalid patterns fail with ErrInvalidInput and leave the
+// previous filter in place.
+func (g *Gemini) SetSymbolFilter(include, exclude []string) error {
+	var filter *symbolFilter
+	if len(include) > 0 || len(exclude) > 0 {
+		var err error
+		if filter, err = newSymbolFilter(include, exclude); err != nil {
+			return err
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.symbolFilter = filter
+	return nil
+}
+
+// SetReconnectBackoff sets the strategy that computes the delay between reconnect attempts of
+// streams opened afterwards, such as SingleSymbolStream. The attempt count restarts after every
+// successful reconnect. A nil strategy restores the default, exponential backoff with full
+// jitter from 100ms up to 30s.
+func (g *Gemini) SetReconnectBackoff(strategy backoff.Strategy) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.reconnectBackoff = strategy
+}
+
+// reconnectStrategy returns the stream reconnect backoff strategy
+func (g *Gemini) reconnectStrategy() backoff.Strategy {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.reconnectBackoff == nil {
+		return backoff.NewFullJitter(marketDataReconnectBase, marketDataReconnectMax, 2, nil)
+	}
+	return g.reconnectBackoff
+}
+
+// StrictSymbols reports whether GetTradingPairs fails for symbols without details
+func (g *Gemini) StrictSymbols() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.strictSymbols
+}
+
+// DefaultAccount returns the account set with SetDefaultAccount or Config.DefaultAccount
+func (g *Gemini) DefaultAccount() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.defaultAccount
+}
+
+// SetNonceManager sets the nonce generator used to sign private requests
+func (g *Gemini) SetNonceManager(nonces NonceManager) {
+	g.SetNonceSource(managerNonceSource{manager: nonces})
+}
+
+// SetNonceSource sets a fallible nonce source used to sign private requests,
+// for example a counter shared by several processes using the same API key
+func (g *Gemini) SetNonceSource(src NonceSource) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nonces = src
+}
+
+// SetNonceJump makes the nonce after a rejected one skip jump ahead of the nonce source, and
+// later nonces stay above it until the source catches up. This recovers from collisions
+// with another process that uses the same API key and a nonce source running ahead. jump is in
+// the unit of the nonce source; the default source uses nanoseconds, so int64(time.Second)
+// skips one second. Nonces that are not integers are never adjusted. 0, the default, disables it.
+func (g *Gemini) SetNonceJump(jump int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if jump < 0 {
+		jump = 0
+	}
+	g.nonceJump = jump
+}
+
+// ReserveNonce generates a nonce without sending a request. Passing it to ContextWithNonce
+// lets a retried mutating request be re-signed with its original nonce.
+func (g *Gemini) ReserveNonce() (string, error) {
+	return g.nextNonce(context.Background())
+}
+
+// nextNonce returns the nonce for a private request: the nonce pinned on ctx
+// by ContextWithNonce if there is one, otherwise a new nonce from the nonce source
+func (g *Gemini) nextNonce(ctx context.Context) (string, error) {
+	if nonce, ok := nonceFromContext(ctx); ok {
+		return nonce, nil
+	}
+
+	g.mu.RLock()
+	nonces := g.nonces
+	g.mu.RUnlock()
+
+	nonce, err := nonces.Next()
+	if err != nil {
+		return "", errors.Wrap(errors.ErrUnknown, "failed to generate nonce", err)
+	}
+	return g.adjustNonce(nonce), nil
+}
+
+// adjustNonce keeps nonce above the floor set after a rejected nonce, skipping nonceJump
+// past the last nonce when a rejection is pending
+func (g *Gemini) adjustNonce(nonce string) string {
+	value, err := strconv.ParseInt(nonce, 10, 64)
+	if err != nil {
+		return nonce
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.nonceJump == 0 {
+		g.nonceRejected = false
+		return nonce
+	}
+	if g.nonceRejected {
+		g.nonceRejected = false
+		if value > g.nonceFloor {
+			g.nonceFloor = value
+		}
+		g.nonceFloor += g.nonceJump
+		g.logger.Warn().Int64("jump", g.nonceJump).Msg("Skipping nonces ahead after a rejected nonce")
+	}
+	if value > g.nonceFloor {
+		return nonce
+	}
+	g.nonceFloor++
+	return strconv.FormatInt(g.nonceFloor, 10)
+}
+
+// sequencers holds the client.Sequencer of each API key, shared by every Gemini instance in the
+// process, so that requests signed with one key are sent in nonce order
+var sequencers sync.Map
+
+// sequencer returns the sequencer of the active API key. Instances that sign with a custom
+// Signer and no API key are sequenced on their own.
+func (g *Gemini) sequencer() *client.Sequencer {
+	g.mu.RLock()
+	var key interface{} = g.apiKey
+	if g.apiKey == "" {
+		key = g
+	}
+	g.mu.RUnlock()
+
+	seq, _ := sequencers.LoadOrStore(key, client.NewSequencer())
+	return seq.(*client.Sequencer)
+}
+
+// postPrivate sends a request signed by sign, consuming weight rate limit tokens. sign is called
+// with the API key's sequencer held, which stays held until the response arrives, so a nonce it
+// draws never reaches Gemini after a larger one. Nonce rejections are noted so that the next
+// nonce can skip ahead; rejections of nonces pinned with ContextWithNonce are expected on
+// retries and do not count. See SetNonceJump.
+func (g *Gemini) postPrivate(ctx context.Context, url string, weight int, sign client.SignFunc) ([]byte, error) {
+	response, err := g.client.RequestSigned(ctx, "POST", url, nil, client.APITypePrivate, weight, g.sequencer(), sign)
+	if _, pinned := nonceFromContext(ctx); err != nil && !pinned && IsNonceError(requestError("", err)) {
+		g.mu.Lock()
+		g.nonceRejected = true
+		g.mu.Unlock()
+	}
+	return response, err
+}
+
+// SetSandbox enables or disables sandbox mode. It switches to the sandbox or production base
+// URL and to the credentials of that mode, so a production key is never sent to the sandbox
+// or the other way round when both pairs are configured.
 func (g *Gemini) SetSandbox(sandbox bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	g.sandbox = sandbox
+	g.useCredentials()
+	g.failover = nil
 	if sandbox {
 		g.baseURL = baseURLSandbox
 	} else {
@@ -233,31 +739,194 @@ func (g *Gemini) SetSandbox(sandbox bool) {
 	}
 }
 
+// SetBaseURLs sets an ordered list of base URLs to fail over between.
+// After repeated connection errors or 5xx responses from the active base URL,
+// subsequent requests move to the next URL; failed URLs are skipped for a short cooldown.
+// Failover never replays a request, so signed requests are not sent twice.
+func (g *Gemini) SetBaseURLs(urls []string) error {
+	if len(urls) == 0 {
+		return errors.New(errors.ErrInvalidInput, "at least one base URL is required")
+	}
+	cleaned := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if err := exchange.ValidateBaseURL(u); err != nil {
+			return err
+		}
+		cleaned = append(cleaned, strings.TrimSuffix(u, "/"))
+	}
+
+	g.mu.Lock()
+	g.baseURL = cleaned[0]
+	g.failover = nil
+	if len(cleaned) > 1 {
+		g.failover = newBaseURLFailover(cleaned)
+	}
+	g.mu.Unlock()
+
+	g.client.SetResultHook(g.observeResult)
+	g.logger.Info().Strs("baseURLs", cleaned).Msg("Base URLs updated")
+	return nil
+}
+
+// observeResult feeds request outcomes into base URL failover
+func (g *Gemini) observeResult(url string, statusCode int, err error) {
+	g.mu.RLock()
+	failover := g.failover
+	previous := g.baseURL
+	g.mu.RUnlock()
+	if failover == nil {
+		return
+	}
+
+	next := failover.observe(url, statusCode, err)
+	if next == previous {
+		return
+	}
+
+	g.mu.Lock()
+	if g.failover == failover {
+		g.baseURL = next
+	}
+	g.mu.Unlock()
+	g.logger.Warn().Str("from", previous).Str("to", next).Msg("Failing over to next base URL")
+}
+
+// getBaseURL returns the active base URL
+func (g *Gemini) getBaseURL() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.baseURL
+}
+
+// Warmup opens a connection to the active base URL so the first request does not pay for DNS
+// resolution and the TLS handshake. Call it at startup, shortly before latency-sensitive
+// requests: idle connections are closed after client.DefaultMaxIdleConnDuration.
+func (g *Gemini) Warmup(ctx context.Context) error {
+	return g.client.Warmup(ctx, g.getBaseURL())
+}
+
+// SetWithdrawalGuard enables or disables the production withdrawal guard.
+// When enabled (the default), withdrawals sent to any base URL other than the Gemini
+// sandbox fail unless the request sets ConfirmProduction.
+func (g *Gemini) SetWithdrawalGuard(enabled bool) {
+	g.mu.Lock()
+	g.withdrawalGuard = enabled
+	g.mu.Unlock()
+	g.logger.Info().Bool("enabled", enabled).Msg("Withdrawal guard updated")
+}
+
+// withdrawalNeedsConfirmation reports whether the withdrawal guard applies to the active base URL.
+// The guard follows the URL rather than the sandbox flag, so base URLs set with SetBaseURLs
+// are treated as production.
+func (g *Gemini) withdrawalNeedsConfirmation() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.withdrawalGuard && g.baseURL != baseURLSandbox
+}
+
 // ValidateConfig validates the exchange configuration
 func (g *Gemini) ValidateConfig() error {
+	baseURL := g.getBaseURL()
+
 	// Basic validation
-	if g.baseURL == "" {
+	if baseURL == "" {
 		return errors.New(errors.ErrInvalidInput, "base URL is required")
 	}
 
 	// Validate URL format
-	if !strings.HasPrefix(g.baseURL, "http://") && !strings.HasPrefix(g.baseURL, "https://") {
-		return errors.New(errors.ErrInvalidInput, "invalid base URL format")
+	if err := exchange.ValidateBaseURL(baseURL); err != nil {
+		return err
 	}
 
 	// Test connectivity
-	testURL := fmt.Sprintf("%s/v1/symbols", g.baseURL)
+	testURL := fmt.Sprintf("%s/v1/symbols", baseURL)
 	ctx := context.Background()
 	_, err := g.client.Get(ctx, testURL)
 	if err != nil {
-		return errors.Wrap(errors.ErrNetworkError, "failed to connect to Gemini API", err)
+		return requestError("failed to connect to Gemini API", err)
 	}
 
 	return nil
 }
 
+// GetServerTime fetches the current server time from the Date header of a lightweight public endpoint.
+// The Date header has one-second resolution.
+func (g *Gemini) GetServerTime(ctx context.Context) (time.Time, error) {
+	url := fmt.Sprintf("%s/v1/pubticker/btcusd", g.getBaseURL())
+
+	_, headers, err := g.client.GetWithResponseHeaders(ctx, url, client.APITypePublic)
+	if err != nil {
+		return time.Time{}, requestError("failed to fetch server time", err)
+	}
+
+	date := headers.Get("Date")
+	if date == "" {
+		return time.Time{}, errors.New(errors.ErrMissingField, "server response has no Date header")
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}, errors.Wrap(errors.ErrDataParsingError, "failed to parse server Date header", err)
+	}
+	return serverTime, nil
+}
+
+// ClockSkew estimates how far the local clock is ahead of (positive) or behind (negative) the server.
+// Local time is taken at the midpoint of the request to compensate for latency. A warning is logged
+// when the skew exceeds a few seconds, since that can cause nonce and signature failures.
+func (g *Gemini) ClockSkew(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	serverTime, err := g.GetServerTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	localTime := start.Add(time.Since(start) / 2)
+
+	skew := localTime.Sub(serverTime)
+	if skew > clockSkewWarnThreshold || skew < -clockSkewWarnThreshold {
+		g.logger.Warn().Dur("skew", skew).Msg("Local clock is skewed relative to Gemini server time")
+	}
+	return skew, nil
+}
+
 // Helper functions
 
+// filterTradingPairsByStatus returns the pairs whose status matches, ignoring case
+func filterTradingPairsByStatus(pairs []exchange.TradingPair, status SymbolStatus) []exchange.TradingPair {
+	filtered := make([]exchange.TradingPair, 0, len(pairs))
+	for _, pair := range pairs {
+		if strings.EqualFold(pair.Status, string(status)) {
+			filtered = append(filtered, pair)
+		}
+	}
+	return filtered
+}
+
+// tradingPairFromDetails converts Gemini symbol details to a unified trading pair
+func tradingPairFromDetails(detail SymbolDetails) exchange.TradingPair {
+	minOrderSize, _ := parseFloatFromString(detail.MinOrderSize)
+
+	return exchange.TradingPair{
+		Symbol:     strings.ToUpper(detail.Symbol),
+		BaseAsset:  strings.ToUpper(detail.BaseCurrency),
+		QuoteAsset: strings.ToUpper(detail.QuoteCurrency),
+		Status:     string(detail.Status),
+		MinQty:     minOrderSize,
+		MaxQty:     0, // Gemini doesn't provide max order size in this endpoint
+		StepSize:   0,
+		TickSize:   float64(detail.TickSize),
+	}
+}
+
+// containsSymbol reports whether symbols contains symbol, ignoring case
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractBaseCurrency extracts base currency from symbol
 // For Gemini, symbols are typically like "btcusd", "ethusd", etc.
 func extractBaseCurrency(symbol string) string {
@@ -1,11 +1,22 @@
 package gemini
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/rs/zerolog"
 )
 
 func TestNewGemini(t *testing.T) {
@@ -41,6 +52,276 @@ func TestNewGemini(t *testing.T) {
 	}
 }
 
+func TestNewGemini_BaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *exchange.Config
+		expected string
+		sandbox  bool
+	}{
+		{"sandbox alias", &exchange.Config{Sandbox: true}, "https://api.sandbox.gemini.com", true},
+		{"base url", &exchange.Config{BaseURL: "http://localhost:8080/"}, "http://localhost:8080", false},
+		{"base url over testnet", &exchange.Config{BaseURL: "https://gateway.example.com/gemini", Testnet: true}, "https://gateway.example.com/gemini", true},
+	}
+	for _, test := range tests {
+		g := NewGemini(test.config)
+		if g.getBaseURL() != test.expected {
+			t.Errorf("%s: expected base URL %s, got %s", test.name, test.expected, g.getBaseURL())
+		}
+		if g.sandbox != test.sandbox {
+			t.Errorf("%s: expected sandbox %v, got %v", test.name, test.sandbox, g.sandbox)
+		}
+	}
+}
+
+func TestNewGemini_SandboxCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *exchange.Config
+		key    string
+		secret string
+	}{
+		{"production ignores sandbox keys", &exchange.Config{APIKey: "prod-key", SecretKey: "prod-secret", SandboxAPIKey: "sb-key", SandboxSecretKey: "sb-secret"}, "prod-key", "prod-secret"},
+		{"sandbox prefers sandbox keys", &exchange.Config{Testnet: true, APIKey: "prod-key", SecretKey: "prod-secret", SandboxAPIKey: "sb-key", SandboxSecretKey: "sb-secret"}, "sb-key", "sb-secret"},
+		{"sandbox alias", &exchange.Config{Sandbox: true, SandboxAPIKey: "sb-key", SandboxSecretKey: "sb-secret"}, "sb-key", "sb-secret"},
+		{"sandbox falls back to main keys", &exchange.Config{Testnet: true, APIKey: "key", SecretKey: "secret"}, "key", "secret"},
+		{"sandbox keys are not mixed", &exchange.Config{Testnet: true, APIKey: "prod-key", SecretKey: "prod-secret", SandboxAPIKey: "sb-key"}, "sb-key", ""},
+	}
+	for _, test := range tests {
+		g := NewGemini(test.config)
+		if g.apiKey != test.key || g.apiSecret != test.secret {
+			t.Errorf("%s: expected credentials %q/%q, got %q/%q", test.name, test.key, test.secret, g.apiKey, g.apiSecret)
+		}
+	}
+}
+
+func TestNewGemini_BaseURLRequests(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`["btcusd"]`))
+	}))
+	defer server.Close()
+
+	config := exchange.Config{BaseURL: server.URL + "/", Testnet: true}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected %s to be valid, got %v", config.BaseURL, err)
+	}
+	g := NewGemini(&config)
+	if _, err := g.Market.ListSymbols(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/v1/symbols" {
+		t.Errorf("Expected the request to reach the configured base URL, got %v", paths)
+	}
+
+	factory := exchange.NewFactory()
+	factory.Register("gemini", func(config exchange.Config) exchange.Exchange {
+		return NewGemini(&config)
+	})
+	for _, invalid := range []string{"localhost:8080", "ftp://example.com", "https://", "https://example.com/?x=1"} {
+		if _, err := factory.Create("gemini", exchange.Config{BaseURL: invalid}); errors.GetCode(err) != errors.ErrInvalidInput {
+			t.Errorf("Expected base URL %q to be rejected, got %v", invalid, err)
+		}
+	}
+}
+
+// counterNonces is a deterministic NonceManager for tests
+type counterNonces struct{ n int }
+
+func (c *counterNonces) Next() string {
+	c.n++
+	return fmt.Sprintf("%d", c.n)
+}
+
+func TestNewGeminiWithOptions(t *testing.T) {
+	nonces := &counterNonces{}
+	g := NewGeminiWithOptions(
+		WithCredentials("opt-key", "opt-secret"),
+		WithSandbox(true),
+		WithRateLimit(exchange.APITypePrivate, 5, time.Second),
+		WithNonceManager(nonces),
+		WithDefaultAccount("primary"),
+	)
+
+	if g.apiKey != "opt-key" || g.apiSecret != "opt-secret" {
+		t.Errorf("Expected credentials to be set, got '%s'/'%s'", g.apiKey, g.apiSecret)
+	}
+	if g.baseURL != "https://api.sandbox.gemini.com" {
+		t.Errorf("Expected sandbox URL, got '%s'", g.baseURL)
+	}
+	first, _ := g.nextNonce(context.Background())
+	second, _ := g.nextNonce(context.Background())
+	if first != "1" || second != "2" {
+		t.Error("Expected nonces from the custom nonce manager")
+	}
+	if g.DefaultAccount() != "primary" {
+		t.Errorf("Expected default account 'primary', got '%s'", g.DefaultAccount())
+	}
+}
+
+// failingNonces is a NonceSource whose backing store is unavailable
+type failingNonces struct{}
+
+func (failingNonces) Next() (string, error) {
+	return "", fmt.Errorf("nonce store unavailable")
+}
+
+func TestGemini_SetNonceSource(t *testing.T) {
+	g := NewGeminiWithOptions(
+		WithCredentials("key", "secret"),
+		WithNonceSource(failingNonces{}),
+	)
+
+	// A failing nonce source aborts private requests before anything is sent
+	_, err := g.Fund.GetAvailableBalances(context.Background(), "")
+	if errors.GetCode(err) != errors.ErrUnknown {
+		t.Errorf("Expected nonce source error, got %v", err)
+	}
+}
+
+func TestGemini_ContextWithNonce(t *testing.T) {
+	var nonces []string
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, decodeTestPayload(t, r)["nonce"].(string))
+		_, _ = w.Write([]byte(`{"order_id":"1"}`))
+	})
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "100", Side: OrderSideBuy, Type: OrderTypeExchangeLimit}
+
+	nonce, err := g.ReserveNonce()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Every attempt of a retried request is signed with the reserved nonce
+	ctx := ContextWithNonce(context.Background(), nonce)
+	for i := 0; i < 2; i++ {
+		if _, err := g.Order.PlaceOrder(ctx, req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// Without a pinned nonce a fresh one is generated
+	if _, err := g.Order.PlaceOrder(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(nonces) != 3 || nonces[0] != nonce || nonces[1] != nonce || nonces[2] == nonce {
+		t.Errorf("Expected reserved nonce to be reused only when pinned, got %v (reserved %s)", nonces, nonce)
+	}
+}
+
+func TestGemini_ConcurrentRequestsKeepNonceOrder(t *testing.T) {
+	var mu sync.Mutex
+	var last int64
+	inflight, peak := 0, 0
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		nonce, err := strconv.ParseInt(decodeTestPayload(t, r)["nonce"].(string), 10, 64)
+		if err != nil {
+			t.Errorf("Unexpected nonce: %v", err)
+		}
+		mu.Lock()
+		inflight++
+		peak = max(peak, inflight)
+		stale := nonce <= last
+		if !stale {
+			last = nonce
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inflight--
+			mu.Unlock()
+		}()
+
+		// Like Gemini, reject a nonce that arrives after a larger one
+		if stale {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidNonce","message":"Nonce has not increased"}`))
+			return
+		}
+		time.Sleep(time.Millisecond)
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	accounts := make([]string, 20)
+	for i := range accounts {
+		accounts[i] = fmt.Sprintf("sub-%d", i)
+	}
+	ctx := context.Background()
+	if _, errs := g.Fund.GetAllAccountBalances(ctx, accounts); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+	if _, errs := g.Order.GetAllActiveOrders(ctx, accounts); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+	if peak != 1 {
+		t.Errorf("Expected signed requests with one key to be sent one at a time, got %d at once", peak)
+	}
+}
+
+func TestGemini_SetNonceJump(t *testing.T) {
+	var nonces []string
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		nonce := decodeTestPayload(t, r)["nonce"].(string)
+		nonces = append(nonces, nonce)
+		if nonce == "2" || nonce == "3" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidNonce","message":"Nonce has not increased"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	g.SetNonceManager(&counterNonces{})
+	g.SetNonceJump(100)
+	ctx := context.Background()
+
+	_, err := g.Fund.GetAvailableBalances(ctx, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = g.Fund.GetAvailableBalances(ctx, "")
+	if !IsNonceError(err) {
+		t.Fatalf("Expected a nonce error, got %v", err)
+	}
+	// The next nonce skips ahead of the source, and later ones stay above it
+	for i := 0; i < 2; i++ {
+		if _, err := g.Fund.GetAvailableBalances(ctx, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// A rejected pinned nonce is expected on a retry and does not skip ahead
+	_, err = g.Fund.GetAvailableBalances(ContextWithNonce(ctx, "3"), "")
+	if !IsNonceError(err) {
+		t.Fatalf("Expected a nonce error, got %v", err)
+	}
+	if _, err := g.Fund.GetAvailableBalances(ctx, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"1", "2", "104", "105", "3", "106"}
+	if strings.Join(nonces, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected nonces %v, got %v", expected, nonces)
+	}
+	if IsNonceError(errors.New(errors.ErrAPIError, "other")) {
+		t.Error("Expected other errors not to be nonce errors")
+	}
+}
+
+func TestTimeNonceManager(t *testing.T) {
+	nonces := NewTimeNonceManager()
+	previous := int64(0)
+	for i := 0; i < 1000; i++ {
+		nonce, err := strconv.ParseInt(nonces.Next(), 10, 64)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if nonce <= previous {
+			t.Fatalf("Expected strictly increasing nonces, got %d after %d", nonce, previous)
+		}
+		previous = nonce
+	}
+}
+
 func TestGemini_GetName(t *testing.T) {
 	g := NewGemini(nil)
 	name := g.GetName()
@@ -49,6 +330,18 @@ func TestGemini_GetName(t *testing.T) {
 	}
 }
 
+func TestGemini_Capabilities(t *testing.T) {
+	var exch exchange.Exchange = NewGemini(nil)
+	caps := exch.Capabilities()
+
+	if !caps.Spot || !caps.Withdrawals || !caps.Deposits {
+		t.Errorf("Expected spot, deposits and withdrawals to be supported, got %+v", caps)
+	}
+	if caps.Margin || caps.Staking {
+		t.Errorf("Expected margin and staking to be unsupported, got %+v", caps)
+	}
+}
+
 func TestGemini_SetRateLimit(t *testing.T) {
 	g := NewGemini(nil)
 
@@ -61,6 +354,31 @@ func TestGemini_SetRateLimit(t *testing.T) {
 	g.SetRateLimit(exchange.APITypePrivate, rateLimit)
 }
 
+func TestGemini_SetSharedRateLimiter(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}
+	shared := client.NewRateLimiter(3, time.Hour)
+	first := newTestGemini(t, handler)
+	second := newTestGemini(t, handler)
+	first.SetSharedRateLimiter(nil, shared)
+	second.SetSharedRateLimiter(nil, shared)
+
+	ctx := context.Background()
+	if _, err := first.Order.GetActiveOrders(ctx, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := second.Order.GetActiveOrders(ctx, "sub-account"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tokens := shared.AvailableTokens(); tokens != 1 {
+		t.Errorf("Expected both instances to draw from the shared limiter, %d tokens left", tokens)
+	}
+	if first.client.GetRateLimiter(client.APITypePublic) == shared {
+		t.Error("Expected the public limiter to be left unchanged")
+	}
+}
+
 func TestGemini_SetHeaders(t *testing.T) {
 	g := NewGemini(nil)
 
@@ -116,6 +434,94 @@ func TestGemini_SetSandbox(t *testing.T) {
 	}
 }
 
+func TestGemini_SetSandbox_SwitchesCredentials(t *testing.T) {
+	g := NewGemini(&exchange.Config{APIKey: "prod-key", SecretKey: "prod-secret", SandboxAPIKey: "sandbox-key", SandboxSecretKey: "sandbox-secret"})
+	if g.apiKey != "prod-key" {
+		t.Fatalf("Expected production key, got '%s'", g.apiKey)
+	}
+
+	g.SetSandbox(true)
+	if g.apiKey != "sandbox-key" || g.apiSecret != "sandbox-secret" {
+		t.Errorf("Expected sandbox credentials in sandbox mode, got '%s'", g.apiKey)
+	}
+	g.SetSandbox(false)
+	if g.apiKey != "prod-key" || g.apiSecret != "prod-secret" {
+		t.Errorf("Expected production credentials in production mode, got '%s'", g.apiKey)
+	}
+
+	// Without a sandbox pair, sandbox mode falls back to the production pair
+	g.SetSandboxAPICredentials("", "")
+	g.SetSandbox(true)
+	if g.apiKey != "prod-key" {
+		t.Errorf("Expected production key as fallback, got '%s'", g.apiKey)
+	}
+
+	// SetAPICredentials sets the pair of the current mode only
+	g.SetAPICredentials("new-sandbox-key", "new-sandbox-secret")
+	g.SetSandbox(false)
+	if g.apiKey != "prod-key" {
+		t.Errorf("Expected production key after leaving sandbox mode, got '%s'", g.apiKey)
+	}
+}
+
+func TestGemini_SetBaseURLs(t *testing.T) {
+	g := NewGemini(nil)
+
+	if err := g.SetBaseURLs(nil); err == nil {
+		t.Error("Expected error for empty base URL list")
+	}
+	if err := g.SetBaseURLs([]string{"invalid-url"}); err == nil {
+		t.Error("Expected error for invalid base URL")
+	}
+
+	primary, backup := "https://primary.example.com", "https://backup.example.com"
+	if err := g.SetBaseURLs([]string{primary + "/", backup}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if g.getBaseURL() != primary {
+		t.Errorf("Expected primary URL, got '%s'", g.getBaseURL())
+	}
+
+	// 4xx responses do not count as failures
+	for i := 0; i < failoverThreshold; i++ {
+		g.observeResult(primary+"/v1/symbols", 400, nil)
+	}
+	if g.getBaseURL() != primary {
+		t.Errorf("Expected to stay on primary URL, got '%s'", g.getBaseURL())
+	}
+
+	// Repeated 5xx responses fail over to the backup
+	for i := 0; i < failoverThreshold; i++ {
+		g.observeResult(primary+"/v1/symbols", 503, nil)
+	}
+	if g.getBaseURL() != backup {
+		t.Errorf("Expected backup URL after failover, got '%s'", g.getBaseURL())
+	}
+}
+
+func TestGemini_ClockSkew(t *testing.T) {
+	serverTime := time.Now().Add(-time.Minute).UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	g := NewGemini(nil)
+	if err := g.SetBaseURLs([]string{server.URL}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	skew, err := g.ClockSkew(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The Date header has one-second resolution
+	if skew < time.Minute-2*time.Second || skew > time.Minute+2*time.Second {
+		t.Errorf("Expected skew of about one minute, got %v", skew)
+	}
+}
+
 func TestGemini_ValidateConfig(t *testing.T) {
 	// Test with valid config
 	g := NewGemini(nil)
@@ -138,6 +544,180 @@ func TestGemini_ValidateConfig(t *testing.T) {
 	}
 }
 
+// symbolDetailsFixture is a trimmed /v1/symbols/details response
+const symbolDetailsFixture = `[
+	{"symbol":"BTCUSD","base_currency":"BTC","quote_currency":"USD","tick_size":1e-2,"min_order_size":"0.00001","status":"open"},
+	{"symbol":"ETHUSD","base_currency":"ETH","quote_currency":"USD","tick_size":1e-2,"min_order_size":"0.001","status":"open"},
+	{"symbol":"LTCUSD","base_currency":"LTC","quote_currency":"USD","tick_size":1e-2,"min_order_size":"0.01","status":"limit_only"},
+	{"symbol":"ZECUSD","base_currency":"ZEC","quote_currency":"USD","tick_size":1e-2,"min_order_size":"0.001","status":"closed"},
+	{"symbol":"DOGEUSD","base_currency":"DOGE","quote_currency":"USD","tick_size":1e-5,"min_order_size":"1","status":"cancel_only"}
+]`
+
+func TestGemini_GetTradingPairsFiltered(t *testing.T) {
+	var fixture []SymbolDetails
+	if err := json.Unmarshal([]byte(symbolDetailsFixture), &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	// Seed the symbol cache so no network requests are made
+	g := NewGemini(nil)
+	symbols := make([]string, 0, len(fixture))
+	for _, detail := range fixture {
+		symbols = append(symbols, strings.ToLower(detail.Symbol))
+		g.symbols.putDetails(detail)
+	}
+	g.symbols.putSymbols(symbols)
+
+	tests := []struct {
+		status   SymbolStatus
+		expected []string
+	}{
+		{SymbolStatusOpen, []string{"BTCUSD", "ETHUSD"}},
+		{SymbolStatusLimitOnly, []string{"LTCUSD"}},
+		{SymbolStatusClosed, []string{"ZECUSD"}},
+		{SymbolStatusCancelOnly, []string{"DOGEUSD"}},
+		{SymbolStatusPostOnly, []string{}},
+	}
+
+	for _, test := range tests {
+		pairs, err := g.GetTradingPairsFiltered(context.Background(), test.status)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := make([]string, 0, len(pairs))
+		for _, pair := range pairs {
+			got = append(got, pair.Symbol)
+		}
+		if strings.Join(got, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("GetTradingPairsFiltered(%s) = %v, expected %v", test.status, got, test.expected)
+		}
+	}
+}
+
+func TestGemini_GetTradingPairs_MissingDetails(t *testing.T) {
+	// The details list lacks solusd, as when the endpoint returns a truncated list
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/symbols/details" {
+			_, _ = w.Write([]byte(symbolDetailsFixture))
+			return
+		}
+		_, _ = w.Write([]byte(`["solusd","btcusd","ethusd"]`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	g := NewGeminiWithOptions(WithLogger(zerolog.New(&logs)))
+	if err := g.SetBaseURLs([]string{server.URL}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pairs, err := g.GetTradingPairs(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pairs) != 3 || pairs[0].Symbol != "SOLUSD" || pairs[0].MinQty != 0 {
+		t.Errorf("Expected a fallback pair for SOLUSD, got %+v", pairs)
+	}
+	if !strings.Contains(logs.String(), `"symbols":["solusd"]`) {
+		t.Errorf("Expected a warning listing solusd, got %q", logs.String())
+	}
+
+	g.SetStrictSymbols(true)
+	_, err = g.GetTradingPairs(context.Background())
+	if errors.GetCode(err) != errors.ErrInvalidResponse {
+		t.Fatalf("Expected ErrInvalidResponse, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "solusd") {
+		t.Errorf("Expected the error to name solusd, got %v", err)
+	}
+}
+
+func TestGemini_SetSymbolFilter(t *testing.T) {
+	var fixture []SymbolDetails
+	if err := json.Unmarshal([]byte(symbolDetailsFixture), &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	// Seed the symbol cache so no network requests are made
+	g := NewGemini(nil)
+	symbols := make([]string, 0, len(fixture))
+	for _, detail := range fixture {
+		symbols = append(symbols, strings.ToLower(detail.Symbol))
+		g.symbols.putDetails(detail)
+	}
+	g.symbols.putSymbols(symbols)
+
+	tests := []struct {
+		include  []string
+		exclude  []string
+		expected string
+	}{
+		{nil, nil, "BTCUSD,ETHUSD,LTCUSD,ZECUSD,DOGEUSD"},
+		{[]string{"BTCUSD", "eth*"}, nil, "BTCUSD,ETHUSD"},
+		{[]string{"*usd"}, []string{"zec*", "doge*"}, "BTCUSD,ETHUSD,LTCUSD"},
+		{nil, []string{"btc*"}, "ETHUSD,LTCUSD,ZECUSD,DOGEUSD"},
+		{[]string{"*gbp"}, nil, ""},
+	}
+	for _, test := range tests {
+		if err := g.SetSymbolFilter(test.include, test.exclude); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		pairs, err := g.GetTradingPairs(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := make([]string, 0, len(pairs))
+		for _, pair := range pairs {
+			got = append(got, pair.Symbol)
+		}
+		if strings.Join(got, ",") != test.expected {
+			t.Errorf("SetSymbolFilter(%v, %v) gave %v, expected %s", test.include, test.exclude, got, test.expected)
+		}
+	}
+
+	// An invalid pattern keeps the previous filter
+	if err := g.SetSymbolFilter([]string{"[btc"}, nil); errors.GetCode(err) != errors.ErrInvalidInput {
+		t.Errorf("Expected ErrInvalidInput for an invalid pattern, got %v", err)
+	}
+	if pairs, _ := g.GetTradingPairs(context.Background()); len(pairs) != 0 {
+		t.Errorf("Expected the previous filter to stay in place, got %d pairs", len(pairs))
+	}
+}
+
+func TestGemini_GetTradingPair(t *testing.T) {
+	var fixture []SymbolDetails
+	if err := json.Unmarshal([]byte(symbolDetailsFixture), &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	// Seed the symbol cache so no network requests are made
+	g := NewGemini(nil)
+	symbols := make([]string, 0, len(fixture))
+	for _, detail := range fixture {
+		symbols = append(symbols, strings.ToLower(detail.Symbol))
+		g.symbols.putDetails(detail)
+	}
+	g.symbols.putSymbols(symbols)
+
+	pair, err := g.GetTradingPair(context.Background(), "ltcusd")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pair.Symbol != "LTCUSD" || pair.BaseAsset != "LTC" || pair.Status != string(SymbolStatusLimitOnly) {
+		t.Errorf("Unexpected pair: %+v", pair)
+	}
+
+	_, err = g.GetTradingPair(context.Background(), "foobar")
+	if errors.GetCode(err) != errors.ErrInvalidSymbol {
+		t.Errorf("Expected ErrInvalidSymbol, got %v", err)
+	}
+
+	_, err = g.GetTradingPair(context.Background(), "")
+	if errors.GetCode(err) != errors.ErrInvalidInput {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
 func TestExtractBaseCurrency(t *testing.T) {
 	tests := []struct {
 		symbol   string
@@ -214,6 +794,41 @@ func TestParseFloatFromString(t *testing.T) {
 	}
 }
 
+func TestFlexNumbers(t *testing.T) {
+	var details SymbolDetails
+	if err := json.Unmarshal([]byte(`{"symbol":"BTCUSD","tick_size":"0.01","quote_increment":0.01}`), &details); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.TickSize != 0.01 || details.QuoteIncrement != 0.01 {
+		t.Errorf("Expected tick size and quote increment 0.01, got %v and %v", details.TickSize, details.QuoteIncrement)
+	}
+
+	tests := []struct {
+		input     string
+		expected  int64
+		shouldErr bool
+	}{
+		{`1700000000000`, 1700000000000, false},
+		{`"1700000000000"`, 1700000000000, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"abc"`, 0, true},
+	}
+	for _, test := range tests {
+		var v FlexInt
+		err := json.Unmarshal([]byte(test.input), &v)
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("FlexInt(%s) expected error but got none", test.input)
+			}
+			continue
+		}
+		if err != nil || int64(v) != test.expected {
+			t.Errorf("FlexInt(%s) = %d, %v, expected %d", test.input, v, err, test.expected)
+		}
+	}
+}
+
 // Integration test - skip by default to avoid network dependency
 func TestGemini_GetTradingPairs_Integration(t *testing.T) {
 	t.Skip("Skipping integration test to avoid network dependency")
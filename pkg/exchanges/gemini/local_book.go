@@ -0,0 +1,165 @@
+package gemini
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// LocalOrderBook maintains an order book from the updates of a MarketDataStream.
+//
+// Gemini's market data feed carries no checksum, so the book verifies every change event
+// instead: the amount it held at the price plus the event's delta must equal the event's
+// remaining amount. A mismatch means an update was lost or applied twice. The book then stops
+// serving levels, reports the mismatch to the OnChecksumMismatch callback and, when run with
+// Run, asks the stream for a fresh snapshot. It is safe for concurrent use.
+type LocalOrderBook struct {
+	bids       map[string]BookLevel // keyed by normalized price
+	asks       map[string]BookLevel // keyed by normalized price
+	synced     bool                 // set by a snapshot, cleared by a mismatch
+	onMismatch func(error)
+	mu         sync.RWMutex
+}
+
+// NewLocalOrderBook creates an empty order book that becomes synced with the first snapshot
+func NewLocalOrderBook() *LocalOrderBook {
+	return &LocalOrderBook{
+		bids: make(map[string]BookLevel),
+		asks: make(map[string]BookLevel),
+	}
+}
+
+// OnChecksumMismatch sets a callback for updates that do not match the book. It is called with
+// an ErrInvalidResponse error describing the mismatch, before the book resynchronizes.
+func (b *LocalOrderBook) OnChecksumMismatch(fn func(err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onMismatch = fn
+}
+
+// Synced reports whether the book holds a verified snapshot. It is false before the first
+// snapshot and after a mismatch until the next snapshot arrives.
+func (b *LocalOrderBook) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// Apply applies the change events of an update. An update whose changes have reason "initial"
+// replaces the book with a new snapshot. Changes that do not match the book fail with
+// ErrInvalidResponse and leave the book unsynced; later updates are ignored until a snapshot.
+func (b *LocalOrderBook) Apply(update MarketDataUpdate) error {
+	b.mu.Lock()
+	err := b.apply(update)
+	onMismatch := b.onMismatch
+	b.mu.Unlock()
+
+	if err != nil && onMismatch != nil {
+		onMismatch(err)
+	}
+	return err
+}
+
+// apply applies update to the book. b.mu must be held.
+func (b *LocalOrderBook) apply(update MarketDataUpdate) error {
+	snapshot := false
+	for _, event := range update.Events {
+		if event.Type != "change" {
+			continue
+		}
+		if event.Reason == "initial" && !snapshot {
+			snapshot = true
+			b.bids = make(map[string]BookLevel)
+			b.asks = make(map[string]BookLevel)
+			b.synced = true
+		}
+		if !b.synced {
+			return nil
+		}
+		if err := b.applyChange(event, update.EventID); err != nil {
+			b.synced = false
+			return err
+		}
+	}
+	return nil
+}
+
+// applyChange verifies a change event against the level it replaces and applies it
+func (b *LocalOrderBook) applyChange(event MarketDataEvent, eventID FlexInt) error {
+	var side map[string]BookLevel
+	switch event.Side {
+	case "bid":
+		side = b.bids
+	case "ask":
+		side = b.asks
+	default:
+		return errors.Newf(errors.ErrInvalidResponse, "order book change in event %d has unknown side %q", eventID, event.Side)
+	}
+
+	price, err := decimal.NewFromString(event.Price)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInvalidResponse, err, "order book change in event %d has invalid price %q", eventID, event.Price)
+	}
+	remaining, err := decimal.NewFromString(event.Remaining)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInvalidResponse, err, "order book change in event %d has invalid remaining %q", eventID, event.Remaining)
+	}
+	delta, err := decimal.NewFromString(event.Delta)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInvalidResponse, err, "order book change in event %d has invalid delta %q", eventID, event.Delta)
+	}
+
+	key := price.String()
+	previous := side[key].Amount
+	if !previous.Add(delta).Equal(remaining) {
+		return errors.Newf(errors.ErrInvalidResponse, "order book mismatch in event %d at %s %s: held %s, delta %s, remaining %s",
+			eventID, event.Side, key, previous, delta, remaining)
+	}
+
+	if remaining.IsZero() {
+		delete(side, key)
+		return nil
+	}
+	side[key] = BookLevel{Price: price, Amount: remaining}
+	return nil
+}
+
+// Snapshot returns up to depth levels on each side of the book, sorted as in OrderBook.
+// A depth of 0 returns the whole book. It returns nil while the book is not synced.
+func (b *LocalOrderBook) Snapshot(depth int) *OrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.synced {
+		return nil
+	}
+	return &OrderBook{
+		Bids: sortedLevels(b.bids, depth, func(a, b decimal.Decimal) bool { return a.GreaterThan(b) }),
+		Asks: sortedLevels(b.asks, depth, func(a, b decimal.Decimal) bool { return a.LessThan(b) }),
+	}
+}
+
+// sortedLevels returns up to depth levels of one side of the book, best price first
+func sortedLevels(side map[string]BookLevel, depth int, better func(a, b decimal.Decimal) bool) []BookLevel {
+	levels := make([]BookLevel, 0, len(side))
+	for _, level := range side {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool { return better(levels[i].Price, levels[j].Price) })
+	if depth > 0 && depth < len(levels) {
+		levels = levels[:depth]
+	}
+	return levels
+}
+
+// Run applies the updates of stream until it ends and returns stream.Err(). On a mismatch the
+// stream is asked to reconnect, and the book recovers with the snapshot that follows.
+func (b *LocalOrderBook) Run(stream *MarketDataStream) error {
+	for update := range stream.Updates() {
+		if err := b.Apply(update); err != nil {
+			stream.Resync()
+		}
+	}
+	return stream.Err()
+}
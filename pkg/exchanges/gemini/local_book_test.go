@@ -0,0 +1,121 @@
+package gemini
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 -- test WebSocket handshake
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUpdate decodes a market data update message
+func testUpdate(t *testing.T, message string) MarketDataUpdate {
+	t.Helper()
+	var update MarketDataUpdate
+	require.NoError(t, json.Unmarshal([]byte(message), &update))
+	return update
+}
+
+const testBookSnapshot = `{"type":"update","eventId":1,"socket_sequence":0,"events":[` +
+	`{"type":"change","side":"bid","price":"100.00","remaining":"1","delta":"1","reason":"initial"},` +
+	`{"type":"change","side":"bid","price":"99.50","remaining":"2","delta":"2","reason":"initial"},` +
+	`{"type":"change","side":"ask","price":"101.00","remaining":"3","delta":"3","reason":"initial"}]}`
+
+func TestLocalOrderBook_Apply(t *testing.T) {
+	book := NewLocalOrderBook()
+	var mismatches []error
+	book.OnChecksumMismatch(func(err error) { mismatches = append(mismatches, err) })
+
+	// Updates before the first snapshot are ignored
+	require.NoError(t, book.Apply(testUpdate(t, `{"type":"update","eventId":0,"events":[{"type":"change","side":"bid","price":"98","remaining":"1","delta":"1","reason":"place"}]}`)))
+	assert.False(t, book.Synced())
+	assert.Nil(t, book.Snapshot(0))
+
+	require.NoError(t, book.Apply(testUpdate(t, testBookSnapshot)))
+	require.NoError(t, book.Apply(testUpdate(t, `{"type":"update","eventId":2,"events":[`+
+		`{"type":"trade","tid":2,"price":"101","amount":"1","makerSide":"ask"},`+
+		`{"type":"change","side":"ask","price":"101","remaining":"2","delta":"-1","reason":"trade"},`+
+		`{"type":"change","side":"bid","price":"100","remaining":"0","delta":"-1","reason":"cancel"}]}`)))
+
+	snapshot := book.Snapshot(0)
+	require.NotNil(t, snapshot)
+	require.Len(t, snapshot.Bids, 1)
+	assert.Equal(t, "99.5", snapshot.Bids[0].Price.String())
+	require.Len(t, snapshot.Asks, 1)
+	assert.Equal(t, "2", snapshot.Asks[0].Amount.String())
+	assert.Empty(t, mismatches)
+
+	// The delta does not lead from the held amount of 2 to the remaining amount
+	err := book.Apply(testUpdate(t, `{"type":"update","eventId":3,"events":[{"type":"change","side":"ask","price":"101","remaining":"5","delta":"1","reason":"place"}]}`))
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	assert.False(t, book.Synced())
+	assert.Nil(t, book.Snapshot(0))
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, err, mismatches[0])
+
+	// Later changes are ignored until the next snapshot restores the book
+	require.NoError(t, book.Apply(testUpdate(t, `{"type":"update","eventId":4,"events":[{"type":"change","side":"ask","price":"102","remaining":"1","delta":"1","reason":"place"}]}`)))
+	require.NoError(t, book.Apply(testUpdate(t, testBookSnapshot)))
+	snapshot = book.Snapshot(1)
+	require.Len(t, snapshot.Bids, 1)
+	assert.Equal(t, "100", snapshot.Bids[0].Price.String())
+	assert.Equal(t, "101", snapshot.Asks[0].Price.String())
+	assert.Len(t, book.Snapshot(0).Bids, 2)
+}
+
+func TestLocalOrderBook_Run(t *testing.T) {
+	var connections atomic.Int32
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		h := sha1.New() // #nosec G401 -- test WebSocket handshake
+		h.Write([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		accept := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept + "\r\n\r\n")
+
+		messages := []string{testBookSnapshot}
+		if connections.Add(1) == 1 {
+			// The bid at 100 holds 1, so removing 2 cannot leave 0
+			messages = append(messages, `{"type":"update","eventId":2,"socket_sequence":1,"events":[{"type":"change","side":"bid","price":"100","remaining":"0","delta":"-2","reason":"cancel"}]}`)
+		}
+		for _, message := range messages {
+			writeTestWebSocketText(rw, message)
+		}
+		_ = rw.Flush()
+
+		// Hold the connection open until the client goes away
+		_, _ = rw.ReadByte()
+	})
+
+	stream, err := g.Market.SingleSymbolStream(context.Background(), "btcusd")
+	require.NoError(t, err)
+
+	book := NewLocalOrderBook()
+	var mismatches atomic.Int32
+	book.OnChecksumMismatch(func(err error) { mismatches.Add(1) })
+	done := make(chan error, 1)
+	go func() { done <- book.Run(stream) }()
+
+	require.Eventually(t, func() bool {
+		return connections.Load() == 2 && book.Synced()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), mismatches.Load())
+	assert.Len(t, book.Snapshot(0).Bids, 2)
+
+	require.NoError(t, stream.Close())
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the stream closed")
+	}
+}
@@ -2,23 +2,68 @@ package gemini
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 )
 
+// APIVersion selects which Gemini API version is used where several versions of an endpoint exist
+type APIVersion int
+
+const (
+	APIVersionV1 APIVersion = 1
+	APIVersionV2 APIVersion = 2
+)
+
 // MarketAPI handles market data related operations
 type MarketAPI struct {
-	gemini *Gemini
+	gemini         *Gemini
+	version        APIVersion
+	detailsWorkers int // concurrent requests made by GetSymbolDetailsBatch
+	tickerWorkers  int // concurrent requests made by GetTickersV2
 }
 
+// defaultSymbolDetailsWorkers bounds the concurrent requests made by GetSymbolDetailsBatch
+const defaultSymbolDetailsWorkers = 10
+
+// defaultTickerWorkers bounds the concurrent requests made by GetTickersV2
+const defaultTickerWorkers = 8
+
 // NewMarketAPI creates a new market API instance
 func NewMarketAPI(g *Gemini) *MarketAPI {
 	return &MarketAPI{
-		gemini: g,
+		gemini:         g,
+		version:        APIVersionV2,
+		detailsWorkers: defaultSymbolDetailsWorkers,
+		tickerWorkers:  defaultTickerWorkers,
+	}
+}
+
+// SetSymbolDetailsConcurrency sets how many symbol details requests GetSymbolDetailsBatch and
+// GetAllSymbolDetails make at a time. The requests still share the public rate limiter.
+// Non-positive values restore the default of 10.
+func (m *MarketAPI) SetSymbolDetailsConcurrency(workers int) {
+	if workers <= 0 {
+		workers = defaultSymbolDetailsWorkers
+	}
+	m.detailsWorkers = workers
+}
+
+// SetTickerConcurrency sets how many ticker requests GetTickersV2 makes at a time. The requests
+// still share the public rate limiter. Non-positive values restore the default of 8.
+func (m *MarketAPI) SetTickerConcurrency(workers int) {
+	if workers <= 0 {
+		workers = defaultTickerWorkers
 	}
+	m.tickerWorkers = workers
+}
+
+// SetAPIVersion selects the API version used by version-independent methods such as GetTicker.
+// The default is APIVersionV2.
+func (m *MarketAPI) SetAPIVersion(version APIVersion) {
+	m.version = version
 }
 
 // ListSymbolsResponse represents the response from list symbols API
@@ -27,100 +72,423 @@ type ListSymbolsResponse []string
 // ListSymbols fetches all available trading symbols from Gemini
 // This implements the public API: https://docs.gemini.com/rest/market-data#list-symbols
 func (m *MarketAPI) ListSymbols(ctx context.Context) (ListSymbolsResponse, error) {
-	url := fmt.Sprintf("%s/v1/symbols", m.gemini.baseURL)
+	url := fmt.Sprintf("%s/v1/symbols", m.gemini.getBaseURL())
 
 	m.gemini.logger.Debug().Str("url", url).Msg("Fetching symbols")
 
 	// This is a public API, no authentication required
 	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch symbols", err)
+		return nil, requestError("failed to fetch symbols", err)
 	}
 
 	var symbols ListSymbolsResponse
-	if err := json.Unmarshal(response, &symbols); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse symbols response", err)
+	if err := jsonUnmarshal(response, &symbols); err != nil {
+		return nil, parseError(response, url, "failed to parse symbols response", err)
 	}
 
+	m.gemini.symbols.putSymbols(symbols)
+
 	m.gemini.logger.Debug().Int("count", len(symbols)).Msg("Successfully fetched symbols")
 	return symbols, nil
 }
 
+// cachedSymbols returns the symbol list from the shared cache, fetching it if stale
+func (m *MarketAPI) cachedSymbols(ctx context.Context) ([]string, error) {
+	if symbols, ok := m.gemini.symbols.getSymbols(); ok {
+		return symbols, nil
+	}
+	return m.ListSymbols(ctx)
+}
+
 // SymbolDetails represents detailed information about a trading symbol
 type SymbolDetails struct {
-	Symbol                string  `json:"symbol"`
-	BaseCurrency          string  `json:"base_currency"`
-	QuoteCurrency         string  `json:"quote_currency"`
-	TickSize              float64 `json:"tick_size"`
-	QuoteIncrement        float64 `json:"quote_increment"`
-	MinOrderSize          string  `json:"min_order_size"`
-	Status                string  `json:"status"`
-	WrapEnabled           bool    `json:"wrap_enabled"`
-	ProductType           string  `json:"product_type"`
-	ContractType          string  `json:"contract_type"`
-	ContractPriceCurrency string  `json:"contract_price_currency"`
+	Symbol                string       `json:"symbol"`
+	BaseCurrency          string       `json:"base_currency"`
+	QuoteCurrency         string       `json:"quote_currency"`
+	TickSize              FlexFloat    `json:"tick_size"`
+	QuoteIncrement        FlexFloat    `json:"quote_increment"`
+	MinOrderSize          string       `json:"min_order_size"`
+	Status                SymbolStatus `json:"status"`
+	WrapEnabled           bool         `json:"wrap_enabled"`
+	ProductType           string       `json:"product_type"`
+	ContractType          string       `json:"contract_type"`
+	ContractPriceCurrency string       `json:"contract_price_currency"`
 }
 
 // GetSymbolDetails fetches detailed information for a specific symbol
 func (m *MarketAPI) GetSymbolDetails(ctx context.Context, symbol string) (*SymbolDetails, error) {
-	url := fmt.Sprintf("%s/v1/symbols/details/%s", m.gemini.baseURL, symbol)
+	url := fmt.Sprintf("%s/v1/symbols/details/%s", m.gemini.getBaseURL(), symbol)
 
 	m.gemini.logger.Debug().Str("url", url).Str("symbol", symbol).Msg("Fetching symbol details")
 
 	// This is a public API, no authentication required
 	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch symbol details", err)
+		return nil, requestError("failed to fetch symbol details", err)
 	}
 
 	var details SymbolDetails
-	if err := json.Unmarshal(response, &details); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse symbol details response", err)
+	if err := jsonUnmarshal(response, &details); err != nil {
+		return nil, parseError(response, url, "failed to parse symbol details response", err)
 	}
 
+	m.gemini.symbols.putDetails(details)
+
 	m.gemini.logger.Debug().Str("symbol", symbol).Msg("Successfully fetched symbol details")
 	return &details, nil
 }
 
-// GetAllSymbolDetails fetches detailed information for all symbols
+// GetSymbolDetailsBatch fetches details for the given symbols, keyed by symbol.
+// Recently fetched details are served from the shared cache; the rest are fetched concurrently,
+// at most SetSymbolDetailsConcurrency at a time, and symbols that fail are omitted.
+// If ctx is done, no further requests are started and the details collected so far are
+// returned together with ctx.Err().
+func (m *MarketAPI) GetSymbolDetailsBatch(ctx context.Context, symbols []string) (map[string]SymbolDetails, error) {
+	result := make(map[string]SymbolDetails, len(symbols))
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	pending := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if details, ok := m.gemini.symbols.getDetails(symbol); ok {
+			result[symbol] = details
+			continue
+		}
+		pending = append(pending, symbol)
+	}
+
+	fetched, errs := forEachBounded(ctx, m.detailsWorkers, pending, func(symbol string) (*SymbolDetails, error) {
+		return m.GetSymbolDetails(ctx, symbol)
+	})
+	for symbol, details := range fetched {
+		result[symbol] = *details
+	}
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	for symbol, err := range errs {
+		m.gemini.logger.Warn().Str("symbol", symbol).Err(err).Msg("Failed to fetch details for symbol")
+	}
+
+	m.gemini.logger.Debug().Int("requested", len(symbols)).Int("count", len(result)).Msg("Successfully fetched symbol details batch")
+	return result, nil
+}
+
+// GetAllSymbolDetails fetches detailed information for all symbols, concurrently as in
+// GetSymbolDetailsBatch. Details are returned in the order of the symbols list.
+// If ctx is done part way through, the details collected so far are returned together with ctx.Err().
 func (m *MarketAPI) GetAllSymbolDetails(ctx context.Context) ([]SymbolDetails, error) {
 	// First get all symbols
-	symbols, err := m.ListSymbols(ctx)
+	symbols, err := m.cachedSymbols(ctx)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch symbols list", err)
+		return nil, requestError("failed to fetch symbols list", err)
 	}
 
-	allDetails := make([]SymbolDetails, 0, len(symbols))
+	detailsMap, err := m.GetSymbolDetailsBatch(ctx, symbols)
+
+	allDetails := make([]SymbolDetails, 0, len(detailsMap))
 	for _, symbol := range symbols {
-		details, err := m.GetSymbolDetails(ctx, symbol)
-		if err != nil {
-			m.gemini.logger.Warn().Str("symbol", symbol).Err(err).Msg("Failed to fetch details for symbol")
-			continue
+		if details, ok := detailsMap[symbol]; ok {
+			allDetails = append(allDetails, details)
 		}
-		allDetails = append(allDetails, *details)
+	}
+	if err != nil {
+		m.gemini.logger.Debug().Int("count", len(allDetails)).Err(err).Msg("Symbol details fetch interrupted")
+		return allDetails, err
 	}
 
 	m.gemini.logger.Debug().Int("count", len(allDetails)).Msg("Successfully fetched all symbol details")
 	return allDetails, nil
 }
 
+// fetchSymbolDetailsList fetches the details of every symbol in one request to
+// /v1/symbols/details and stores them in the shared cache
+func (m *MarketAPI) fetchSymbolDetailsList(ctx context.Context) ([]SymbolDetails, error) {
+	url := fmt.Sprintf("%s/v1/symbols/details", m.gemini.getBaseURL())
+
+	m.gemini.logger.Debug().Str("url", url).Msg("Fetching symbol details list")
+
+	// This is a public API, no authentication required
+	response, err := m.gemini.client.Get(ctx, url)
+	if err != nil {
+		return nil, requestError("failed to fetch symbol details", err)
+	}
+
+	var details []SymbolDetails
+	if err := jsonUnmarshalArray(response, &details); err != nil {
+		return nil, parseError(response, url, "failed to parse symbol details", err)
+	}
+	for _, detail := range details {
+		m.gemini.symbols.putDetails(detail)
+	}
+
+	m.gemini.logger.Debug().Int("count", len(details)).Msg("Successfully fetched symbol details list")
+	return details, nil
+}
+
+// ListActiveSymbols returns the symbols whose status is open, leaving out symbols that are
+// closed or restricted to cancelling, limit or post-only orders. Symbol details are served from
+// the shared cache when available and otherwise fetched in a single request to the bulk
+// details endpoint, as GetTradingPairs does. Symbols that the details endpoint omits are
+// reported in an ErrInvalidResponse error, returned together with the active symbols that
+// could be checked. Use GetTradingPairsFiltered for other statuses.
+func (m *MarketAPI) ListActiveSymbols(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	symbols, err := m.cachedSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range symbols {
+		if _, ok := m.gemini.symbols.getDetails(symbol); !ok {
+			if _, err := m.fetchSymbolDetailsList(ctx); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	active := make([]string, 0, len(symbols))
+	var unknown []string
+	for _, symbol := range symbols {
+		detail, ok := m.gemini.symbols.getDetails(symbol)
+		switch {
+		case !ok:
+			unknown = append(unknown, symbol)
+		case detail.Status == SymbolStatusOpen:
+			active = append(active, strings.ToLower(detail.Symbol))
+		}
+	}
+
+	if len(unknown) > 0 {
+		return active, errors.Newf(errors.ErrInvalidResponse, "symbol details missing for %d of %d symbols", len(unknown), len(symbols)).
+			WithDetails(strings.Join(unknown, ","))
+	}
+
+	m.gemini.logger.Debug().Int("symbols", len(symbols)).Int("active", len(active)).Msg("Successfully listed active symbols")
+	return active, nil
+}
+
+// GetTicker fetches ticker data for a specific symbol from the configured API version,
+// normalized to a Ticker
+func (m *MarketAPI) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	switch m.version {
+	case APIVersionV1:
+		ticker, err := m.GetTickerV1(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return ticker.normalize(symbol), nil
+	case APIVersionV2:
+		ticker, err := m.GetTickerV2(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return ticker.normalize(), nil
+	default:
+		return nil, errors.Newf(errors.ErrInvalidInput, "unsupported ticker API version: %d", m.version)
+	}
+}
+
+// GetTickerV1 fetches v1 ticker data for a specific symbol. Unlike v2, the response
+// breaks the 24 hour volume down per currency.
+// This implements the public API: https://docs.gemini.com/rest/market-data#get-ticker
+func (m *MarketAPI) GetTickerV1(ctx context.Context, symbol string) (*TickerV1, error) {
+	url := fmt.Sprintf("%s/v1/pubticker/%s", m.gemini.getBaseURL(), symbol)
+
+	m.gemini.logger.Debug().Str("url", url).Str("symbol", symbol).Msg("Fetching v1 ticker data")
+
+	// This is a public API, no authentication required
+	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
+	if err != nil {
+		return nil, requestError("failed to fetch ticker data", err)
+	}
+
+	var ticker TickerV1
+	if err := jsonUnmarshal(response, &ticker); err != nil {
+		return nil, parseError(response, url, "failed to parse ticker response", err)
+	}
+
+	m.gemini.logger.Debug().Str("symbol", symbol).Msg("Successfully fetched v1 ticker data")
+	return &ticker, nil
+}
+
 // GetTickerV2 fetches ticker data for a specific symbol
 func (m *MarketAPI) GetTickerV2(ctx context.Context, symbol string) (*TickerV2, error) {
-	url := fmt.Sprintf("%s/v2/ticker/%s", m.gemini.baseURL, symbol)
+	url := fmt.Sprintf("%s/v2/ticker/%s", m.gemini.getBaseURL(), symbol)
 
 	m.gemini.logger.Debug().Str("url", url).Str("symbol", symbol).Msg("Fetching ticker data")
 
 	// This is a public API, no authentication required
 	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch ticker data", err)
+		return nil, requestError("failed to fetch ticker data", err)
 	}
 
 	var ticker TickerV2
-	if err := json.Unmarshal(response, &ticker); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse ticker response", err)
+	if err := jsonUnmarshal(response, &ticker); err != nil {
+		return nil, parseError(response, url, "failed to parse ticker response", err)
 	}
 
 	m.gemini.logger.Debug().Str("symbol", symbol).Msg("Successfully fetched ticker data")
 	return &ticker, nil
 }
+
+// GetTickersV2 fetches the v2 ticker of each symbol, at most SetTickerConcurrency at a time.
+// Results and per-symbol errors are keyed by symbol; all requests share the public rate limiter.
+// If ctx is done, no further requests are started and the symbols not fetched get ctx.Err().
+func (m *MarketAPI) GetTickersV2(ctx context.Context, symbols []string) (map[string]*TickerV2, map[string]error) {
+	results, errs := forEachBounded(ctx, m.tickerWorkers, symbols, func(symbol string) (*TickerV2, error) {
+		return m.GetTickerV2(ctx, symbol)
+	})
+
+	m.gemini.logger.Debug().Int("symbols", len(results)+len(errs)).Int("failed", len(errs)).Msg("Fetched tickers for symbols")
+	return results, errs
+}
+
+// GetFeePromos fetches the symbols currently enjoying promotional fees
+func (m *MarketAPI) GetFeePromos(ctx context.Context) (*FeePromos, error) {
+	url := fmt.Sprintf("%s/v1/feepromos", m.gemini.getBaseURL())
+
+	m.gemini.logger.Debug().Str("url", url).Msg("Fetching fee promos")
+
+	// This is a public API, no authentication required
+	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
+	if err != nil {
+		return nil, requestError("failed to fetch fee promos", err)
+	}
+
+	var promos FeePromos
+	if err := jsonUnmarshal(response, &promos); err != nil {
+		return nil, parseError(response, url, "failed to parse fee promos response", err)
+	}
+
+	m.gemini.logger.Debug().Int("count", len(promos.Symbols)).Msg("Successfully fetched fee promos")
+	return &promos, nil
+}
+
+// TickerDetail selects how much ticker data GetAllTickers fetches
+type TickerDetail int
+
+const (
+	// TickerDetailPrice fetches every symbol's last price and 24 hour change with a single
+	// price feed request. Bid, ask, open, high, low and volume are left empty.
+	TickerDetailPrice TickerDetail = iota
+	// TickerDetailFull fetches each symbol's ticker from the configured API version, one
+	// request per symbol. Bid, ask and last are set; PercentChange24h is left empty.
+	TickerDetailFull
+)
+
+// GetPriceFeed fetches the last price and 24 hour change of every trading pair
+// This implements the public API: https://docs.gemini.com/rest/market-data#list-prices
+func (m *MarketAPI) GetPriceFeed(ctx context.Context) ([]PriceFeedEntry, error) {
+	url := fmt.Sprintf("%s/v1/pricefeed", m.gemini.getBaseURL())
+
+	m.gemini.logger.Debug().Str("url", url).Msg("Fetching price feed")
+
+	// This is a public API, no authentication required
+	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
+	if err != nil {
+		return nil, requestError("failed to fetch price feed", err)
+	}
+
+	var prices []PriceFeedEntry
+	if err := jsonUnmarshal(response, &prices); err != nil {
+		return nil, parseError(response, url, "failed to parse price feed response", err)
+	}
+
+	m.gemini.logger.Debug().Int("count", len(prices)).Msg("Successfully fetched price feed")
+	return prices, nil
+}
+
+// GetAllTickers fetches tickers for every symbol. TickerDetailPrice makes one price feed request
+// and fills only Symbol, Last and PercentChange24h, which is enough for price dashboards.
+// TickerDetailFull fetches each symbol's ticker for bid and ask as well, at one request per
+// symbol; symbols that fail are omitted. If ctx is done part way through, the tickers collected
+// so far are returned together with ctx.Err().
+func (m *MarketAPI) GetAllTickers(ctx context.Context, detail TickerDetail) ([]Ticker, error) {
+	switch detail {
+	case TickerDetailPrice:
+		return m.priceFeedTickers(ctx)
+	case TickerDetailFull:
+		return m.fullTickers(ctx)
+	default:
+		return nil, errors.Newf(errors.ErrInvalidInput, "unsupported ticker detail: %d", detail)
+	}
+}
+
+// priceFeedTickers converts the price feed to tickers
+func (m *MarketAPI) priceFeedTickers(ctx context.Context) ([]Ticker, error) {
+	prices, err := m.GetPriceFeed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]Ticker, 0, len(prices))
+	for _, price := range prices {
+		tickers = append(tickers, Ticker{
+			Symbol:           price.Pair,
+			Last:             price.Price,
+			PercentChange24h: price.PercentChange24h,
+		})
+	}
+	return tickers, nil
+}
+
+// fullTickers fetches the ticker of every symbol in turn
+func (m *MarketAPI) fullTickers(ctx context.Context) ([]Ticker, error) {
+	symbols, err := m.cachedSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make([]Ticker, 0, len(symbols))
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return tickers, err
+		}
+
+		ticker, err := m.GetTicker(ctx, symbol)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return tickers, ctxErr
+			}
+			m.gemini.logger.Warn().Str("symbol", symbol).Err(err).Msg("Failed to fetch ticker for symbol")
+			continue
+		}
+		tickers = append(tickers, *ticker)
+	}
+
+	m.gemini.logger.Debug().Int("requested", len(symbols)).Int("count", len(tickers)).Msg("Successfully fetched all tickers")
+	return tickers, nil
+}
+
+// normalize converts a v1 ticker to a Ticker. v1 responses do not include the symbol,
+// so it is taken from the request; volumes are looked up by the symbol's currencies.
+func (t *TickerV1) normalize(symbol string) *Ticker {
+	return &Ticker{
+		Symbol:      strings.ToUpper(symbol),
+		Bid:         t.Bid,
+		Ask:         t.Ask,
+		Last:        t.Last,
+		Volume:      t.Volume.Get(extractBaseCurrency(symbol)),
+		QuoteVolume: t.Volume.Get(extractQuoteCurrency(symbol)),
+	}
+}
+
+// normalize converts a v2 ticker to a Ticker
+func (t *TickerV2) normalize() *Ticker {
+	return &Ticker{
+		Symbol: t.Symbol,
+		Bid:    t.Bid,
+		Ask:    t.Ask,
+		Last:   t.Close,
+		Open:   t.Open,
+		High:   t.High,
+		Low:    t.Low,
+	}
+}
@@ -2,15 +2,36 @@ package gemini
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/testutil"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// fixturesDir holds API responses recorded from the sandbox. The committed fixtures were
+// seeded from the Gemini API documentation examples; rerun the tests with CEXSDK_RECORD=1
+// to record them from the live sandbox instead.
+const fixturesDir = "testdata/fixtures"
+
+// newFixtureGemini creates a Gemini instance that replays recorded responses from
+// fixturesDir, or records them from the sandbox when CEXSDK_RECORD is set
+func newFixtureGemini(t *testing.T, config *exchange.Config) *Gemini {
+	t.Helper()
+	g := NewGemini(config)
+	require.NoError(t, g.SetBaseURLs([]string{testutil.NewServer(t, fixturesDir, baseURLSandbox)}))
+	return g
+}
+
 func TestMarketAPI_ListSymbols(t *testing.T) {
 	// Create a test configuration
 	config := &exchange.Config{
@@ -19,8 +40,8 @@ func TestMarketAPI_ListSymbols(t *testing.T) {
 		Logger:  &zerolog.Logger{},
 	}
 
-	// Create Gemini instance
-	gemini := NewGemini(config)
+	// Create Gemini instance serving recorded sandbox responses
+	gemini := newFixtureGemini(t, config)
 	require.NotNil(t, gemini)
 	require.NotNil(t, gemini.Market)
 
@@ -58,8 +79,8 @@ func TestMarketAPI_GetSymbolDetails(t *testing.T) {
 		Logger:  &zerolog.Logger{},
 	}
 
-	// Create Gemini instance
-	gemini := NewGemini(config)
+	// Create Gemini instance serving recorded sandbox responses
+	gemini := newFixtureGemini(t, config)
 	require.NotNil(t, gemini)
 	require.NotNil(t, gemini.Market)
 
@@ -74,7 +95,7 @@ func TestMarketAPI_GetSymbolDetails(t *testing.T) {
 	assert.NotEmpty(t, details.BaseCurrency, "BaseCurrency should not be empty")
 	assert.NotEmpty(t, details.QuoteCurrency, "QuoteCurrency should not be empty")
 	assert.NotEmpty(t, details.Status, "Status should not be empty")
-	assert.GreaterOrEqual(t, details.TickSize, 0.0, "TickSize should be non-negative")
+	assert.GreaterOrEqual(t, float64(details.TickSize), 0.0, "TickSize should be non-negative")
 	assert.NotEmpty(t, details.ProductType, "ProductType should not be empty")
 
 	t.Logf("Symbol details for BTCUSD: %+v", details)
@@ -88,8 +109,8 @@ func TestMarketAPI_GetTickerV2(t *testing.T) {
 		Logger:  &zerolog.Logger{},
 	}
 
-	// Create Gemini instance
-	gemini := NewGemini(config)
+	// Create Gemini instance serving recorded sandbox responses
+	gemini := newFixtureGemini(t, config)
 	require.NotNil(t, gemini)
 	require.NotNil(t, gemini.Market)
 
@@ -107,6 +128,320 @@ func TestMarketAPI_GetTickerV2(t *testing.T) {
 	t.Logf("Ticker for BTCUSD: %+v", ticker)
 }
 
+func TestMarketAPI_GetTickersV2(t *testing.T) {
+	var inflight, peak int32
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			previous := atomic.LoadInt32(&peak)
+			if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		symbol := strings.TrimPrefix(r.URL.Path, "/v2/ticker/")
+		if symbol == "badusd" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"symbol":%q,"bid":"1.00","ask":"1.01"}`, strings.ToUpper(symbol))
+	})
+
+	symbols := []string{"badusd", "btcusd", "btcusd"}
+	for i := 0; i < 20; i++ {
+		symbols = append(symbols, fmt.Sprintf("c%dusd", i))
+	}
+	tickers, errs := g.Market.GetTickersV2(context.Background(), symbols)
+
+	assert.Len(t, tickers, 21)
+	assert.Equal(t, "BTCUSD", tickers["btcusd"].Symbol)
+	assert.Equal(t, "C7USD", tickers["c7usd"].Symbol)
+	require.Len(t, errs, 1)
+	assert.Error(t, errs["badusd"])
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(defaultTickerWorkers))
+
+	atomic.StoreInt32(&peak, 0)
+	g.Market.SetTickerConcurrency(2)
+	tickers, errs = g.Market.GetTickersV2(context.Background(), symbols[3:])
+	assert.Len(t, tickers, 20)
+	assert.Empty(t, errs)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tickers, errs = g.Market.GetTickersV2(ctx, []string{"btcusd", "ethusd", "btcusd"})
+	assert.Empty(t, tickers)
+	require.Len(t, errs, 2)
+	assert.ErrorIs(t, errs["btcusd"], context.Canceled)
+	assert.ErrorIs(t, errs["ethusd"], context.Canceled)
+}
+
+func TestMarketAPI_GetTicker_Versions(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/pubticker/btcusd":
+			_, _ = w.Write([]byte(`{"bid":"29999.00","ask":"30001.00","last":"30000.00","volume":{"BTC":"1200.5","USD":"36015000.00","timestamp":1700000000000}}`))
+		case "/v2/ticker/btcusd":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSD","open":"29500.00","high":"30500.00","low":"29000.00","close":"30000.00","changes":[],"bid":"29999.00","ask":"30001.00"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	// v2 is the default
+	ticker, err := g.Market.GetTicker(ctx, "btcusd")
+	require.NoError(t, err)
+	assert.Equal(t, "BTCUSD", ticker.Symbol)
+	assert.Equal(t, "30000.00", ticker.Last)
+	assert.Equal(t, "29500.00", ticker.Open)
+	assert.Empty(t, ticker.Volume)
+
+	g.Market.SetAPIVersion(APIVersionV1)
+	ticker, err = g.Market.GetTicker(ctx, "btcusd")
+	require.NoError(t, err)
+	assert.Equal(t, "BTCUSD", ticker.Symbol)
+	assert.Equal(t, "30000.00", ticker.Last)
+	assert.Equal(t, "29999.00", ticker.Bid)
+	assert.Equal(t, "1200.5", ticker.Volume)
+	assert.Equal(t, "36015000.00", ticker.QuoteVolume)
+	assert.Empty(t, ticker.Open)
+
+	g.Market.SetAPIVersion(APIVersion(3))
+	_, err = g.Market.GetTicker(ctx, "btcusd")
+	assert.Error(t, err)
+}
+
+// tickerV1Fixture is a /v1/pubticker/btcusd response captured from the Gemini API docs
+const tickerV1Fixture = `{
+	"ask": "977.59",
+	"bid": "977.35",
+	"last": "977.65",
+	"volume": {
+		"BTC": "2210.505328803",
+		"USD": "2135477.463379586263",
+		"timestamp": 1483018200000
+	}
+}`
+
+func TestMarketAPI_GetTickerV1(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/pubticker/btcusd", r.URL.Path)
+		_, _ = w.Write([]byte(tickerV1Fixture))
+	})
+
+	ticker, err := g.Market.GetTickerV1(context.Background(), "btcusd")
+	require.NoError(t, err)
+	assert.Equal(t, "977.35", ticker.Bid)
+	assert.Equal(t, "977.59", ticker.Ask)
+	assert.Equal(t, "977.65", ticker.Last)
+	assert.Equal(t, map[string]string{"BTC": "2210.505328803", "USD": "2135477.463379586263"}, ticker.Volume.Currencies)
+	assert.Equal(t, FlexInt(1483018200000), ticker.Volume.Timestamp)
+	assert.Equal(t, "2210.505328803", ticker.Volume.Get("btc"))
+
+	encoded, err := json.Marshal(ticker.Volume)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"BTC":"2210.505328803","USD":"2135477.463379586263","timestamp":1483018200000}`, string(encoded))
+}
+
+func TestMarketAPI_GetFeePromos(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/feepromos", r.URL.Path)
+		_, _ = w.Write([]byte(`{"symbols":["GUSDUSD","USDCUSD"]}`))
+	})
+
+	promos, err := g.Market.GetFeePromos(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GUSDUSD", "USDCUSD"}, promos.Symbols)
+}
+
+func TestMarketAPI_GetAllTickers(t *testing.T) {
+	var paths []string
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/v1/pricefeed":
+			_, _ = w.Write([]byte(`[{"pair":"BTCUSD","price":"9500.00","percentChange24h":"5.23"},{"pair":"ETHUSD","price":"250.10","percentChange24h":"-1.10"}]`))
+		case "/v1/symbols":
+			_, _ = w.Write([]byte(`["btcusd","ethusd"]`))
+		case "/v2/ticker/btcusd":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSD","open":"9000","close":"9500","bid":"9499","ask":"9501"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	tickers, err := g.Market.GetAllTickers(ctx, TickerDetailPrice)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1/pricefeed"}, paths)
+	require.Len(t, tickers, 2)
+	assert.Equal(t, Ticker{Symbol: "BTCUSD", Last: "9500.00", PercentChange24h: "5.23"}, tickers[0])
+	assert.Equal(t, "-1.10", tickers[1].PercentChange24h)
+
+	// Full detail fetches each ticker and skips symbols that fail
+	paths = nil
+	tickers, err = g.Market.GetAllTickers(ctx, TickerDetailFull)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1/symbols", "/v2/ticker/btcusd", "/v2/ticker/ethusd"}, paths)
+	require.Len(t, tickers, 1)
+	assert.Equal(t, "9499", tickers[0].Bid)
+	assert.Equal(t, "9501", tickers[0].Ask)
+	assert.Equal(t, "9500", tickers[0].Last)
+	assert.Empty(t, tickers[0].PercentChange24h)
+
+	_, err = g.Market.GetAllTickers(ctx, TickerDetail(7))
+	assert.Error(t, err)
+}
+
+func TestMarketAPI_GetSymbolDetailsBatch_Cached(t *testing.T) {
+	gemini := NewGemini(nil)
+	gemini.symbols.putDetails(SymbolDetails{Symbol: "BTCUSD", BaseCurrency: "BTC", QuoteCurrency: "USD"})
+	gemini.symbols.putDetails(SymbolDetails{Symbol: "ETHUSD", BaseCurrency: "ETH", QuoteCurrency: "USD"})
+
+	// Cached details are served without any network request
+	details, err := gemini.Market.GetSymbolDetailsBatch(context.Background(), []string{"btcusd", "ethusd"})
+	require.NoError(t, err)
+	require.Len(t, details, 2)
+	assert.Equal(t, "BTC", details["btcusd"].BaseCurrency)
+	assert.Equal(t, "ETH", details["ethusd"].BaseCurrency)
+}
+
+func TestMarketAPI_GetAllSymbolDetails_Cancelled(t *testing.T) {
+	gemini := NewGemini(nil)
+	gemini.symbols.putSymbols([]string{"btcusd", "ethusd"})
+	gemini.symbols.putDetails(SymbolDetails{Symbol: "BTCUSD"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A cancelled context stops the crawl before any request is sent
+	details, err := gemini.Market.GetAllSymbolDetails(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, details)
+}
+
+func TestMarketAPI_ListActiveSymbols(t *testing.T) {
+	var fixture []SymbolDetails
+	require.NoError(t, json.Unmarshal([]byte(symbolDetailsFixture), &fixture))
+
+	// Cached details with mixed statuses are served without any network request
+	gemini := NewGemini(nil)
+	symbols := make([]string, 0, len(fixture))
+	for _, detail := range fixture {
+		symbols = append(symbols, strings.ToLower(detail.Symbol))
+		gemini.symbols.putDetails(detail)
+	}
+	gemini.symbols.putSymbols(symbols)
+
+	active, err := gemini.Market.ListActiveSymbols(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"btcusd", "ethusd"}, active)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = gemini.Market.ListActiveSymbols(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMarketAPI_ListActiveSymbols_BulkDetails(t *testing.T) {
+	var paths []string
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/v1/symbols":
+			_, _ = w.Write([]byte(`["btcusd","ethusd","ltcusd","zecusd","dogeusd","newusd"]`))
+		case "/v1/symbols/details":
+			_, _ = w.Write([]byte(symbolDetailsFixture))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	// Details come from one bulk request; a symbol it omits is reported, not dropped
+	active, err := g.Market.ListActiveSymbols(context.Background())
+	assert.Equal(t, []string{"/v1/symbols", "/v1/symbols/details"}, paths)
+	assert.Equal(t, []string{"btcusd", "ethusd"}, active)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "newusd")
+}
+
+// symbolDetailsServer serves symbol details for any symbol after delay, tracking how many
+// requests are in flight at once
+func symbolDetailsServer(t *testing.T, delay time.Duration, inFlight, maxInFlight, served *atomic.Int32) *Gemini {
+	t.Helper()
+	return newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		served.Add(1)
+		symbol := strings.TrimPrefix(r.URL.Path, "/v1/symbols/details/")
+		_, _ = fmt.Fprintf(w, `{"symbol":%q,"base_currency":"BTC","quote_currency":"USD","status":"open"}`, strings.ToUpper(symbol))
+	})
+}
+
+func TestMarketAPI_GetAllSymbolDetails_Concurrent(t *testing.T) {
+	symbols := make([]string, 40)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("sym%dusd", i)
+	}
+
+	var inFlight, maxInFlight, served atomic.Int32
+	gemini := symbolDetailsServer(t, 20*time.Millisecond, &inFlight, &maxInFlight, &served)
+	gemini.symbols.putSymbols(symbols)
+	gemini.Market.SetSymbolDetailsConcurrency(5)
+
+	// Serially the 40 requests would take at least 800ms
+	start := time.Now()
+	details, err := gemini.Market.GetAllSymbolDetails(context.Background())
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	require.Len(t, details, len(symbols))
+	for i, detail := range details {
+		assert.Equal(t, strings.ToUpper(symbols[i]), detail.Symbol)
+	}
+	assert.Equal(t, int32(5), maxInFlight.Load())
+	assert.Less(t, elapsed, 600*time.Millisecond)
+	t.Logf("fetched %d symbol details in %v with 5 workers", len(details), elapsed)
+}
+
+func TestMarketAPI_GetAllSymbolDetails_CancelledMidway(t *testing.T) {
+	symbols := make([]string, 100)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("sym%dusd", i)
+	}
+
+	var inFlight, maxInFlight, served atomic.Int32
+	gemini := symbolDetailsServer(t, 50*time.Millisecond, &inFlight, &maxInFlight, &served)
+	gemini.symbols.putSymbols(symbols)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+
+	// Cancellation stops new requests and returns the partial result promptly
+	start := time.Now()
+	details, err := gemini.Market.GetAllSymbolDetails(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.NotEmpty(t, details)
+	assert.Less(t, len(details), len(symbols))
+	assert.LessOrEqual(t, int32(len(details)), served.Load())
+}
+
 // Helper function for min (Go 1.21+)
 func min(a, b int) int {
 	if a < b {
@@ -0,0 +1,236 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+const (
+	// marketDataReadTimeout is how long the stream waits for a message before reconnecting.
+	// Heartbeats are requested, so a healthy connection delivers a message every few seconds.
+	marketDataReadTimeout = 30 * time.Second
+	// marketDataReconnectBase and marketDataReconnectMax bound the default delay between reconnect attempts
+	marketDataReconnectBase = 100 * time.Millisecond
+	marketDataReconnectMax  = 30 * time.Second
+	// marketDataBufferSize is the number of updates buffered for a slow consumer
+	marketDataBufferSize = 256
+)
+
+// MarketDataEvent represents a single event of a v1 market data update.
+// Type is "change" for order book changes, "trade" for trades, or one of the
+// auction event types ("auction_open", "auction_indicative", "auction_result").
+type MarketDataEvent struct {
+	Type      string  `json:"type"`
+	Side      string  `json:"side,omitempty"`
+	Price     string  `json:"price,omitempty"`
+	Remaining string  `json:"remaining,omitempty"`
+	Delta     string  `json:"delta,omitempty"`
+	Reason    string  `json:"reason,omitempty"`
+	TID       FlexInt `json:"tid,omitempty"`
+	Amount    string  `json:"amount,omitempty"`
+	MakerSide string  `json:"makerSide,omitempty"`
+
+	// Auction events
+	AuctionPrice       string  `json:"auction_price,omitempty"`
+	AuctionQuantity    string  `json:"auction_quantity,omitempty"`
+	Result             string  `json:"result,omitempty"`
+	HighestBidPrice    string  `json:"highest_bid_price,omitempty"`
+	LowestAskPrice     string  `json:"lowest_ask_price,omitempty"`
+	CollarPrice        string  `json:"collar_price,omitempty"`
+	IndicativePrice    string  `json:"indicative_price,omitempty"`
+	IndicativeQuantity string  `json:"indicative_quantity,omitempty"`
+	EID                FlexInt `json:"eid,omitempty"`
+}
+
+// MarketDataUpdate represents a message of the v1 single symbol market data feed
+type MarketDataUpdate struct {
+	Type           string            `json:"type"`
+	EventID        FlexInt           `json:"eventId"`
+	Timestamp      FlexInt           `json:"timestamp"`   // Time of the update in seconds
+	Timestampms    FlexInt           `json:"timestampms"` // Time of the update in milliseconds
+	SocketSequence int64             `json:"socket_sequence"`
+	Events         []MarketDataEvent `json:"events"`
+}
+
+// Time returns the time of the update
+func (u *MarketDataUpdate) Time() time.Time {
+	return timestampTime(u.Timestampms, int64(u.Timestamp))
+}
+
+// MarketDataStream delivers updates from the v1 single symbol market data feed
+type MarketDataStream struct {
+	updates    chan MarketDataUpdate
+	cancel     context.CancelFunc
+	connCancel context.CancelFunc // closes the current connection
+	done       chan struct{}
+	err        error
+	mu         sync.Mutex
+}
+
+// Updates returns the channel of market data updates. It is closed when the stream ends.
+func (s *MarketDataStream) Updates() <-chan MarketDataUpdate {
+	return s.updates
+}
+
+// Err returns the reason the stream ended, once Updates is closed
+func (s *MarketDataStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close stops the stream and waits for its connection to close
+func (s *MarketDataStream) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// Resync drops the current connection so that the stream reconnects and delivers a fresh
+// order book snapshot. It has no effect while the stream is already reconnecting.
+func (s *MarketDataStream) Resync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.connCancel != nil {
+		s.connCancel()
+	}
+}
+
+// setConnCancel records how to close the current connection
+func (s *MarketDataStream) setConnCancel(cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connCancel = cancel
+}
+
+// setErr records the reason the stream ended
+func (s *MarketDataStream) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// SingleSymbolStream subscribes to the v1 market data feed for a single symbol.
+//
+// Only "update" messages are delivered; heartbeats are consumed internally. The feed's
+// socket_sequence is checked on every message, and the stream reconnects on a gap, a read
+// error or a silent connection. Each connection starts with a snapshot of the order book
+// (change events with reason "initial"), so consumers maintaining a book should rebuild it
+// when they see one. Reconnect attempts are spaced by the strategy set with
+// Gemini.SetReconnectBackoff. The stream runs until ctx is done or Close is called.
+func (m *MarketAPI) SingleSymbolStream(ctx context.Context, symbol string) (*MarketDataStream, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+
+	url := fmt.Sprintf("%s/v1/marketdata/%s?heartbeat=true", websocketBaseURL(m.gemini.getBaseURL()), strings.ToLower(symbol))
+	headers := map[string]string{"User-Agent": m.gemini.userAgent}
+
+	m.gemini.logger.Debug().Str("url", url).Str("symbol", symbol).Msg("Connecting market data stream")
+
+	conn, err := client.DialWebSocket(ctx, url, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream := &MarketDataStream{
+		updates: make(chan MarketDataUpdate, marketDataBufferSize),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go m.runMarketDataStream(streamCtx, stream, conn, url, headers)
+
+	m.gemini.logger.Debug().Str("symbol", symbol).Msg("Market data stream connected")
+	return stream, nil
+}
+
+// runMarketDataStream consumes conn and reconnects until ctx is done
+func (m *MarketAPI) runMarketDataStream(ctx context.Context, stream *MarketDataStream, conn *client.WebSocketConn, url string, headers map[string]string) {
+	defer close(stream.done)
+	defer close(stream.updates)
+
+	backoff := client.NewBackoffWithStrategy(m.gemini.reconnectStrategy())
+	for {
+		connCtx, cancelConn := context.WithCancel(ctx)
+		stream.setConnCancel(cancelConn)
+		err := consumeMarketData(connCtx, conn, stream.updates)
+		cancelConn()
+		if ctx.Err() != nil {
+			stream.setErr(ctx.Err())
+			return
+		}
+		m.gemini.logger.Warn().Str("url", url).Err(err).Msg("Market data stream interrupted, reconnecting")
+
+		for {
+			select {
+			case <-ctx.Done():
+				stream.setErr(ctx.Err())
+				return
+			case <-time.After(backoff.Next()):
+			}
+
+			conn, err = client.DialWebSocket(ctx, url, headers)
+			if err == nil {
+				backoff.Reset()
+				break
+			}
+			m.gemini.logger.Warn().Str("url", url).Err(err).Msg("Market data stream reconnect failed")
+		}
+	}
+}
+
+// consumeMarketData forwards updates from conn until the connection fails, the socket
+// sequence has a gap, or ctx is done. The connection is closed on return.
+func consumeMarketData(ctx context.Context, conn *client.WebSocketConn, updates chan<- MarketDataUpdate) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	expected := int64(0)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(marketDataReadTimeout)); err != nil {
+			return err
+		}
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var update MarketDataUpdate
+		if err := jsonUnmarshal(message, &update); err != nil {
+			return errors.Wrap(errors.ErrDataParsingError, "failed to parse market data message", err)
+		}
+		if update.SocketSequence != expected {
+			return errors.Newf(errors.ErrInvalidResponse, "market data sequence gap: expected %d, got %d", expected, update.SocketSequence)
+		}
+		expected++
+
+		if update.Type != "update" {
+			continue
+		}
+		select {
+		case updates <- update:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// websocketBaseURL converts an HTTP(S) base URL to the matching WS(S) URL
+func websocketBaseURL(baseURL string) string {
+	switch {
+	case strings.HasPrefix(baseURL, "https://"):
+		return "wss://" + strings.TrimPrefix(baseURL, "https://")
+	case strings.HasPrefix(baseURL, "http://"):
+		return "ws://" + strings.TrimPrefix(baseURL, "http://")
+	default:
+		return baseURL
+	}
+}
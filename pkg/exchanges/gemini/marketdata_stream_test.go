@@ -0,0 +1,117 @@
+package gemini
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 -- test WebSocket handshake
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestWebSocketText writes an unmasked text frame, as sent by a server
+func writeTestWebSocketText(w io.Writer, message string) {
+	frame := []byte{0x81}
+	if len(message) < 126 {
+		frame = append(frame, byte(len(message)))
+	} else {
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(message)))
+	}
+	_, _ = w.Write(append(frame, message...))
+}
+
+// recordingStrategy returns no delay and counts the attempts it was asked for
+type recordingStrategy struct {
+	calls atomic.Int32
+}
+
+func (r *recordingStrategy) NextDelay(attempt int) time.Duration {
+	r.calls.Add(1)
+	return 0
+}
+
+func TestMarketAPI_SingleSymbolStream(t *testing.T) {
+	var connections atomic.Int32
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/marketdata/btcusd", r.URL.Path)
+		h := sha1.New() // #nosec G401 -- test WebSocket handshake
+		h.Write([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		accept := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept + "\r\n\r\n")
+
+		var messages []string
+		if connections.Add(1) == 1 {
+			// The first connection skips sequence 2, forcing a reconnect
+			messages = []string{
+				`{"type":"update","eventId":1,"socket_sequence":0,"events":[{"type":"change","side":"bid","price":"100","remaining":"1","delta":"1","reason":"initial"}]}`,
+				`{"type":"heartbeat","socket_sequence":1}`,
+				`{"type":"update","eventId":3,"socket_sequence":3,"events":[]}`,
+			}
+		} else {
+			messages = []string{
+				`{"type":"update","eventId":4,"socket_sequence":0,"events":[{"type":"change","side":"ask","price":"101","remaining":"2","delta":"2","reason":"initial"}]}`,
+				`{"type":"update","eventId":5,"timestampms":1700000000000,"socket_sequence":1,"events":[{"type":"trade","tid":5,"price":"101","amount":"0.5","makerSide":"ask"}]}`,
+			}
+		}
+		for _, message := range messages {
+			writeTestWebSocketText(rw, message)
+		}
+		_ = rw.Flush()
+
+		// Hold the connection open until the client goes away
+		_, _ = rw.ReadByte()
+	})
+
+	strategy := &recordingStrategy{}
+	g.SetReconnectBackoff(strategy)
+
+	stream, err := g.Market.SingleSymbolStream(context.Background(), "BTCUSD")
+	require.NoError(t, err)
+
+	var received []MarketDataUpdate
+	timeout := time.After(5 * time.Second)
+	for len(received) < 3 {
+		select {
+		case update := <-stream.Updates():
+			received = append(received, update)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for updates, got %d", len(received))
+		}
+	}
+	require.NoError(t, stream.Close())
+	assert.Equal(t, int32(1), strategy.calls.Load())
+
+	assert.Equal(t, FlexInt(1), received[0].EventID)
+	assert.Equal(t, "initial", received[1].Events[0].Reason)
+	assert.Equal(t, FlexInt(4), received[1].EventID)
+	assert.Equal(t, "trade", received[2].Events[0].Type)
+	assert.Equal(t, "ask", received[2].Events[0].MakerSide)
+	assert.Equal(t, int32(2), connections.Load())
+
+	// The updates channel is closed once the stream has stopped
+	for range stream.Updates() {
+	}
+	assert.ErrorIs(t, stream.Err(), context.Canceled)
+}
+
+func TestMarketAPI_SingleSymbolStream_InvalidSymbol(t *testing.T) {
+	g := NewGemini(nil)
+	_, err := g.Market.SingleSymbolStream(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestWebsocketBaseURL(t *testing.T) {
+	assert.Equal(t, "wss://api.gemini.com", websocketBaseURL("https://api.gemini.com"))
+	assert.Equal(t, "ws://127.0.0.1:8080", websocketBaseURL("http://127.0.0.1:8080"))
+}
@@ -0,0 +1,86 @@
+package gemini
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// NonceManager generates nonces for private API requests.
+// Nonces must be strictly increasing for a given API key.
+type NonceManager interface {
+	Next() string
+}
+
+// nonceContextKey is the context key for a nonce pinned by ContextWithNonce
+type nonceContextKey struct{}
+
+// ContextWithNonce returns a context that makes private requests sign with nonce instead of a new one.
+//
+// This makes retries of mutating requests safe after an ambiguous failure such as a timeout:
+// reserve a nonce with Gemini.ReserveNonce, and send every attempt of the same operation with it.
+// If an earlier attempt did reach Gemini, the retry is rejected as a reused nonce rather than
+// executed twice, and the caller can then look up the outcome (for example by client order ID).
+// A pinned nonce that has fallen behind newer requests is rejected the same way.
+// Only pin a nonce for calls that send a single private request.
+func ContextWithNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, nonceContextKey{}, nonce)
+}
+
+// nonceFromContext returns the nonce pinned on ctx, if any
+func nonceFromContext(ctx context.Context) (string, bool) {
+	nonce, ok := ctx.Value(nonceContextKey{}).(string)
+	return nonce, ok && nonce != ""
+}
+
+// IsNonceError reports whether err means Gemini rejected the request's nonce, for example
+// because another process using the same API key sent a larger one. Gemini.SetNonceJump
+// recovers from such collisions automatically.
+func IsNonceError(err error) bool {
+	return errors.GetCode(err) == errors.ErrInvalidNonce
+}
+
+// NonceSource generates nonces for private API requests from a source that can fail,
+// such as a counter shared between processes through Redis or a file.
+// Nonces must be strictly increasing for a given API key.
+type NonceSource interface {
+	Next() (string, error)
+}
+
+// managerNonceSource adapts a NonceManager to a NonceSource
+type managerNonceSource struct {
+	manager NonceManager
+}
+
+// Next returns the next nonce from the wrapped manager
+func (s managerNonceSource) Next() (string, error) {
+	return s.manager.Next(), nil
+}
+
+// TimeNonceManager generates nanosecond timestamp nonces that are strictly
+// increasing even when requests are signed concurrently
+type TimeNonceManager struct {
+	last int64
+	mu   sync.Mutex
+}
+
+// NewTimeNonceManager creates a timestamp based nonce manager
+func NewTimeNonceManager() *TimeNonceManager {
+	return &TimeNonceManager{}
+}
+
+// Next returns the next nonce
+func (n *TimeNonceManager) Next() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	nonce := time.Now().UnixNano()
+	if nonce <= n.last {
+		nonce = n.last + 1
+	}
+	n.last = nonce
+	return strconv.FormatInt(nonce, 10)
+}
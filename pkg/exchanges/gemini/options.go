@@ -0,0 +1,123 @@
+package gemini
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/backoff"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/rs/zerolog"
+)
+
+// Option configures a Gemini instance created with NewGeminiWithOptions
+type Option func(*Gemini)
+
+// NewGeminiWithOptions creates a new Gemini exchange instance configured by functional options.
+// It starts from the same defaults as NewGemini with an empty config.
+func NewGeminiWithOptions(opts ...Option) *Gemini {
+	g := NewGemini(&exchange.Config{})
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithCredentials sets the API key and secret
+func WithCredentials(apiKey, apiSecret string) Option {
+	return func(g *Gemini) {
+		g.SetAPICredentials(apiKey, apiSecret)
+	}
+}
+
+// WithSandboxCredentials sets the API key and secret used in sandbox mode
+func WithSandboxCredentials(apiKey, apiSecret string) Option {
+	return func(g *Gemini) {
+		g.SetSandboxAPICredentials(apiKey, apiSecret)
+	}
+}
+
+// WithSigner signs private requests with signer instead of the API secret. See Gemini.SetSigner.
+func WithSigner(signer Signer) Option {
+	return func(g *Gemini) {
+		g.SetSigner(signer)
+	}
+}
+
+// WithSandbox selects the sandbox or production environment
+func WithSandbox(sandbox bool) Option {
+	return func(g *Gemini) {
+		g.SetSandbox(sandbox)
+	}
+}
+
+// WithLogger sets a custom logger
+func WithLogger(logger zerolog.Logger) Option {
+	return func(g *Gemini) {
+		g.SetLogger(logger)
+	}
+}
+
+// WithRateLimit sets the rate limit for an API type
+func WithRateLimit(apiType exchange.APIType, requests int, interval time.Duration) Option {
+	return func(g *Gemini) {
+		g.SetRateLimit(apiType, exchange.RateLimit{Requests: requests, Interval: interval})
+	}
+}
+
+// WithSharedRateLimiter makes the instance draw from rate limiters shared with other instances
+// using the same API key. See Gemini.SetSharedRateLimiter.
+func WithSharedRateLimiter(public, private *client.RateLimiter) Option {
+	return func(g *Gemini) {
+		g.SetSharedRateLimiter(public, private)
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client
+func WithHTTPClient(client *http.Client) Option {
+	return func(g *Gemini) {
+		g.SetHTTPClient(client)
+	}
+}
+
+// WithNonceManager sets the nonce generator used to sign private requests
+func WithNonceManager(nonces NonceManager) Option {
+	return func(g *Gemini) {
+		g.SetNonceManager(nonces)
+	}
+}
+
+// WithNonceSource sets a fallible nonce source used to sign private requests
+func WithNonceSource(src NonceSource) Option {
+	return func(g *Gemini) {
+		g.SetNonceSource(src)
+	}
+}
+
+// WithRequestObserver sets an observer that is notified of every HTTP request with its timings
+func WithRequestObserver(observer client.RequestObserver) Option {
+	return func(g *Gemini) {
+		g.SetRequestObserver(observer)
+	}
+}
+
+// WithDefaultAccount sets the account used by private requests that do not specify one
+func WithDefaultAccount(name string) Option {
+	return func(g *Gemini) {
+		g.SetDefaultAccount(name)
+	}
+}
+
+// WithStrictSymbols makes GetTradingPairs fail for symbols without details; see SetStrictSymbols
+func WithStrictSymbols(strict bool) Option {
+	return func(g *Gemini) {
+		g.SetStrictSymbols(strict)
+	}
+}
+
+// WithReconnectBackoff sets the stream reconnect backoff strategy; see SetReconnectBackoff
+func WithReconnectBackoff(strategy backoff.Strategy) Option {
+	return func(g *Gemini) {
+		g.SetReconnectBackoff(strategy)
+	}
+}
@@ -2,29 +2,45 @@ package gemini
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
 	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/shopspring/decimal"
 )
 
 // OrderAPI handles order management related operations
 type OrderAPI struct {
-	gemini *Gemini
+	gemini       *Gemini
+	autoRound    bool
+	roundingMode RoundingMode
+	amountMode   RoundingMode // RoundBySide means RoundDown for amounts
+	orderWeight  atomic.Int64 // written by SetRateLimit while orders are placed
+	tracker      atomic.Pointer[OrderTracker]
 }
 
+// defaultOrderWeight is the number of private rate limit tokens an order placement consumes.
+// Gemini documents order entry as counting once against the private limit.
+const defaultOrderWeight = 1
+
 // NewOrderAPI creates a new order API instance
 func NewOrderAPI(g *Gemini) *OrderAPI {
-	return &OrderAPI{
-		gemini: g,
+	o := &OrderAPI{gemini: g}
+	o.orderWeight.Store(defaultOrderWeight)
+	return o
+}
+
+// SetOrderWeight sets how many private rate limit tokens each order placement consumes,
+// to model an account whose order entry limit is tighter than its other private endpoints.
+// Non-positive values restore the default. It is safe to call while orders are being placed.
+func (o *OrderAPI) SetOrderWeight(weight int) {
+	if weight <= 0 {
+		weight = defaultOrderWeight
 	}
+	o.orderWeight.Store(int64(weight))
 }
 
 // OrderSide represents the side of an order
@@ -48,6 +64,30 @@ const (
 	OrderTypeIndicationOfInterest OrderType = "indication-of-interest"
 )
 
+// OrderOption is an order execution option, sent in NewOrderRequest.Options and reported
+// in Order.Options
+type OrderOption string
+
+const (
+	OrderOptionMakerOrCancel        OrderOption = "maker-or-cancel"
+	OrderOptionImmediateOrCancel    OrderOption = "immediate-or-cancel"
+	OrderOptionFillOrKill           OrderOption = "fill-or-kill"
+	OrderOptionAuctionOnly          OrderOption = "auction-only"
+	OrderOptionIndicationOfInterest OrderOption = "indication-of-interest"
+)
+
+// ParseOrderOption converts an option string to an OrderOption, ignoring case and surrounding
+// space. It reports false for options the SDK does not know.
+func ParseOrderOption(s string) (OrderOption, bool) {
+	option := OrderOption(strings.ToLower(strings.TrimSpace(s)))
+	switch option {
+	case OrderOptionMakerOrCancel, OrderOptionImmediateOrCancel, OrderOptionFillOrKill,
+		OrderOptionAuctionOnly, OrderOptionIndicationOfInterest:
+		return option, true
+	}
+	return "", false
+}
+
 // OrderStatus represents the status of an order
 type OrderStatus string
 
@@ -63,168 +103,343 @@ type NewOrderRequest struct {
 	Nonce         string    `json:"nonce"`
 	ClientOrderID string    `json:"client_order_id,omitempty"`
 	Symbol        string    `json:"symbol"`
-	Amount        string    `json:"amount"`
+	Amount        string    `json:"amount,omitempty"` // empty for total-spend market buys, which send TotalSpend instead
 	Price         string    `json:"price,omitempty"`
 	Side          OrderSide `json:"side"`
 	Type          OrderType `json:"type"`
 	Options       []string  `json:"options,omitempty"`
+	MinAmount     string    `json:"min_amount,omitempty"`
+	TotalSpend    string    `json:"total_spend,omitempty"`
 	Account       string    `json:"account,omitempty"`
+
+	// TimeInForce sets how long a limit order stays on the book. It is sent as the matching
+	// execution option; empty means good till cancelled.
+	TimeInForce exchange.TimeInForce `json:"-"`
+	// ExpireAt cancels a resting limit order at the given time. Gemini has no native order
+	// expiry, so the SDK cancels the order itself; it stays live if the process exits first.
+	ExpireAt time.Time `json:"-"`
 }
 
 // Order represents an order
 type Order struct {
-	OrderID           string    `json:"order_id"`
-	ID                string    `json:"id"`
-	Symbol            string    `json:"symbol"`
-	Exchange          string    `json:"exchange"`
-	AvgExecutionPrice string    `json:"avg_execution_price"`
-	Side              OrderSide `json:"side"`
-	Type              OrderType `json:"type"`
-	Timestamp         string    `json:"timestamp"`
-	Timestampms       int64     `json:"timestampms"`
-	IsLive            bool      `json:"is_live"`
-	IsCancelled       bool      `json:"is_cancelled"`
-	IsHidden          bool      `json:"is_hidden"`
-	WasForced         bool      `json:"was_forced"`
-	ExecutedAmount    string    `json:"executed_amount"`
-	RemainingAmount   string    `json:"remaining_amount"`
-	Options           []string  `json:"options"`
-	Price             string    `json:"price"`
-	OriginalAmount    string    `json:"original_amount"`
-	ClientOrderID     string    `json:"client_order_id,omitempty"`
+	OrderID           string      `json:"order_id"`
+	ID                string      `json:"id"`
+	Symbol            string      `json:"symbol"`
+	Exchange          string      `json:"exchange"`
+	AvgExecutionPrice string      `json:"avg_execution_price"`
+	Side              OrderSide   `json:"side"`
+	Type              OrderType   `json:"type"`
+	Timestamp         string      `json:"timestamp"`   // Creation time in seconds, sent as a string
+	Timestampms       FlexInt     `json:"timestampms"` // Creation time in milliseconds
+	IsLive            bool        `json:"is_live"`
+	IsCancelled       bool        `json:"is_cancelled"`
+	IsHidden          bool        `json:"is_hidden"`
+	WasForced         bool        `json:"was_forced"`
+	ExecutedAmount    string      `json:"executed_amount"`
+	RemainingAmount   string      `json:"remaining_amount"`
+	Options           []string    `json:"options"`
+	Price             string      `json:"price"`
+	OriginalAmount    string      `json:"original_amount"`
+	ClientOrderID     string      `json:"client_order_id,omitempty"`
+	Trades            []PastTrade `json:"trades,omitempty"` // Fills, only set by GetOrderStatus with includeTrades
+}
+
+// CreatedAt returns the time the order was accepted, or the zero time if the response has no timestamp
+func (o *Order) CreatedAt() time.Time {
+	seconds, _ := strconv.ParseInt(o.Timestamp, 10, 64)
+	return timestampTime(o.Timestampms, seconds)
+}
+
+// HasOption reports whether the order was placed with the given execution option
+func (o *Order) HasOption(option OrderOption) bool {
+	for _, s := range o.Options {
+		if parsed, ok := ParseOrderOption(s); ok && parsed == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMakerOrCancel reports whether the order was placed as maker-or-cancel (post-only)
+func (o *Order) IsMakerOrCancel() bool {
+	return o.HasOption(OrderOptionMakerOrCancel)
+}
+
+// MissingOptions returns the execution options requested in req that the order does not carry,
+// for reconciling a placed order with its request. Options the SDK does not know are skipped.
+func (o *Order) MissingOptions(req *NewOrderRequest) []OrderOption {
+	if req == nil {
+		return nil
+	}
+	var missing []OrderOption
+	for _, s := range req.Options {
+		if option, ok := ParseOrderOption(s); ok && !o.HasOption(option) {
+			missing = append(missing, option)
+		}
+	}
+	return missing
+}
+
+// Status derives the order's status from its live and cancelled flags
+func (o *Order) Status() OrderStatus {
+	switch {
+	case o.IsCancelled:
+		return OrderStatusCancelled
+	case o.IsLive:
+		return OrderStatusOpen
+	default:
+		return OrderStatusClosed
+	}
 }
 
 // PlaceOrder places a new order
+//
+// Indication-of-interest (block) orders are sent as "exchange limit" orders with the
+// "indication-of-interest" option. They require a limit price and a MinAmount no larger
+// than Amount, never rest on the book, and cannot be combined with other execution options.
+//
+// TimeInForce and ExpireAt are validated and mapped to execution options when the request is
+// built; an order with ExpireAt that is still live after placement is cancelled at that time.
+// These options are added to a copy, so req is left unchanged and can be placed again.
 func (o *OrderAPI) PlaceOrder(ctx context.Context, req *NewOrderRequest) (*Order, error) {
-	if o.gemini.apiKey == "" || o.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
+	order, err := o.placeOrder(ctx, req)
+	o.gemini.audit("PlaceOrder", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"symbol":          req.Symbol,
+			"side":            req.Side,
+			"type":            req.Type,
+			"amount":          req.Amount,
+			"price":           req.Price,
+			"min_amount":      req.MinAmount,
+			"total_spend":     req.TotalSpend,
+			"options":         req.Options,
+			"time_in_force":   req.TimeInForce,
+			"expire_at":       req.ExpireAt,
+			"client_order_id": req.ClientOrderID,
+			"account":         req.Account,
+		}
+	}, order, err)
+	o.trackOrder(order, err)
+	return order, err
+}
+
+// placeOrder places a new order without auditing
+func (o *OrderAPI) placeOrder(ctx context.Context, req *NewOrderRequest) (*Order, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrInvalidInput, "order request is required")
 	}
+	// Options are added to a copy, so the caller's request can be placed again unchanged
+	prepared := *req
+	prepared.Options = append([]string(nil), req.Options...)
+	req = &prepared
 
-	endpoint := "/v1/order/new"
-	url := fmt.Sprintf("%s%s", o.gemini.baseURL, endpoint)
+	if err := applyTimeInForce(req); err != nil {
+		return nil, err
+	}
+	if req.Type == OrderTypeIndicationOfInterest {
+		prepared, err := prepareIOIOrder(req)
+		if err != nil {
+			return nil, err
+		}
+		req = prepared
+	}
+	// With auto-rounding the copy is rounded to the symbol's increments before it is sent
+	if o.autoRound {
+		if err := o.ValidateOrder(ctx, req); err != nil {
+			return nil, err
+		}
+	}
 
-	// Set request endpoint and nonce
-	req.Request = endpoint
-	req.Nonce = strconv.FormatInt(time.Now().UnixNano(), 10)
+	o.gemini.logger.Debug().Str("symbol", req.Symbol).Str("side", string(req.Side)).Str("type", string(req.Type)).Msg("Placing order")
 
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(req)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal order request", err)
+	// Weighted as order entry
+	var order Order
+	if err := o.gemini.doSigned(ctx, signedCall{endpoint: "/v1/order/new", payload: req, weight: int(o.orderWeight.Load())}, &order); err != nil {
+		return nil, err
 	}
 
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
+	o.gemini.logger.Debug().Str("order_id", order.OrderID).Msg("Successfully placed order")
+	if !req.ExpireAt.IsZero() && order.IsLive {
+		o.scheduleExpiry(order.OrderID, req.Account, req.ExpireAt)
+	}
+	return &order, nil
+}
 
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(o.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
+// MarketBuy buys symbol at market, spending up to totalSpend in the quote currency
+// (e.g. USD for btcusd). The amount of base currency received depends on the fill price.
+// The order executes immediately against the book; any unfilled part is cancelled rather than resting.
+func (o *OrderAPI) MarketBuy(ctx context.Context, symbol string, totalSpend decimal.Decimal) (*Order, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+	if !totalSpend.IsPositive() {
+		return nil, errors.Newf(errors.ErrInvalidInput, "total spend must be positive, got %s", totalSpend)
+	}
+
+	return o.PlaceOrder(ctx, &NewOrderRequest{
+		Symbol:     symbol,
+		TotalSpend: totalSpend.String(),
+		Side:       OrderSideBuy,
+		Type:       OrderTypeMarketBuy,
+	})
+}
 
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    o.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
+// MarketSell sells amount of the base currency of symbol (e.g. BTC for btcusd) at market.
+// Unlike MarketBuy, the size is given in the base currency rather than as a quote-currency spend.
+// The order executes immediately against the book; any unfilled part is cancelled rather than resting.
+func (o *OrderAPI) MarketSell(ctx context.Context, symbol string, amount decimal.Decimal) (*Order, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
 	}
+	if !amount.IsPositive() {
+		return nil, errors.Newf(errors.ErrInvalidInput, "amount must be positive, got %s", amount)
+	}
+
+	return o.PlaceOrder(ctx, &NewOrderRequest{
+		Symbol: symbol,
+		Amount: amount.String(),
+		Side:   OrderSideSell,
+		Type:   OrderTypeMarketSell,
+	})
+}
 
-	o.gemini.logger.Debug().Str("url", url).Str("symbol", req.Symbol).Str("side", string(req.Side)).Str("type", string(req.Type)).Msg("Placing order")
+// PlacePeggedOrder places a maker-or-cancel limit order priced offsetBps basis points from the
+// current mid price on the passive side: below mid for buys and above mid for sells. The mid is
+// taken from the ticker's best bid and ask, and the price is rounded away from the mid to the
+// symbol's price increment so the order never crosses it. Gemini cancels the order instead of
+// filling it if it would take liquidity when it arrives.
+func (o *OrderAPI) PlacePeggedOrder(ctx context.Context, symbol string, side OrderSide, offsetBps float64, amount string) (*Order, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+	if side != OrderSideBuy && side != OrderSideSell {
+		return nil, errors.Newf(errors.ErrInvalidInput, "invalid order side: %q", side)
+	}
+	if offsetBps < 0 {
+		return nil, errors.Newf(errors.ErrInvalidInput, "offset must not be negative, got %v bps", offsetBps)
+	}
 
-	// Make POST request with authentication headers
-	response, err := o.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
+	ticker, err := o.gemini.Market.GetTicker(ctx, symbol)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to place order", err)
+		return nil, err
+	}
+	bid, bidErr := parseFloatFromString(ticker.Bid)
+	ask, askErr := parseFloatFromString(ticker.Ask)
+	if bidErr != nil || askErr != nil || bid <= 0 || ask <= 0 {
+		return nil, errors.Newf(errors.ErrInvalidResponse, "no best bid and ask for %s (bid %q, ask %q)", symbol, ticker.Bid, ticker.Ask)
 	}
 
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
+	details, err := o.symbolDetails(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
 
-	var order Order
-	if err := json.Unmarshal(response, &order); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse order response", err)
+	offset := offsetBps / 10000
+	if side == OrderSideBuy {
+		offset = -offset
+	}
+	target := (bid + ask) / 2 * (1 + offset)
+	price := strconv.FormatFloat(target, 'f', -1, 64)
+	if increment := float64(details.QuoteIncrement); increment > 0 {
+		price = formatIncrement(roundToIncrement(target, increment, roundingModeForSide(RoundBySide, side)), increment)
 	}
 
-	o.gemini.logger.Debug().Str("order_id", order.OrderID).Msg("Successfully placed order")
-	return &order, nil
+	o.gemini.logger.Debug().Str("symbol", symbol).Str("side", string(side)).Float64("bid", bid).Float64("ask", ask).Str("price", price).Msg("Placing pegged order")
+	return o.PlaceOrder(ctx, &NewOrderRequest{
+		Symbol:  symbol,
+		Amount:  amount,
+		Price:   price,
+		Side:    side,
+		Type:    OrderTypeExchangeLimit,
+		Options: []string{optionMakerOrCancel},
+	})
 }
 
 // CancelOrderRequest represents a cancel order request
 type CancelOrderRequest struct {
-	Request string `json:"request"`
-	Nonce   string `json:"nonce"`
-	OrderID string `json:"order_id"`
-	Account string `json:"account,omitempty"`
+	Request       string `json:"request"`
+	Nonce         string `json:"nonce"`
+	OrderID       string `json:"order_id,omitempty"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	Account       string `json:"account,omitempty"`
 }
 
 // CancelOrder cancels an existing order
 func (o *OrderAPI) CancelOrder(ctx context.Context, orderID string, account string) (*Order, error) {
-	if o.gemini.apiKey == "" || o.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
-	}
+	order, err := o.cancelOrder(ctx, orderID, "", account)
+	o.gemini.audit("CancelOrder", func() map[string]interface{} {
+		return map[string]interface{}{"order_id": orderID, "account": account}
+	}, order, err)
+	o.trackOrder(order, err)
+	return order, err
+}
 
-	endpoint := "/v1/order/cancel"
-	url := fmt.Sprintf("%s%s", o.gemini.baseURL, endpoint)
+// CancelOrderByClientID cancels an existing order identified by the client order ID
+// it was placed with, for callers that did not keep Gemini's order ID
+func (o *OrderAPI) CancelOrderByClientID(ctx context.Context, clientOrderID, account string) (*Order, error) {
+	order, err := o.cancelOrder(ctx, "", clientOrderID, account)
+	o.gemini.audit("CancelOrderByClientID", func() map[string]interface{} {
+		return map[string]interface{}{"client_order_id": clientOrderID, "account": account}
+	}, order, err)
+	o.trackOrder(order, err)
+	return order, err
+}
 
-	// Create request payload
-	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
-	request := CancelOrderRequest{
-		Request: endpoint,
-		Nonce:   nonce,
-		OrderID: orderID,
-		Account: account,
+// cancelOrder cancels an existing order by order ID or client order ID without auditing
+func (o *OrderAPI) cancelOrder(ctx context.Context, orderID string, clientOrderID string, account string) (*Order, error) {
+	if orderID == "" && clientOrderID == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "order ID or client order ID is required")
 	}
 
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(request)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal cancel request", err)
+	payload := map[string]any{}
+	if orderID != "" {
+		payload["order_id"] = orderID
+	}
+	if clientOrderID != "" {
+		payload["client_order_id"] = clientOrderID
+	}
+	if account != "" {
+		payload["account"] = account
 	}
 
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
-
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(o.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
+	o.gemini.logger.Debug().Str("order_id", orderID).Str("client_order_id", clientOrderID).Msg("Cancelling order")
 
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    o.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
+	var order Order
+	if err := o.gemini.doSignedPost(ctx, "/v1/order/cancel", payload, &order); err != nil {
+		return nil, err
 	}
 
-	o.gemini.logger.Debug().Str("url", url).Str("order_id", orderID).Msg("Cancelling order")
+	o.gemini.logger.Debug().Str("order_id", order.OrderID).Str("client_order_id", clientOrderID).Msg("Successfully cancelled order")
+	return &order, nil
+}
 
-	// Make POST request with authentication headers
-	response, err := o.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to cancel order", err)
+// ReplaceOrder cancels an existing order and places newReq in its place, returning the
+// cancelled and the new order. Gemini has no native amend endpoint, so this is a
+// cancel-then-place sequence: if the cancel fails nothing is placed, and if placement
+// fails the original order stays cancelled (it cannot be restored) and the cancelled
+// order is returned together with the error. Check the cancelled order's executed
+// amount before sizing newReq if the original may have been partially filled.
+func (o *OrderAPI) ReplaceOrder(ctx context.Context, orderID string, newReq *NewOrderRequest) (*Order, *Order, error) {
+	if newReq == nil {
+		return nil, nil, errors.New(errors.ErrInvalidInput, "replacement order request is required")
 	}
 
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
+	cancelled, err := o.CancelOrder(ctx, orderID, newReq.Account)
+	if err != nil {
+		return nil, nil, errors.Wrapf(errors.GetCode(err), err, "failed to cancel order %s for replacement", orderID)
 	}
 
-	var order Order
-	if err := json.Unmarshal(response, &order); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse cancel order response", err)
+	placed, err := o.PlaceOrder(ctx, newReq)
+	if err != nil {
+		o.gemini.logger.Warn().Str("order_id", orderID).Err(err).Msg("Order cancelled but replacement placement failed")
+		return cancelled, nil, errors.Wrapf(errors.GetCode(err), err, "order %s was cancelled but the replacement was not placed", orderID)
 	}
 
-	o.gemini.logger.Debug().Str("order_id", orderID).Msg("Successfully cancelled order")
-	return &order, nil
+	o.gemini.logger.Debug().Str("cancelled_order_id", orderID).Str("order_id", placed.OrderID).Msg("Successfully replaced order")
+	return cancelled, placed, nil
 }
 
 // GetActiveOrdersRequest represents a request to get active orders
@@ -236,66 +451,79 @@ type GetActiveOrdersRequest struct {
 
 // GetActiveOrders fetches all active orders
 func (o *OrderAPI) GetActiveOrders(ctx context.Context, account string) ([]Order, error) {
-	if o.gemini.apiKey == "" || o.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
+	orders, err := o.getActiveOrders(ctx, account)
+	o.gemini.audit("GetActiveOrders", func() map[string]interface{} {
+		return map[string]interface{}{"account": account}
+	}, orders, err)
+	if tracker := o.tracker.Load(); tracker != nil && err == nil {
+		tracker.Reconcile(orders)
 	}
+	return orders, err
+}
 
-	endpoint := "/v1/orders"
-	url := fmt.Sprintf("%s%s", o.gemini.baseURL, endpoint)
-
-	// Create request payload
-	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
-	request := GetActiveOrdersRequest{
-		Request: endpoint,
-		Nonce:   nonce,
-		Account: account,
+// getActiveOrders fetches all active orders without auditing
+func (o *OrderAPI) getActiveOrders(ctx context.Context, account string) ([]Order, error) {
+	payload := map[string]any{}
+	if account != "" {
+		payload["account"] = account
 	}
 
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(request)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal request payload", err)
+	o.gemini.logger.Debug().Str("account", account).Msg("Fetching active orders")
+
+	var orders []Order
+	if err := o.gemini.doSignedPost(ctx, "/v1/orders", payload, &orders); err != nil {
+		return nil, err
 	}
 
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
+	o.gemini.logger.Debug().Int("count", len(orders)).Msg("Successfully fetched active orders")
+	return orders, nil
+}
 
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(o.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
+// GetAllActiveOrders fetches active orders for each of the given accounts, a few at a time.
+// Results and per-account errors are keyed by account name; all requests share the private rate
+// limiter. If ctx is done, the accounts not fetched get ctx.Err().
+func (o *OrderAPI) GetAllActiveOrders(ctx context.Context, accounts []string) (map[string][]Order, map[string]error) {
+	results, errs := forEachBounded(ctx, privateRequestWorkers, accounts, func(account string) ([]Order, error) {
+		return o.GetActiveOrders(ctx, account)
+	})
 
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    o.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
-	}
+	o.gemini.logger.Debug().Int("accounts", len(accounts)).Int("failed", len(errs)).Msg("Fetched active orders for all accounts")
+	return results, errs
+}
 
-	o.gemini.logger.Debug().Str("url", url).Str("account", account).Msg("Fetching active orders")
+// CancelOrdersBySymbol cancels every active order for the given symbol, leaving other markets untouched.
+// Symbols are matched case-insensitively. Cancellations run a few at a time and share the private rate
+// limiter; the cancelled orders are returned in the order GetActiveOrders listed them, together with
+// per-order errors keyed by order ID. If ctx is done, the orders not yet cancelled get ctx.Err().
+func (o *OrderAPI) CancelOrdersBySymbol(ctx context.Context, symbol string, account string) ([]Order, map[string]error, error) {
+	if symbol == "" {
+		return nil, nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
 
-	// Make POST request with authentication headers
-	response, err := o.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
+	active, err := o.GetActiveOrders(ctx, account)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch active orders", err)
+		return nil, nil, err
 	}
 
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
+	orderIDs := make([]string, 0, len(active))
+	for _, order := range active {
+		if strings.EqualFold(order.Symbol, symbol) {
+			orderIDs = append(orderIDs, order.OrderID)
+		}
 	}
-
-	var orders []Order
-	if err := json.Unmarshal(response, &orders); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse orders response", err)
+	results, errs := forEachBounded(ctx, privateRequestWorkers, orderIDs, func(orderID string) (*Order, error) {
+		return o.CancelOrder(ctx, orderID, account)
+	})
+
+	cancelled := make([]Order, 0, len(results))
+	for _, orderID := range orderIDs {
+		if order, ok := results[orderID]; ok {
+			cancelled = append(cancelled, *order)
+		}
 	}
 
-	o.gemini.logger.Debug().Int("count", len(orders)).Msg("Successfully fetched active orders")
-	return orders, nil
+	o.gemini.logger.Debug().Str("symbol", symbol).Int("cancelled", len(cancelled)).Int("failed", len(errs)).Msg("Cancelled orders by symbol")
+	return cancelled, errs, nil
 }
 
 // GetOrderStatusRequest represents a request to get order status
@@ -310,67 +538,196 @@ type GetOrderStatusRequest struct {
 
 // GetOrderStatus fetches the status of a specific order
 func (o *OrderAPI) GetOrderStatus(ctx context.Context, orderID string, clientOrderID string, includeTrades bool, account string) (*Order, error) {
-	if o.gemini.apiKey == "" || o.gemini.apiSecret == "" {
-		return nil, errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
-	}
-
-	endpoint := "/v1/order/status"
-	url := fmt.Sprintf("%s%s", o.gemini.baseURL, endpoint)
+	order, err := o.getOrderStatus(ctx, orderID, clientOrderID, includeTrades, account)
+	o.gemini.audit("GetOrderStatus", func() map[string]interface{} {
+		return map[string]interface{}{
+			"order_id":        orderID,
+			"client_order_id": clientOrderID,
+			"include_trades":  includeTrades,
+			"account":         account,
+		}
+	}, order, err)
+	o.trackOrder(order, err)
+	return order, err
+}
 
-	// Create request payload
-	nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
+// getOrderStatus fetches the status of a specific order without auditing
+func (o *OrderAPI) getOrderStatus(ctx context.Context, orderID string, clientOrderID string, includeTrades bool, account string) (*Order, error) {
 	request := GetOrderStatusRequest{
-		Request:       endpoint,
-		Nonce:         nonce,
 		OrderID:       orderID,
 		ClientOrderID: clientOrderID,
 		IncludeTrades: includeTrades,
 		Account:       account,
 	}
 
-	// Marshal request to JSON
-	payloadBytes, err := json.Marshal(request)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to marshal request payload", err)
+	var order Order
+	if err := o.gemini.doSignedPost(ctx, "/v1/order/status", request, &order); err != nil {
+		return nil, err
 	}
 
-	// Encode payload to base64
-	payload := base64.StdEncoding.EncodeToString(payloadBytes)
+	o.gemini.logger.Debug().Str("order_id", orderID).Str("client_order_id", clientOrderID).Msg("Successfully fetched order status")
+	return &order, nil
+}
+
+// WaitForOrderTerminal polls GetOrderStatus every pollInterval until the order is no longer
+// live (filled or cancelled) or has no remaining amount, and returns the final order.
+// Polls go through the private rate limiter like any other request. If ctx ends first,
+// the last fetched order (nil if none) is returned with an ErrTimeout error.
+func (o *OrderAPI) WaitForOrderTerminal(ctx context.Context, orderID, account string, pollInterval time.Duration) (*Order, error) {
+	if orderID == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "order ID is required")
+	}
+	if pollInterval <= 0 {
+		return nil, errors.New(errors.ErrInvalidInput, "poll interval must be positive")
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var last *Order
+	for {
+		order, err := o.GetOrderStatus(ctx, orderID, "", false, account)
+		if err != nil {
+			if ctx.Err() != nil {
+				return last, errors.Wrap(errors.ErrTimeout, "stopped waiting for order "+orderID, ctx.Err())
+			}
+			return last, err
+		}
+		last = order
+		if isTerminalOrder(order) {
+			o.gemini.logger.Debug().Str("order_id", orderID).Bool("is_cancelled", order.IsCancelled).Str("executed_amount", order.ExecutedAmount).Msg("Order reached terminal state")
+			return order, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, errors.Wrap(errors.ErrTimeout, "stopped waiting for order "+orderID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
 
-	// Create HMAC-SHA384 signature
-	mac := hmac.New(sha512.New384, []byte(o.gemini.apiSecret))
-	mac.Write([]byte(payload))
-	signature := hex.EncodeToString(mac.Sum(nil))
+// isTerminalOrder reports whether an order can no longer change: it was cancelled,
+// closed, or has nothing left to fill
+func isTerminalOrder(order *Order) bool {
+	if order.IsCancelled || !order.IsLive {
+		return true
+	}
+	remaining, err := decimal.NewFromString(order.RemainingAmount)
+	return err == nil && remaining.IsZero()
+}
+
+// orderExpiryCancelTimeout bounds the cancel request sent when an order expires
+const orderExpiryCancelTimeout = 30 * time.Second
+
+// applyTimeInForce validates an order's time in force and expiry and adds the matching
+// execution option. Market orders are immediate by nature and only accept IOC. Other time in
+// force values and expiry apply to limit orders, and IOC and FOK orders never rest on the
+// book, so they cannot expire. Unsupported combinations return ErrInvalidOrderType.
+func applyTimeInForce(req *NewOrderRequest) error {
+	if req.TimeInForce == "" && req.ExpireAt.IsZero() {
+		return nil
+	}
 
-	// Set required headers for private API
-	headers := map[string]string{
-		"X-GEMINI-APIKEY":    o.gemini.apiKey,
-		"X-GEMINI-PAYLOAD":   payload,
-		"X-GEMINI-SIGNATURE": signature,
-		"Content-Type":       "text/plain",
-		"Content-Length":     "0",
-		"Cache-Control":      "no-cache",
+	if req.Type == OrderTypeMarketBuy || req.Type == OrderTypeMarketSell {
+		if req.TimeInForce != "" && req.TimeInForce != exchange.TimeInForceIOC {
+			return errors.Newf(errors.ErrInvalidOrderType, "market orders do not support time in force %s", req.TimeInForce)
+		}
+		if !req.ExpireAt.IsZero() {
+			return errors.New(errors.ErrInvalidOrderType, "market orders cannot expire")
+		}
+		return nil
+	}
+	if req.Type != OrderTypeExchangeLimit {
+		return errors.Newf(errors.ErrInvalidOrderType, "%s orders do not support a time in force or expiry", req.Type)
 	}
 
-	o.gemini.logger.Debug().Str("url", url).Str("order_id", orderID).Str("client_order_id", clientOrderID).Msg("Fetching order status")
+	if !req.ExpireAt.IsZero() {
+		if req.TimeInForce == exchange.TimeInForceIOC || req.TimeInForce == exchange.TimeInForceFOK {
+			return errors.Newf(errors.ErrInvalidOrderType, "%s orders never rest on the book and cannot expire", req.TimeInForce)
+		}
+		if !req.ExpireAt.After(time.Now()) {
+			return errors.Newf(errors.ErrInvalidInput, "order expiry %s is not in the future", req.ExpireAt.Format(time.RFC3339))
+		}
+	}
 
-	// Make POST request with authentication headers
-	response, err := o.gemini.client.PostWithHeaders(ctx, url, nil, headers, client.APITypePrivate)
+	options, err := ToGeminiOptions(req.TimeInForce)
 	if err != nil {
-		return nil, errors.Wrap(errors.ErrNetworkError, "failed to fetch order status", err)
+		return err
+	}
+	for _, option := range options {
+		for _, existing := range req.Options {
+			if existing != option && isTimeInForceOption(existing) {
+				return errors.Newf(errors.ErrInvalidOrderType, "time in force %s conflicts with option %s", req.TimeInForce, existing)
+			}
+		}
+		if !containsOption(req.Options, option) {
+			req.Options = append(req.Options, option)
+		}
 	}
+	return nil
+}
 
-	// Check for API error response
-	var errorResp ErrorResponse
-	if err := json.Unmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
-		return nil, errors.Newf(errors.ErrAPIError, "Gemini API error: %s - %s", errorResp.Reason, errorResp.Message)
+// isTimeInForceOption reports whether an execution option sets the order's time in force
+func isTimeInForceOption(option string) bool {
+	switch option {
+	case optionMakerOrCancel, optionImmediateOrCancel, optionFillOrKill:
+		return true
 	}
+	return false
+}
 
-	var order Order
-	if err := json.Unmarshal(response, &order); err != nil {
-		return nil, errors.Wrap(errors.ErrDataParsingError, "failed to parse order status response", err)
+// containsOption reports whether options contains option
+func containsOption(options []string, option string) bool {
+	for _, existing := range options {
+		if existing == option {
+			return true
+		}
 	}
+	return false
+}
 
-	o.gemini.logger.Debug().Str("order_id", orderID).Msg("Successfully fetched order status")
-	return &order, nil
+// scheduleExpiry cancels an order once its expiry time is reached. Orders that are filled or
+// cancelled before then make the cancel fail, which is only logged.
+func (o *OrderAPI) scheduleExpiry(orderID, account string, expireAt time.Time) {
+	time.AfterFunc(time.Until(expireAt), func() {
+		ctx, cancel := context.WithTimeout(context.Background(), orderExpiryCancelTimeout)
+		defer cancel()
+		if _, err := o.CancelOrder(ctx, orderID, account); err != nil {
+			o.gemini.logger.Debug().Err(err).Str("order_id", orderID).Msg("Failed to cancel expired order")
+			return
+		}
+		o.gemini.logger.Debug().Str("order_id", orderID).Msg("Cancelled expired order")
+	})
+}
+
+// prepareIOIOrder validates an indication-of-interest order and returns a copy of it
+// rewritten into the limit order with the IOI option that the API expects. req is not modified.
+func prepareIOIOrder(req *NewOrderRequest) (*NewOrderRequest, error) {
+	if req.Price == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "indication-of-interest orders require a price")
+	}
+	if req.MinAmount == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "indication-of-interest orders require a minimum amount")
+	}
+	if len(req.Options) > 0 {
+		return nil, errors.New(errors.ErrInvalidOrderType, "indication-of-interest orders cannot be combined with other options")
+	}
+
+	amount, err := parseFloatFromString(req.Amount)
+	if err != nil || amount <= 0 {
+		return nil, errors.Newf(errors.ErrInvalidInput, "invalid order amount: %s", req.Amount)
+	}
+	minAmount, err := parseFloatFromString(req.MinAmount)
+	if err != nil || minAmount <= 0 {
+		return nil, errors.Newf(errors.ErrInvalidInput, "invalid minimum amount: %s", req.MinAmount)
+	}
+	if minAmount > amount {
+		return nil, errors.Newf(errors.ErrInvalidInput, "minimum amount %s exceeds order amount %s", req.MinAmount, req.Amount)
+	}
+
+	prepared := *req
+	prepared.Type = OrderTypeExchangeLimit
+	prepared.Options = []string{string(OrderTypeIndicationOfInterest)}
+	return &prepared, nil
 }
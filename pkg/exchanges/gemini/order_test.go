@@ -0,0 +1,811 @@
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/exchange"
+	"github.com/shopspring/decimal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareIOIOrder(t *testing.T) {
+	req := &NewOrderRequest{
+		Symbol:    "btcusd",
+		Amount:    "10",
+		MinAmount: "5",
+		Price:     "30000",
+		Side:      OrderSideBuy,
+		Type:      OrderTypeIndicationOfInterest,
+	}
+
+	prepared, err := prepareIOIOrder(req)
+	require.NoError(t, err)
+	assert.Equal(t, OrderTypeExchangeLimit, prepared.Type)
+	assert.Equal(t, []string{"indication-of-interest"}, prepared.Options)
+	assert.Equal(t, OrderTypeIndicationOfInterest, req.Type, "the caller's request is not modified")
+	assert.Empty(t, req.Options)
+
+	invalid := []*NewOrderRequest{
+		{Amount: "10", MinAmount: "5"},                                                       // missing price
+		{Amount: "10", Price: "30000"},                                                       // missing minimum amount
+		{Amount: "10", MinAmount: "20", Price: "30000"},                                      // minimum above amount
+		{Amount: "10", MinAmount: "5", Price: "30000", Options: []string{"maker-or-cancel"}}, // extra options
+	}
+	for _, r := range invalid {
+		r.Type = OrderTypeIndicationOfInterest
+		_, err := prepareIOIOrder(r)
+		assert.Error(t, err, "expected error for %+v", r)
+	}
+}
+
+func TestOrderAPI_PlaceOrder_IOIKeepsRequest(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payloads = append(payloads, decodeTestPayload(t, r))
+		_, _ = w.Write([]byte(`{"order_id":"1","is_live":false}`))
+	})
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "10", MinAmount: "5", Price: "30000", Side: OrderSideBuy, Type: OrderTypeIndicationOfInterest}
+
+	for i := 0; i < 2; i++ {
+		_, err := g.Order.PlaceOrder(context.Background(), req)
+		require.NoError(t, err)
+	}
+	require.Len(t, payloads, 2)
+	for _, payload := range payloads {
+		assert.Equal(t, string(OrderTypeExchangeLimit), payload["type"])
+		assert.Equal(t, []interface{}{"indication-of-interest"}, payload["options"])
+	}
+	assert.Equal(t, OrderTypeIndicationOfInterest, req.Type)
+	assert.Nil(t, req.Options)
+}
+
+func TestOrderAPI_PlaceOrder_KeepsRequest(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payloads = append(payloads, decodeTestPayload(t, r))
+		_, _ = w.Write([]byte(`{"order_id":"1","is_live":false}`))
+	})
+	ctx := context.Background()
+
+	// A template whose options slice has spare capacity must not be written through
+	options := make([]string, 0, 4)
+	template := NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "100.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit, Options: options}
+	ioc, fok := template, template
+	ioc.TimeInForce = exchange.TimeInForceIOC
+	fok.TimeInForce = exchange.TimeInForceFOK
+	for _, req := range []*NewOrderRequest{&ioc, &fok, &ioc} {
+		_, err := g.Order.PlaceOrder(ctx, req)
+		require.NoError(t, err)
+	}
+	require.Len(t, payloads, 3)
+	assert.Equal(t, []interface{}{"immediate-or-cancel"}, payloads[0]["options"])
+	assert.Equal(t, []interface{}{"fill-or-kill"}, payloads[1]["options"])
+	assert.Equal(t, []interface{}{"immediate-or-cancel"}, payloads[2]["options"])
+	assert.Empty(t, ioc.Options)
+	assert.Empty(t, fok.Options)
+
+	_, err := g.Order.PlaceOrder(ctx, nil)
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+	assert.Len(t, payloads, 3)
+}
+
+// newTestGemini creates a Gemini instance with dummy credentials pointed at a local server
+func newTestGemini(t *testing.T, handler http.HandlerFunc) *Gemini {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	g := NewGemini(&exchange.Config{APIKey: "test-key", SecretKey: "test-secret"})
+	require.NoError(t, g.SetBaseURLs([]string{server.URL}))
+	return g
+}
+
+func TestOrderAPI_ReplaceOrder(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/order/cancel":
+			_, _ = w.Write([]byte(`{"order_id":"1","is_cancelled":true,"price":"100.00"}`))
+		case "/v1/order/new":
+			_, _ = w.Write([]byte(`{"order_id":"2","is_live":true,"price":"101.00"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "101.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit}
+	cancelled, placed, err := g.Order.ReplaceOrder(context.Background(), "1", req)
+	require.NoError(t, err)
+	assert.Equal(t, "1", cancelled.OrderID)
+	assert.True(t, cancelled.IsCancelled)
+	assert.Equal(t, "2", placed.OrderID)
+}
+
+func TestOrderAPI_ReplaceOrder_PlacementFails(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/order/cancel":
+			_, _ = w.Write([]byte(`{"order_id":"1","is_cancelled":true}`))
+		default:
+			_, _ = w.Write([]byte(`{"result":"error","reason":"InsufficientFunds","message":"Insufficient funds"}`))
+		}
+	})
+
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "101.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit}
+	cancelled, placed, err := g.Order.ReplaceOrder(context.Background(), "1", req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "was cancelled but the replacement was not placed")
+	require.NotNil(t, cancelled)
+	assert.Equal(t, "1", cancelled.OrderID)
+	assert.Nil(t, placed)
+}
+
+func TestOrderAPI_ValidateOrder(t *testing.T) {
+	g := NewGemini(nil)
+	g.symbols.putDetails(SymbolDetails{
+		Symbol:         "BTCUSD",
+		TickSize:       1e-8,
+		QuoteIncrement: 0.01,
+		MinOrderSize:   "0.00001",
+	})
+	ctx := context.Background()
+
+	valid := &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Price: "30000.25", Type: OrderTypeExchangeLimit}
+	assert.NoError(t, g.Order.ValidateOrder(ctx, valid))
+
+	market := &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Type: OrderTypeMarketBuy}
+	assert.NoError(t, g.Order.ValidateOrder(ctx, market))
+
+	invalid := map[string]*NewOrderRequest{
+		"below minimum order size": {Symbol: "btcusd", Amount: "0.000001", Price: "30000", Type: OrderTypeExchangeLimit},
+		"not a multiple of price":  {Symbol: "btcusd", Amount: "0.5", Price: "30000.123", Type: OrderTypeExchangeLimit},
+		"price is required":        {Symbol: "btcusd", Amount: "0.5", Type: OrderTypeExchangeLimit},
+		"not a multiple of amount": {Symbol: "btcusd", Amount: "0.123456789", Price: "30000", Type: OrderTypeExchangeLimit},
+	}
+	for message, req := range invalid {
+		err := g.Order.ValidateOrder(ctx, req)
+		require.Error(t, err, message)
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+func TestOrderAPI_ValidateOrder_AutoRound(t *testing.T) {
+	g := NewGemini(nil)
+	g.symbols.putDetails(SymbolDetails{
+		Symbol:         "BTCUSD",
+		TickSize:       1e-8,
+		QuoteIncrement: 0.01,
+		MinOrderSize:   "0.00001",
+	})
+	g.Order.SetAutoRound(true)
+
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "0.123456789", Price: "30000.126", Type: OrderTypeExchangeLimit}
+	require.NoError(t, g.Order.ValidateOrder(context.Background(), req))
+	assert.Equal(t, "0.12345678", req.Amount)
+	assert.Equal(t, "30000.13", req.Price)
+}
+
+func TestOrderAPI_PlaceOrder_AutoRound(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/order/cancel" {
+			_, _ = w.Write([]byte(`{"order_id":"1","is_cancelled":true}`))
+			return
+		}
+		payloads = append(payloads, decodeTestPayload(t, r))
+		_, _ = w.Write([]byte(`{"order_id":"2","is_live":true}`))
+	})
+	g.symbols.putDetails(SymbolDetails{
+		Symbol:         "BTCUSD",
+		TickSize:       1e-8,
+		QuoteIncrement: 0.01,
+		MinOrderSize:   "0.00001",
+	})
+	g.Order.SetAutoRound(true)
+	ctx := context.Background()
+
+	// PlaceOrder rounds a copy before sending and leaves the caller's request unchanged
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "0.123456789", Price: "30000.129", Side: OrderSideBuy, Type: OrderTypeExchangeLimit}
+	_, err := g.Order.PlaceOrder(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "0.12345678", payloads[0]["amount"])
+	assert.Equal(t, "30000.12", payloads[0]["price"])
+	assert.Equal(t, "0.123456789", req.Amount)
+	assert.Equal(t, "30000.129", req.Price)
+
+	// So does ReplaceOrder, which places through PlaceOrder
+	_, _, err = g.Order.ReplaceOrder(ctx, "1", &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Price: "30000.121", Side: OrderSideSell, Type: OrderTypeExchangeLimit})
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "30000.13", payloads[1]["price"])
+
+	// An order that still violates a constraint after rounding is not sent
+	_, err = g.Order.PlaceOrder(ctx, &NewOrderRequest{Symbol: "btcusd", Amount: "0.000001", Price: "30000", Side: OrderSideBuy, Type: OrderTypeExchangeLimit})
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+	assert.Len(t, payloads, 2)
+}
+
+func TestOrderAPI_ValidateOrder_AutoRoundFailure(t *testing.T) {
+	g := NewGemini(nil)
+	g.symbols.putDetails(SymbolDetails{
+		Symbol:         "BTCUSD",
+		TickSize:       1e-8,
+		QuoteIncrement: 0.01,
+		MinOrderSize:   "0.00001",
+	})
+	g.Order.SetAutoRound(true)
+	ctx := context.Background()
+
+	// A failed check leaves the request as it was
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "0.123456789", Price: "-1", Type: OrderTypeExchangeLimit}
+	require.Error(t, g.Order.ValidateOrder(ctx, req))
+	assert.Equal(t, "0.123456789", req.Amount)
+
+	// Orders sized by total spend have no amount to check
+	req = &NewOrderRequest{Symbol: "btcusd", TotalSpend: "100", Side: OrderSideBuy, Type: OrderTypeMarketBuy}
+	require.NoError(t, g.Order.ValidateOrder(ctx, req))
+	assert.Empty(t, req.Amount)
+}
+
+func TestOrderAPI_ValidateOrder_RoundingMode(t *testing.T) {
+	g := NewGemini(nil)
+	g.symbols.putDetails(SymbolDetails{
+		Symbol:         "BTCUSD",
+		TickSize:       1e-8,
+		QuoteIncrement: 0.01,
+		MinOrderSize:   "0.00001",
+	})
+	g.Order.SetAutoRound(true)
+	ctx := context.Background()
+
+	tests := []struct {
+		mode     RoundingMode
+		side     OrderSide
+		price    string
+		expected string
+	}{
+		{RoundBySide, OrderSideBuy, "30000.129", "30000.12"},
+		{RoundBySide, OrderSideSell, "30000.121", "30000.13"},
+		{RoundBySide, "", "30000.125", "30000.13"},
+		{RoundBySide, OrderSideBuy, "30000.13", "30000.13"},
+		{RoundBySide, OrderSideSell, "30000.13", "30000.13"},
+		{RoundDown, OrderSideSell, "30000.129", "30000.12"},
+		{RoundUp, OrderSideBuy, "30000.121", "30000.13"},
+		{RoundNearest, OrderSideBuy, "30000.126", "30000.13"},
+	}
+	for _, test := range tests {
+		g.Order.SetRoundingMode(test.mode)
+		req := &NewOrderRequest{Symbol: "btcusd", Amount: "0.5", Price: test.price, Side: test.side, Type: OrderTypeExchangeLimit}
+		require.NoError(t, g.Order.ValidateOrder(ctx, req))
+		assert.Equal(t, test.expected, req.Price, "mode %d side %q price %s", test.mode, test.side, test.price)
+	}
+}
+
+func TestOrderAPI_ValidateOrder_AmountRoundingMode(t *testing.T) {
+	g := NewGemini(nil)
+	g.symbols.putDetails(SymbolDetails{
+		Symbol:         "BTCUSD",
+		TickSize:       1e-8,
+		QuoteIncrement: 0.01,
+		MinOrderSize:   "0.00001",
+	})
+	g.Order.SetAutoRound(true)
+	ctx := context.Background()
+
+	tests := []struct {
+		mode     RoundingMode
+		amount   string
+		expected string
+	}{
+		// The default never increases the order size
+		{RoundBySide, "0.123456789", "0.12345678"},
+		{RoundDown, "0.123456789", "0.12345678"},
+		{RoundDown, "0.12345678", "0.12345678"},
+		{RoundUp, "0.123456781", "0.12345679"},
+		{RoundUp, "0.12345678", "0.12345678"},
+		{RoundNearest, "0.123456785", "0.12345679"},
+		{RoundNearest, "0.123456784", "0.12345678"},
+	}
+	for _, test := range tests {
+		g.Order.SetAmountRoundingMode(test.mode)
+		req := &NewOrderRequest{Symbol: "btcusd", Amount: test.amount, Price: "30000.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit}
+		require.NoError(t, g.Order.ValidateOrder(ctx, req))
+		assert.Equal(t, test.expected, req.Amount, "mode %d amount %s", test.mode, test.amount)
+		assert.Equal(t, "30000.00", req.Price)
+	}
+}
+
+func TestRoundToIncrement(t *testing.T) {
+	tests := []struct {
+		value     float64
+		increment float64
+		mode      RoundingMode
+		expected  string
+	}{
+		// Values already on a tick stay there despite float noise
+		{0.3, 0.1, RoundUp, "0.3"},
+		{0.7, 0.1, RoundDown, "0.7"},
+		{1.15, 0.05, RoundUp, "1.15"},
+		{0.29, 0.01, RoundDown, "0.29"},
+		{0.00000003, 1e-8, RoundDown, "0.00000003"},
+		// Off-tick values move in the requested direction
+		{0.301, 0.1, RoundUp, "0.4"},
+		{0.399, 0.1, RoundDown, "0.3"},
+		{0.35, 0.1, RoundNearest, "0.4"},
+		{12.5, 5, RoundDown, "10"},
+		{12.5, 5, RoundUp, "15"},
+		{12.5, 0, RoundUp, "12"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, RoundToIncrement(test.value, test.increment, test.mode), "RoundToIncrement(%v, %v, %d)", test.value, test.increment, test.mode)
+	}
+}
+
+func TestApplyTimeInForce(t *testing.T) {
+	future := time.Now().Add(time.Minute)
+	tests := []struct {
+		name     string
+		req      NewOrderRequest
+		options  []string
+		expected errors.ErrorCode
+	}{
+		{"gtc", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForceGTC}, nil, ""},
+		{"ioc", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForceIOC}, []string{"immediate-or-cancel"}, ""},
+		{"fok", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForceFOK}, []string{"fill-or-kill"}, ""},
+		{"post only with expiry", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForcePostOnly, ExpireAt: future}, []string{"maker-or-cancel"}, ""},
+		{"option already set", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForceIOC, Options: []string{"immediate-or-cancel"}}, []string{"immediate-or-cancel"}, ""},
+		{"market ioc", NewOrderRequest{Type: OrderTypeMarketSell, TimeInForce: exchange.TimeInForceIOC}, nil, ""},
+		{"conflicting option", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForceFOK, Options: []string{"maker-or-cancel"}}, nil, errors.ErrInvalidOrderType},
+		{"ioc with expiry", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: exchange.TimeInForceIOC, ExpireAt: future}, nil, errors.ErrInvalidOrderType},
+		{"market gtc", NewOrderRequest{Type: OrderTypeMarketSell, TimeInForce: exchange.TimeInForceGTC}, nil, errors.ErrInvalidOrderType},
+		{"market expiry", NewOrderRequest{Type: OrderTypeMarketBuy, ExpireAt: future}, nil, errors.ErrInvalidOrderType},
+		{"auction-only", NewOrderRequest{Type: OrderTypeAuctionOnly, TimeInForce: exchange.TimeInForceIOC}, nil, errors.ErrInvalidOrderType},
+		{"unsupported", NewOrderRequest{Type: OrderTypeExchangeLimit, TimeInForce: "GTD"}, nil, errors.ErrInvalidOrderType},
+		{"past expiry", NewOrderRequest{Type: OrderTypeExchangeLimit, ExpireAt: time.Now().Add(-time.Second)}, nil, errors.ErrInvalidInput},
+	}
+	for _, test := range tests {
+		req := test.req
+		err := applyTimeInForce(&req)
+		if test.expected != "" {
+			require.Error(t, err, test.name)
+			assert.Equal(t, test.expected, errors.GetCode(err), test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.options, req.Options, test.name)
+	}
+}
+
+func TestOrderAPI_PlaceOrder_ExpireAt(t *testing.T) {
+	cancelled := make(chan map[string]interface{}, 1)
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/order/new":
+			payload := decodeTestPayload(t, r)
+			assert.NotContains(t, payload, "ExpireAt")
+			_, _ = w.Write([]byte(`{"order_id":"42","is_live":true}`))
+		case "/v1/order/cancel":
+			cancelled <- decodeTestPayload(t, r)
+			_, _ = w.Write([]byte(`{"order_id":"42","is_live":false,"is_cancelled":true}`))
+		}
+	})
+
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "100.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit, ExpireAt: time.Now().Add(50 * time.Millisecond)}
+	_, err := g.Order.PlaceOrder(context.Background(), req)
+	require.NoError(t, err)
+
+	select {
+	case payload := <-cancelled:
+		assert.Equal(t, "42", payload["order_id"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the order to be cancelled at its expiry")
+	}
+}
+
+func TestOrderAPI_MarketBuySell(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payloads = append(payloads, decodeTestPayload(t, r))
+		_, _ = w.Write([]byte(`{"order_id":"1"}`))
+	})
+	ctx := context.Background()
+
+	_, err := g.Order.MarketBuy(ctx, "btcusd", decimal.RequireFromString("250.50"))
+	require.NoError(t, err)
+	_, err = g.Order.MarketSell(ctx, "btcusd", decimal.RequireFromString("0.01"))
+	require.NoError(t, err)
+
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "market buy", payloads[0]["type"])
+	assert.Equal(t, "250.5", payloads[0]["total_spend"])
+	assert.NotContains(t, payloads[0], "amount")
+	assert.NotContains(t, payloads[0], "price")
+	assert.Equal(t, "market sell", payloads[1]["type"])
+	assert.Equal(t, "0.01", payloads[1]["amount"])
+	assert.NotContains(t, payloads[1], "total_spend")
+
+	_, err = g.Order.MarketBuy(ctx, "btcusd", decimal.Zero)
+	assert.Error(t, err)
+	_, err = g.Order.MarketSell(ctx, "", decimal.NewFromInt(1))
+	assert.Error(t, err)
+}
+
+func TestOrderAPI_PlacePeggedOrder(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ticker/btcusd":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSD","bid":"100.00","ask":"100.10","close":"100.05"}`))
+		case "/v1/symbols/details/btcusd":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSD","tick_size":1e-8,"quote_increment":0.01,"min_order_size":"0.00001","status":"open"}`))
+		case "/v2/ticker/ethusd":
+			_, _ = w.Write([]byte(`{"symbol":"ETHUSD","bid":"","ask":"2000.00"}`))
+		default:
+			payloads = append(payloads, decodeTestPayload(t, r))
+			_, _ = w.Write([]byte(`{"order_id":"1","is_live":true}`))
+		}
+	})
+	ctx := context.Background()
+
+	_, err := g.Order.PlacePeggedOrder(ctx, "btcusd", OrderSideBuy, 10, "0.5")
+	require.NoError(t, err)
+	_, err = g.Order.PlacePeggedOrder(ctx, "btcusd", OrderSideSell, 10, "0.5")
+	require.NoError(t, err)
+
+	// Mid is 100.05; 10 bps away is 99.94995 for the buy and 100.15005 for the sell,
+	// rounded away from the mid to the 0.01 tick
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "99.94", payloads[0]["price"])
+	assert.Equal(t, "buy", payloads[0]["side"])
+	assert.Equal(t, "exchange limit", payloads[0]["type"])
+	assert.Equal(t, []interface{}{"maker-or-cancel"}, payloads[0]["options"])
+	assert.Equal(t, "100.16", payloads[1]["price"])
+	assert.Equal(t, "sell", payloads[1]["side"])
+
+	_, err = g.Order.PlacePeggedOrder(ctx, "ethusd", OrderSideBuy, 10, "1")
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	_, err = g.Order.PlacePeggedOrder(ctx, "btcusd", OrderSideBuy, -5, "1")
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+	_, err = g.Order.PlacePeggedOrder(ctx, "btcusd", "", 10, "1")
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+	assert.Len(t, payloads, 2)
+}
+
+func TestOrderAPI_PlaceOrder_Weight(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/orders" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"order_id":"1"}`))
+	})
+	g.SetRateLimit(exchange.APITypePrivate, exchange.RateLimit{Requests: 10, Interval: time.Hour, Weight: 3})
+	limiter := g.client.GetRateLimiter(client.APITypePrivate)
+
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "100.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit}
+	_, err := g.Order.PlaceOrder(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 7, limiter.AvailableTokens())
+
+	// Other private requests still count once
+	_, err = g.Order.GetActiveOrders(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 6, limiter.AvailableTokens())
+
+	// The weight can change while orders are placed
+	g.SetRateLimit(exchange.APITypePrivate, exchange.RateLimit{Requests: 1000, Interval: time.Second})
+	var wg sync.WaitGroup
+	for i := 1; i <= 4; i++ {
+		wg.Add(2)
+		go func(weight int) {
+			defer wg.Done()
+			g.Order.SetOrderWeight(weight)
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _ = g.Order.PlaceOrder(context.Background(), req)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOrderAPI_CancelOrderByClientID(t *testing.T) {
+	var payload map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/order/cancel", r.URL.Path)
+		payload = decodeTestPayload(t, r)
+		_, _ = w.Write([]byte(`{"order_id":"42","client_order_id":"my-order-1","is_cancelled":true}`))
+	})
+
+	order, err := g.Order.CancelOrderByClientID(context.Background(), "my-order-1", "primary")
+	require.NoError(t, err)
+	assert.Equal(t, "42", order.OrderID)
+	assert.True(t, order.IsCancelled)
+	assert.Equal(t, "my-order-1", payload["client_order_id"])
+	assert.Equal(t, "primary", payload["account"])
+	assert.NotContains(t, payload, "order_id")
+
+	_, err = g.Order.CancelOrderByClientID(context.Background(), "", "")
+	assert.Error(t, err)
+}
+
+func TestOrderAPI_DoSignedPost(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-GEMINI-APIKEY"))
+		assert.NotEmpty(t, r.Header.Get("X-GEMINI-SIGNATURE"))
+		payload := decodeTestPayload(t, r)
+		payloads = append(payloads, payload)
+		if payload["order_id"] == "404" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"result":"error","reason":"OrderNotFound","message":"Order 404 not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"order_id":"42","symbol":"btcusd","is_cancelled":true}`))
+	})
+	g.SetNonceManager(&counterNonces{})
+	ctx := context.Background()
+
+	// Cancelling an order is a representative endpoint built on doSignedPost
+	order, err := g.Order.CancelOrder(ctx, "42", "")
+	require.NoError(t, err)
+	assert.Equal(t, "42", order.OrderID)
+	assert.True(t, order.IsCancelled)
+	assert.Equal(t, map[string]interface{}{"request": "/v1/order/cancel", "nonce": "1", "order_id": "42"}, payloads[0])
+
+	_, err = g.Order.CancelOrder(ctx, "404", "")
+	assert.Equal(t, errors.ErrAPIError, errors.GetCode(err))
+
+	var out map[string]interface{}
+	require.NoError(t, g.doSignedPost(ctx, "/v1/example", map[string]any{"account": "primary"}, &out))
+	assert.Equal(t, map[string]interface{}{"request": "/v1/example", "nonce": "3", "account": "primary"}, payloads[2])
+	assert.Equal(t, "42", out["order_id"])
+
+	// Request structs are sent with their own field tags and are not modified
+	status := GetOrderStatusRequest{OrderID: "42"}
+	require.NoError(t, g.doSignedPost(ctx, "/v1/order/status", &status, &out))
+	assert.Equal(t, map[string]interface{}{"request": "/v1/order/status", "nonce": "4", "order_id": "42"}, payloads[3])
+	assert.Empty(t, status.Nonce)
+
+	// A slice target requires a JSON array
+	var list []Order
+	err = g.doSignedPost(ctx, "/v1/example", nil, &list)
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+
+	unsigned := NewGemini(nil)
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(unsigned.doSignedPost(ctx, "/v1/example", nil, &out)))
+}
+
+func TestOrderAPI_CancelOrdersBySymbol(t *testing.T) {
+	var mu sync.Mutex
+	var cancelledIDs []string
+	var lastNonce int64
+	active := []string{`{"order_id":"1","symbol":"btcusd","is_live":true}`, `{"order_id":"2","symbol":"ethusd","is_live":true}`,
+		`{"order_id":"3","symbol":"BTCUSD","is_live":true}`, `{"order_id":"4","symbol":"btcusd","is_live":true}`}
+	for i := 5; i < 25; i++ {
+		active = append(active, fmt.Sprintf(`{"order_id":"%d","symbol":"btcusd","is_live":true}`, i))
+	}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payload := decodeTestPayload(t, r)
+		nonce, err := strconv.ParseInt(payload["nonce"].(string), 10, 64)
+		require.NoError(t, err)
+		mu.Lock()
+		stale := nonce <= lastNonce
+		lastNonce = max(lastNonce, nonce)
+		mu.Unlock()
+		if stale {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidNonce","message":"Nonce has not increased"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/orders":
+			_, _ = w.Write([]byte("[" + strings.Join(active, ",") + "]"))
+		case "/v1/order/cancel":
+			orderID := payload["order_id"].(string)
+			if orderID == "4" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"result":"error","reason":"OrderNotFound","message":"Order 4 not found"}`))
+				return
+			}
+			mu.Lock()
+			cancelledIDs = append(cancelledIDs, orderID)
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"order_id":"` + orderID + `","symbol":"btcusd","is_cancelled":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	cancelled, errs, err := g.Order.CancelOrdersBySymbol(context.Background(), "BtcUsd", "")
+	require.NoError(t, err)
+	require.Len(t, cancelled, 22)
+	assert.Len(t, cancelledIDs, 22)
+	assert.Equal(t, "1", cancelled[0].OrderID)
+	assert.Equal(t, "3", cancelled[1].OrderID)
+	assert.Equal(t, "5", cancelled[2].OrderID)
+	require.Len(t, errs, 1, "no cancel may fail on its nonce")
+	assert.Error(t, errs["4"])
+}
+
+func TestOrderAPI_GetOrderStatus_IncludeTrades(t *testing.T) {
+	var payloads []map[string]interface{}
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		payloads = append(payloads, decodeTestPayload(t, r))
+		_, _ = w.Write([]byte(`{"order_id":"44375901","symbol":"btcusd","side":"buy","type":"exchange limit","is_live":false,"executed_amount":"0.5","original_amount":"0.5","trades":[` +
+			`{"price":"3633.00","amount":"0.2","timestamp":1547236333,"timestampms":1547236333253,"type":"Buy","aggressor":true,"fee_currency":"USD","fee_amount":"0.0072","tid":44375902,"order_id":"44375901","exchange":"gemini","is_auction_fill":false},` +
+			`{"price":"3633.10","amount":"0.3","timestamp":1547236334,"timestampms":1547236334101,"type":"Buy","aggressor":true,"fee_currency":"USD","fee_amount":"0.0109","tid":"44375903","order_id":"44375901","exchange":"gemini","is_auction_fill":false}]}`))
+	})
+
+	order, err := g.Order.GetOrderStatus(context.Background(), "44375901", "", true, "")
+	require.NoError(t, err)
+	assert.Equal(t, true, payloads[0]["include_trades"])
+	require.Len(t, order.Trades, 2)
+	assert.Equal(t, FlexInt(44375902), order.Trades[0].TID)
+	assert.Equal(t, "3633.10", order.Trades[1].Price)
+	assert.Equal(t, "0.0109", order.Trades[1].FeeAmount)
+	assert.Equal(t, FlexInt(44375903), order.Trades[1].TID)
+	assert.Equal(t, int64(1547236334101), order.Trades[1].ExecutedAt().UnixMilli())
+}
+
+func TestOrderAPI_ErrorResponseDetails(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"error","reason":"InvalidPrice","message":"Invalid price for symbol BTCUSD: 0.001","code":37}`))
+	})
+
+	_, err := g.Order.GetOrderStatus(context.Background(), "1", "", false, "")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrAPIError, errors.GetCode(err))
+
+	sdkErr, ok := err.(*errors.SDKError)
+	require.True(t, ok)
+	var details ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(sdkErr.Details), &details))
+	assert.Equal(t, "InvalidPrice", details.Reason)
+	assert.Equal(t, "Invalid price for symbol BTCUSD: 0.001", details.Message)
+	assert.JSONEq(t, `37`, string(details.Extra["code"]))
+}
+
+func TestOrderAPI_AuthErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected errors.ErrorCode
+	}{
+		{"invalid signature", http.StatusBadRequest, `{"result":"error","reason":"InvalidSignature","message":"InvalidSignature"}`, errors.ErrInvalidSignature},
+		{"missing signature", http.StatusBadRequest, `{"result":"error","reason":"MissingSignatureHeader","message":"Missing X-GEMINI-SIGNATURE header"}`, errors.ErrInvalidSignature},
+		{"invalid key", http.StatusBadRequest, `{"result":"error","reason":"InvalidApikey","message":"Invalid API key"}`, errors.ErrInvalidAPIKey},
+		{"missing key", http.StatusBadRequest, `{"result":"error","reason":"MissingApikeyHeader","message":"Missing X-GEMINI-APIKEY header"}`, errors.ErrInvalidAPIKey},
+		{"missing role", http.StatusForbidden, `{"result":"error","reason":"MissingRole","message":"To access this endpoint, you need to log in to the website and go to the settings page to assign one of these roles [Trader] to API key"}`, errors.ErrPermissionDenied},
+		{"forbidden with other reason", http.StatusForbidden, `{"result":"error","reason":"AccountClosed","message":"Account is closed"}`, errors.ErrPermissionDenied},
+		{"forbidden without body", http.StatusForbidden, `Forbidden`, errors.ErrPermissionDenied},
+		{"invalid nonce", http.StatusBadRequest, `{"result":"error","reason":"InvalidNonce","message":"Nonce has not increased"}`, errors.ErrInvalidNonce},
+		{"other API error", http.StatusBadRequest, `{"result":"error","reason":"InvalidPrice","message":"Invalid price for symbol BTCUSD: 0.001"}`, errors.ErrAPIError},
+		{"server error", http.StatusBadGateway, `Bad Gateway`, errors.ErrNetworkError},
+		{"invalid signature with 200", http.StatusOK, `{"result":"error","reason":"InvalidSignature","message":"InvalidSignature"}`, errors.ErrInvalidSignature},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			})
+
+			_, err := g.Order.GetOrderStatus(context.Background(), "1", "", false, "")
+			require.Error(t, err)
+			assert.Equal(t, test.expected, errors.GetCode(err), err.Error())
+		})
+	}
+}
+
+func TestOrderAPI_GetActiveOrders_ResponseShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		count    int
+		expected errors.ErrorCode
+	}{
+		{"empty array", ` []`, 0, ""},
+		{"orders", `[{"order_id":"1","is_live":true},{"order_id":"2","is_live":true}]`, 2, ""},
+		{"error object", `{"result":"error","reason":"InvalidPrice","message":"Invalid price for symbol BTCUSD: 0.001"}`, 0, errors.ErrAPIError},
+		{"error object without result", `{"reason":"Maintenance","message":"System is down for maintenance"}`, 0, errors.ErrAPIError},
+		{"unexpected object", `{"order_id":"1"}`, 0, errors.ErrInvalidResponse},
+		{"malformed", `[{"order_id":`, 0, errors.ErrDataParsingError},
+	}
+	for _, test := range tests {
+		g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(test.body))
+		})
+
+		orders, err := g.Order.GetActiveOrders(context.Background(), "")
+		if test.expected != "" {
+			require.Error(t, err, test.name)
+			assert.Equal(t, test.expected, errors.GetCode(err), test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Len(t, orders, test.count, test.name)
+	}
+}
+
+func TestOrderAPI_WaitForOrderTerminal(t *testing.T) {
+	var polls int
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls < 3 {
+			_, _ = w.Write([]byte(`{"order_id":"1","is_live":true,"executed_amount":"0","remaining_amount":"1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"order_id":"1","is_live":false,"executed_amount":"1","remaining_amount":"0"}`))
+	})
+
+	order, err := g.Order.WaitForOrderTerminal(context.Background(), "1", "", time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, "1", order.ExecutedAmount)
+	assert.False(t, order.IsLive)
+}
+
+func TestOrderAPI_WaitForOrderTerminal_ContextDone(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"order_id":"1","is_live":true,"remaining_amount":"1"}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	order, err := g.Order.WaitForOrderTerminal(ctx, "1", "", time.Hour)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrTimeout, errors.GetCode(err))
+	assert.Less(t, time.Since(started), time.Second)
+	require.NotNil(t, order)
+	assert.True(t, order.IsLive)
+}
+
+// decodeTestPayload decodes the base64 X-GEMINI-PAYLOAD header of a private request
+func decodeTestPayload(t *testing.T, r *http.Request) map[string]interface{} {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(r.Header.Get("X-GEMINI-PAYLOAD"))
+	require.NoError(t, err)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &payload))
+	return payload
+}
+
+func TestOrder_HasOption(t *testing.T) {
+	var order Order
+	require.NoError(t, json.Unmarshal([]byte(`{"order_id":"1","options":["Maker-Or-Cancel","some-future-option"]}`), &order))
+
+	assert.True(t, order.HasOption(OrderOptionMakerOrCancel))
+	assert.True(t, order.IsMakerOrCancel())
+	assert.False(t, order.HasOption(OrderOptionImmediateOrCancel))
+	assert.False(t, (&Order{}).IsMakerOrCancel())
+
+	option, ok := ParseOrderOption(" fill-or-kill ")
+	assert.True(t, ok)
+	assert.Equal(t, OrderOptionFillOrKill, option)
+	_, ok = ParseOrderOption("some-future-option")
+	assert.False(t, ok)
+
+	req := &NewOrderRequest{Options: []string{"maker-or-cancel", "fill-or-kill", "some-future-option"}}
+	assert.Equal(t, []OrderOption{OrderOptionFillOrKill}, order.MissingOptions(req))
+	assert.Empty(t, order.MissingOptions(&NewOrderRequest{Options: []string{"maker-or-cancel"}}))
+	assert.Empty(t, order.MissingOptions(nil))
+}
@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"sort"
+	"sync"
+)
+
+// OrderTracker keeps a local view of orders placed through an OrderAPI.
+//
+// Once set with OrderAPI.SetOrderTracker, every order returned by PlaceOrder, CancelOrder,
+// CancelOrderByClientID and GetOrderStatus is recorded, and each GetActiveOrders result is
+// reconciled against the tracked open orders. The SDK has no order events stream, so fills
+// and cancels made elsewhere are only seen on those calls; callers consuming Gemini's order
+// events WebSocket themselves can feed the tracker with Track. A tracker assumes all of its
+// orders belong to one account. It is safe for concurrent use.
+type OrderTracker struct {
+	orders     map[string]Order  // by order ID
+	byClientID map[string]string // client order ID to order ID
+	mu         sync.RWMutex
+}
+
+// NewOrderTracker creates an empty order tracker
+func NewOrderTracker() *OrderTracker {
+	return &OrderTracker{
+		orders:     make(map[string]Order),
+		byClientID: make(map[string]string),
+	}
+}
+
+// Track records the latest known state of an order. Orders without an order ID are ignored.
+func (t *OrderTracker) Track(order Order) {
+	if order.OrderID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.track(order)
+}
+
+// track records an order. Must be called with t.mu held.
+func (t *OrderTracker) track(order Order) {
+	// Cancel responses may omit the client order ID, so keep the one already known
+	if previous, ok := t.orders[order.OrderID]; ok && order.ClientOrderID == "" {
+		order.ClientOrderID = previous.ClientOrderID
+	}
+	t.orders[order.OrderID] = order
+	if order.ClientOrderID != "" {
+		t.byClientID[order.ClientOrderID] = order.OrderID
+	}
+}
+
+// Reconcile updates the tracker from the complete list of active orders on the exchange.
+// Tracked open orders missing from active are no longer live and are marked closed; whether
+// they filled or were cancelled is only known after fetching their status.
+func (t *OrderTracker) Reconcile(active []Order) {
+	live := make(map[string]bool, len(active))
+	for _, order := range active {
+		live[order.OrderID] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, order := range t.orders {
+		if order.IsLive && !live[id] {
+			order.IsLive = false
+			t.orders[id] = order
+		}
+	}
+	for _, order := range active {
+		if order.OrderID != "" {
+			t.track(order)
+		}
+	}
+}
+
+// Get returns the tracked order with the given order ID
+func (t *OrderTracker) Get(orderID string) (Order, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	order, ok := t.orders[orderID]
+	return order, ok
+}
+
+// ByClientID returns the tracked order placed with the given client order ID
+func (t *OrderTracker) ByClientID(clientOrderID string) (Order, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	order, ok := t.orders[t.byClientID[clientOrderID]]
+	return order, ok
+}
+
+// Open returns the tracked orders that are still live, sorted by order ID
+func (t *OrderTracker) Open() []Order {
+	return t.ByStatus(OrderStatusOpen)
+}
+
+// ByStatus returns the tracked orders with the given status, sorted by order ID
+func (t *OrderTracker) ByStatus(status OrderStatus) []Order {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	orders := make([]Order, 0)
+	for _, order := range t.orders {
+		if order.Status() == status {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderID < orders[j].OrderID })
+	return orders
+}
+
+// SetOrderTracker sets the tracker that records orders handled by this API. A nil tracker
+// disables tracking. It is safe to call while requests are in flight.
+func (o *OrderAPI) SetOrderTracker(tracker *OrderTracker) {
+	o.tracker.Store(tracker)
+}
+
+// OrderTracker returns the tracker set with SetOrderTracker, or nil
+func (o *OrderAPI) OrderTracker() *OrderTracker {
+	return o.tracker.Load()
+}
+
+// trackOrder records a successfully returned order if tracking is enabled
+func (o *OrderAPI) trackOrder(order *Order, err error) {
+	if tracker := o.tracker.Load(); tracker != nil && err == nil && order != nil {
+		tracker.Track(*order)
+	}
+}
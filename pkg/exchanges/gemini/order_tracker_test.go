@@ -0,0 +1,143 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderTracker_Queries(t *testing.T) {
+	tracker := NewOrderTracker()
+	tracker.Track(Order{OrderID: "2", ClientOrderID: "b", IsLive: true})
+	tracker.Track(Order{OrderID: "1", ClientOrderID: "a", IsLive: true})
+	tracker.Track(Order{OrderID: "3", IsCancelled: true})
+	tracker.Track(Order{ClientOrderID: "ignored"})
+
+	open := tracker.Open()
+	require.Len(t, open, 2)
+	assert.Equal(t, "1", open[0].OrderID)
+	assert.Equal(t, "2", open[1].OrderID)
+
+	order, ok := tracker.ByClientID("b")
+	require.True(t, ok)
+	assert.Equal(t, "2", order.OrderID)
+	_, ok = tracker.ByClientID("ignored")
+	assert.False(t, ok)
+
+	assert.Len(t, tracker.ByStatus(OrderStatusCancelled), 1)
+	assert.Empty(t, tracker.ByStatus(OrderStatusClosed))
+
+	// A cancel response without the client order ID keeps the known one
+	tracker.Track(Order{OrderID: "2", IsCancelled: true})
+	order, ok = tracker.ByClientID("b")
+	require.True(t, ok)
+	assert.Equal(t, OrderStatusCancelled, order.Status())
+}
+
+func TestOrderTracker_Reconcile(t *testing.T) {
+	tracker := NewOrderTracker()
+	tracker.Track(Order{OrderID: "1", IsLive: true})
+	tracker.Track(Order{OrderID: "2", IsLive: true})
+
+	tracker.Reconcile([]Order{{OrderID: "2", IsLive: true}, {OrderID: "4", IsLive: true}})
+
+	order, ok := tracker.Get("1")
+	require.True(t, ok)
+	assert.Equal(t, OrderStatusClosed, order.Status())
+
+	open := tracker.Open()
+	require.Len(t, open, 2)
+	assert.Equal(t, "2", open[0].OrderID)
+	assert.Equal(t, "4", open[1].OrderID)
+}
+
+func TestOrderTracker_ConcurrentAccess(t *testing.T) {
+	tracker := NewOrderTracker()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			id := strconv.Itoa(i)
+			tracker.Track(Order{OrderID: id, ClientOrderID: "c" + id, IsLive: true})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = tracker.Open()
+			_, _ = tracker.ByClientID("c1")
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, tracker.Open(), 50)
+}
+
+func TestOrderAPI_SetOrderTracker(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/order/new":
+			_, _ = w.Write([]byte(`{"order_id":"10","client_order_id":"bot-1","is_live":true}`))
+		case "/v1/order/cancel":
+			_, _ = w.Write([]byte(`{"order_id":"10","is_live":false,"is_cancelled":true}`))
+		case "/v1/orders":
+			_, _ = w.Write([]byte(`[{"order_id":"11","is_live":true}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	tracker := NewOrderTracker()
+	g.Order.SetOrderTracker(tracker)
+	ctx := context.Background()
+
+	req := &NewOrderRequest{Symbol: "btcusd", Amount: "1", Price: "100.00", Side: OrderSideBuy, Type: OrderTypeExchangeLimit, ClientOrderID: "bot-1"}
+	_, err := g.Order.PlaceOrder(ctx, req)
+	require.NoError(t, err)
+	order, ok := tracker.ByClientID("bot-1")
+	require.True(t, ok)
+	assert.Equal(t, OrderStatusOpen, order.Status())
+
+	_, err = g.Order.CancelOrderByClientID(ctx, "bot-1", "")
+	require.NoError(t, err)
+	order, _ = tracker.ByClientID("bot-1")
+	assert.Equal(t, OrderStatusCancelled, order.Status())
+
+	_, err = g.Order.GetActiveOrders(ctx, "")
+	require.NoError(t, err)
+	open := tracker.Open()
+	require.Len(t, open, 1)
+	assert.Equal(t, "11", open[0].OrderID)
+}
+
+func TestOrderAPI_SetOrderTracker_Concurrent(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"order_id":"1","is_live":true}]`))
+	})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			g.Order.SetOrderTracker(NewOrderTracker())
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := g.Order.GetActiveOrders(ctx, "")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	tracker := NewOrderTracker()
+	g.Order.SetOrderTracker(tracker)
+	assert.Same(t, tracker, g.Order.OrderTracker())
+	g.Order.SetOrderTracker(nil)
+	assert.Nil(t, g.Order.OrderTracker())
+}
@@ -0,0 +1,198 @@
+package gemini
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// incrementTolerance is the relative tolerance used when checking increments
+const incrementTolerance = 1e-9
+
+// RoundingMode selects how a value is rounded to an increment
+type RoundingMode int
+
+const (
+	// RoundBySide picks the mode from the order side: buy prices round down and sell
+	// prices round up, so rounding never makes an order more aggressive. Orders
+	// without a side round to the nearest increment.
+	RoundBySide RoundingMode = iota
+	// RoundDown rounds toward zero to the increment below
+	RoundDown
+	// RoundUp rounds away from zero to the increment above
+	RoundUp
+	// RoundNearest rounds to the closest increment, with halves rounding up
+	RoundNearest
+)
+
+// SetAutoRound controls whether ValidateOrder rounds price and amount to the symbol's
+// increments instead of rejecting them. While enabled, PlaceOrder, and so ReplaceOrder and
+// Gemini.PlaceOrder, validates and rounds a copy of every order before sending it, leaving the
+// caller's request unchanged; the returned order carries the rounded values. Prices round
+// according to the rounding mode (see SetRoundingMode) and amounts according to the amount
+// rounding mode, which by default rounds down so an order never exceeds the requested size.
+func (o *OrderAPI) SetAutoRound(enabled bool) {
+	o.autoRound = enabled
+}
+
+// SetRoundingMode sets how ValidateOrder rounds prices when auto-rounding is enabled.
+// The default, RoundBySide, rounds buy prices down and sell prices up.
+func (o *OrderAPI) SetRoundingMode(mode RoundingMode) {
+	o.roundingMode = mode
+}
+
+// SetAmountRoundingMode sets how ValidateOrder rounds amounts to the symbol's amount
+// increment when auto-rounding is enabled. The default, RoundDown, never increases the
+// order size. RoundUp and RoundNearest can: a buy rounded up spends more quote currency
+// than intended and a sell rounded up sells more than the caller may hold. RoundBySide
+// has no meaning for amounts and selects RoundDown.
+func (o *OrderAPI) SetAmountRoundingMode(mode RoundingMode) {
+	o.amountMode = mode
+}
+
+// RoundToIncrement rounds value to a multiple of increment using mode and formats it with
+// as many decimals as the increment has. RoundBySide has no side to go by here and rounds
+// to the nearest increment. A non-positive increment leaves value unrounded.
+func RoundToIncrement(value, increment float64, mode RoundingMode) string {
+	return formatIncrement(roundToIncrement(value, increment, mode), increment)
+}
+
+// roundingModeForSide resolves RoundBySide to the mode for an order side
+func roundingModeForSide(mode RoundingMode, side OrderSide) RoundingMode {
+	if mode != RoundBySide {
+		return mode
+	}
+	switch side {
+	case OrderSideBuy:
+		return RoundDown
+	case OrderSideSell:
+		return RoundUp
+	default:
+		return RoundNearest
+	}
+}
+
+// roundToIncrement rounds value to a multiple of increment, allowing for float noise so
+// values already on an increment stay there and halves such as 0.35 by 0.1 round up.
+func roundToIncrement(value, increment float64, mode RoundingMode) float64 {
+	if increment <= 0 {
+		return value
+	}
+	ratio := value / increment
+	switch mode {
+	case RoundDown:
+		ratio = math.Floor(ratio + incrementTolerance)
+	case RoundUp:
+		ratio = math.Ceil(ratio - incrementTolerance)
+	default:
+		ratio = math.Floor(ratio + 0.5 + incrementTolerance)
+	}
+	return ratio * increment
+}
+
+// ValidateOrder checks an order against the symbol's trading constraints before it is sent.
+// Symbol details are served from the shared cache when available. Gemini's quote_increment
+// is the price tick and tick_size is the amount increment; min_order_size is the minimum amount.
+// Returns ErrInvalidInput describing the first violated constraint. With auto-rounding
+// enabled, price and amount are rounded in place instead of failing on increments.
+func (o *OrderAPI) ValidateOrder(ctx context.Context, req *NewOrderRequest) error {
+	if req == nil {
+		return errors.New(errors.ErrInvalidInput, "order request is required")
+	}
+	if req.Symbol == "" {
+		return errors.New(errors.ErrInvalidInput, "order symbol is required")
+	}
+
+	details, err := o.symbolDetails(ctx, req.Symbol)
+	if err != nil {
+		return err
+	}
+
+	return validateOrderAgainstSymbol(req, details, o.autoRound, o.roundingMode, o.amountMode)
+}
+
+// symbolDetails returns a symbol's details from the shared cache, fetching them on a miss
+func (o *OrderAPI) symbolDetails(ctx context.Context, symbol string) (SymbolDetails, error) {
+	if details, ok := o.gemini.symbols.getDetails(symbol); ok {
+		return details, nil
+	}
+	fetched, err := o.gemini.Market.GetSymbolDetails(ctx, symbol)
+	if err != nil {
+		return SymbolDetails{}, err
+	}
+	return *fetched, nil
+}
+
+// validateOrderAgainstSymbol checks amount and price against symbol constraints. When
+// auto-rounding, prices round with mode and amounts with amountMode. Orders sized by
+// TotalSpend, such as market buys, carry no amount, so the amount checks are skipped. Rounded
+// values are written to req only once every check has passed.
+func validateOrderAgainstSymbol(req *NewOrderRequest, details SymbolDetails, autoRound bool, mode, amountMode RoundingMode) error {
+	amountText := req.Amount
+	if req.TotalSpend == "" {
+		amount, err := parseFloatFromString(req.Amount)
+		if err != nil || amount <= 0 {
+			return errors.Newf(errors.ErrInvalidInput, "invalid order amount: %q", req.Amount)
+		}
+
+		if autoRound && details.TickSize > 0 {
+			if amountMode == RoundBySide {
+				amountMode = RoundDown
+			}
+			amount = roundToIncrement(amount, float64(details.TickSize), amountMode)
+			amountText = formatIncrement(amount, float64(details.TickSize))
+		} else if !isMultiple(amount, float64(details.TickSize)) {
+			return errors.Newf(errors.ErrInvalidInput, "amount %s is not a multiple of amount increment %v for %s", req.Amount, details.TickSize, details.Symbol)
+		}
+
+		minOrderSize, err := parseFloatFromString(details.MinOrderSize)
+		if err == nil && minOrderSize > 0 && amount < minOrderSize {
+			return errors.Newf(errors.ErrInvalidInput, "amount %s is below minimum order size %s for %s", amountText, details.MinOrderSize, details.Symbol)
+		}
+	}
+
+	priceText := req.Price
+	isMarket := req.Type == OrderTypeMarketBuy || req.Type == OrderTypeMarketSell
+	if req.Price == "" {
+		if !isMarket {
+			return errors.Newf(errors.ErrInvalidInput, "price is required for %s orders", req.Type)
+		}
+	} else {
+		price, err := parseFloatFromString(req.Price)
+		if err != nil || price <= 0 {
+			return errors.Newf(errors.ErrInvalidInput, "invalid order price: %q", req.Price)
+		}
+
+		if autoRound && details.QuoteIncrement > 0 {
+			price = roundToIncrement(price, float64(details.QuoteIncrement), roundingModeForSide(mode, req.Side))
+			priceText = formatIncrement(price, float64(details.QuoteIncrement))
+		} else if !isMultiple(price, float64(details.QuoteIncrement)) {
+			return errors.Newf(errors.ErrInvalidInput, "price %s is not a multiple of price increment %v for %s", req.Price, details.QuoteIncrement, details.Symbol)
+		}
+	}
+
+	req.Amount = amountText
+	req.Price = priceText
+	return nil
+}
+
+// isMultiple reports whether value is a whole multiple of increment, within tolerance.
+// A non-positive increment means the constraint is unknown and always passes.
+func isMultiple(value, increment float64) bool {
+	if increment <= 0 {
+		return true
+	}
+	ratio := value / increment
+	return math.Abs(ratio-math.Round(ratio)) <= incrementTolerance*math.Max(1, math.Abs(ratio))
+}
+
+// formatIncrement formats value with as many decimals as the increment has
+func formatIncrement(value, increment float64) string {
+	decimals := 0
+	if increment > 0 && increment < 1 {
+		decimals = int(math.Ceil(-math.Log10(increment) - incrementTolerance))
+	}
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
@@ -0,0 +1,109 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// productTypeSwap is the symbol details product type of perpetual contracts
+const productTypeSwap = "swap"
+
+// FundingAmount represents the funding of a perpetual contract from /v1/fundingamount
+type FundingAmount struct {
+	Symbol                 string    `json:"symbol"`
+	FundingDateTime        string    `json:"fundingDateTime"`
+	FundingTimestampMs     FlexInt   `json:"fundingTimestampMilliSecs"` // last funding, in milliseconds
+	NextFundingTimestampMs FlexInt   `json:"nextFundingTimestamp"`      // next funding, in milliseconds
+	Amount                 FlexFloat `json:"amount"`                    // last funding amount per contract
+	EstimatedFundingAmount FlexFloat `json:"estimatedFundingAmount"`    // estimate for the next funding
+}
+
+// PerpetualDetails describes a perpetual contract for sizing positions
+//
+// Gemini's public API does not publish a contract multiplier, mark price, index price or
+// maintenance margin rate per product, so they are not included. Gemini perpetuals are linear:
+// one contract is one unit of the base currency, settled in ContractPriceCurrency.
+type PerpetualDetails struct {
+	Symbol                 string        `json:"symbol"`
+	BaseCurrency           string        `json:"base_currency"`
+	ContractType           string        `json:"contract_type"`           // e.g. "linear"
+	ContractPriceCurrency  string        `json:"contract_price_currency"` // settlement currency, e.g. "GUSD"
+	TickSize               float64       `json:"tick_size"`               // amount increment
+	QuoteIncrement         float64       `json:"quote_increment"`         // price tick
+	MinOrderSize           string        `json:"min_order_size"`
+	FundingInterval        time.Duration `json:"funding_interval"` // 0 if Gemini reports no next funding
+	LastFundingTime        time.Time     `json:"last_funding_time"`
+	NextFundingTime        time.Time     `json:"next_funding_time"`
+	LastFundingAmount      float64       `json:"last_funding_amount"`
+	EstimatedFundingAmount float64       `json:"estimated_funding_amount"`
+}
+
+// GetPerpetualDetails fetches the contract specification and funding schedule of a perpetual
+// contract such as btcgusdperp. Symbol details are served from the shared cache when available.
+// Symbols that are not perpetual contracts fail with ErrInvalidSymbol.
+func (m *MarketAPI) GetPerpetualDetails(ctx context.Context, symbol string) (*PerpetualDetails, error) {
+	if symbol == "" {
+		return nil, errors.New(errors.ErrInvalidInput, "symbol is required")
+	}
+
+	details, ok := m.gemini.symbols.getDetails(symbol)
+	if !ok {
+		fetched, err := m.GetSymbolDetails(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		details = *fetched
+	}
+	if !strings.EqualFold(details.ProductType, productTypeSwap) {
+		return nil, errors.Newf(errors.ErrInvalidSymbol, "%s is not a perpetual contract (product type %q)", symbol, details.ProductType)
+	}
+
+	funding, err := m.GetFundingAmount(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	perpetual := &PerpetualDetails{
+		Symbol:                 details.Symbol,
+		BaseCurrency:           details.BaseCurrency,
+		ContractType:           details.ContractType,
+		ContractPriceCurrency:  details.ContractPriceCurrency,
+		TickSize:               float64(details.TickSize),
+		QuoteIncrement:         float64(details.QuoteIncrement),
+		MinOrderSize:           details.MinOrderSize,
+		LastFundingTime:        timestampTime(funding.FundingTimestampMs, 0),
+		NextFundingTime:        timestampTime(funding.NextFundingTimestampMs, 0),
+		LastFundingAmount:      float64(funding.Amount),
+		EstimatedFundingAmount: float64(funding.EstimatedFundingAmount),
+	}
+	if !perpetual.LastFundingTime.IsZero() && perpetual.NextFundingTime.After(perpetual.LastFundingTime) {
+		perpetual.FundingInterval = perpetual.NextFundingTime.Sub(perpetual.LastFundingTime)
+	}
+	return perpetual, nil
+}
+
+// GetFundingAmount fetches the last and estimated next funding of a perpetual contract
+func (m *MarketAPI) GetFundingAmount(ctx context.Context, symbol string) (*FundingAmount, error) {
+	url := fmt.Sprintf("%s/v1/fundingamount/%s", m.gemini.getBaseURL(), symbol)
+
+	m.gemini.logger.Debug().Str("url", url).Str("symbol", symbol).Msg("Fetching funding amount")
+
+	// This is a public API, no authentication required
+	response, err := m.gemini.client.GetWithType(ctx, url, client.APITypePublic)
+	if err != nil {
+		return nil, requestError("failed to fetch funding amount", err)
+	}
+
+	var funding FundingAmount
+	if err := jsonUnmarshal(response, &funding); err != nil {
+		return nil, parseError(response, url, "failed to parse funding amount response", err)
+	}
+
+	m.gemini.logger.Debug().Str("symbol", symbol).Float64("amount", float64(funding.Amount)).Msg("Successfully fetched funding amount")
+	return &funding, nil
+}
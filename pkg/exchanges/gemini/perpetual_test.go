@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketAPI_GetPerpetualDetails(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/symbols/details/btcgusdperp":
+			_, _ = w.Write([]byte(`{"symbol":"BTCGUSDPERP","base_currency":"BTC","quote_currency":"GUSD","tick_size":0.0001,"quote_increment":0.5,` +
+				`"min_order_size":"0.0001","status":"open","wrap_enabled":false,"product_type":"swap","contract_type":"linear","contract_price_currency":"GUSD"}`))
+		case "/v1/symbols/details/btcusd":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSD","base_currency":"BTC","quote_currency":"USD","tick_size":1e-8,"quote_increment":0.01,` +
+				`"min_order_size":"0.00001","status":"open","wrap_enabled":false,"product_type":"spot","contract_type":"vanilla","contract_price_currency":"USD"}`))
+		case "/v1/fundingamount/btcgusdperp":
+			_, _ = w.Write([]byte(`{"symbol":"btcgusdperp","fundingDateTime":"2023-06-12T03:00:00.000Z","fundingTimestampMilliSecs":1686538800000,` +
+				`"nextFundingTimestamp":1686542400000,"amount":0.51692,"estimatedFundingAmount":0.14291}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	perpetual, err := g.Market.GetPerpetualDetails(ctx, "btcgusdperp")
+	require.NoError(t, err)
+	assert.Equal(t, "BTCGUSDPERP", perpetual.Symbol)
+	assert.Equal(t, "linear", perpetual.ContractType)
+	assert.Equal(t, "GUSD", perpetual.ContractPriceCurrency)
+	assert.Equal(t, 0.5, perpetual.QuoteIncrement)
+	assert.Equal(t, time.Hour, perpetual.FundingInterval)
+	assert.True(t, time.Date(2023, 6, 12, 4, 0, 0, 0, time.UTC).Equal(perpetual.NextFundingTime), perpetual.NextFundingTime)
+	assert.Equal(t, 0.51692, perpetual.LastFundingAmount)
+	assert.Equal(t, 0.14291, perpetual.EstimatedFundingAmount)
+
+	_, err = g.Market.GetPerpetualDetails(ctx, "btcusd")
+	assert.Equal(t, errors.ErrInvalidSymbol, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "not a perpetual contract")
+
+	_, err = g.Market.GetPerpetualDetails(ctx, "")
+	assert.Equal(t, errors.ErrInvalidInput, errors.GetCode(err))
+}
@@ -0,0 +1,142 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// signedCall describes a signed request to a private endpoint
+type signedCall struct {
+	endpoint string
+	payload  interface{} // map or request struct; nil sends only the request and nonce fields
+	weight   int         // private rate limit weight, 1 when zero
+
+	// withoutDefaultAccount signs the payload as is, for endpoints that act on the master
+	// group or name their accounts explicitly
+	withoutDefaultAccount bool
+
+	// requestError converts a failed request into the returned error; requestError is used
+	// when nil
+	requestError func(err error) error
+}
+
+// doSignedPost sends a signed request to the private endpoint and decodes the response into out.
+// payload may be a map or a request struct; its request and nonce fields are set, and the
+// default account is added when payload names none, before it is signed. Gemini error objects
+// are returned as API errors. When out points to a slice the response must be a JSON array, as
+// with decodeListResponse; a nil out only checks for an error object. The request counts once
+// against the private rate limit.
+func (g *Gemini) doSignedPost(ctx context.Context, endpoint string, payload interface{}, out interface{}) error {
+	return g.doSigned(ctx, signedCall{endpoint: endpoint, payload: payload}, out)
+}
+
+// doSigned sends call as doSignedPost does, with the weight, signing and request error
+// handling it names
+func (g *Gemini) doSigned(ctx context.Context, call signedCall, out interface{}) error {
+	if !g.hasCredentials() {
+		return errors.New(errors.ErrInvalidInput, "API key and secret are required for private endpoints")
+	}
+
+	url := fmt.Sprintf("%s%s", g.getBaseURL(), call.endpoint)
+
+	weight := call.weight
+	if weight <= 0 {
+		weight = 1
+	}
+
+	g.logger.Debug().Str("url", url).Int("weight", weight).Msg("Sending signed request")
+
+	// The nonce is drawn only once the request is next in line to be sent, so concurrent
+	// requests reach Gemini in nonce order
+	var signErr error
+	response, err := g.postPrivate(ctx, url, weight, func() (map[string]string, error) {
+		headers, err := g.signCall(ctx, call)
+		signErr = err
+		return headers, err
+	})
+	if signErr != nil {
+		return signErr
+	}
+	if err != nil {
+		if call.requestError != nil {
+			return call.requestError(err)
+		}
+		return requestError(fmt.Sprintf("failed to request %s", call.endpoint), err)
+	}
+
+	message := fmt.Sprintf("failed to parse %s response", call.endpoint)
+	if t := reflect.TypeOf(out); t != nil && t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Slice {
+		return decodeListResponse(response, url, out, message)
+	}
+
+	var errorResp ErrorResponse
+	if err := jsonUnmarshal(response, &errorResp); err == nil && errorResp.Result == errorStatus {
+		return errorResp.Err()
+	}
+	if out == nil {
+		return nil
+	}
+	if err := jsonUnmarshal(response, out); err != nil {
+		return parseError(response, url, message, err)
+	}
+	return nil
+}
+
+// signCall draws the nonce for call and returns the signed request headers
+func (g *Gemini) signCall(ctx context.Context, call signedCall) (map[string]string, error) {
+	nonce, err := g.nextNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payloadBytes, err := signedPayload(call.endpoint, nonce, call.payload)
+	if err != nil {
+		return nil, err
+	}
+	if call.withoutDefaultAccount {
+		return g.sign(call.endpoint, payloadBytes)
+	}
+	return g.signRequest(call.endpoint, payloadBytes)
+}
+
+// signedPayload encodes payload with the request and nonce fields set. Maps are copied and
+// request structs, which carry Request and Nonce fields, are set on a copy, so the caller's
+// payload is never modified.
+func signedPayload(endpoint, nonce string, payload interface{}) ([]byte, error) {
+	var request interface{}
+	switch p := payload.(type) {
+	case nil:
+		request = map[string]any{"request": endpoint, "nonce": nonce}
+	case map[string]any:
+		fields := make(map[string]any, len(p)+2)
+		for k, v := range p {
+			fields[k] = v
+		}
+		fields["request"] = endpoint
+		fields["nonce"] = nonce
+		request = fields
+	default:
+		value := reflect.Indirect(reflect.ValueOf(payload))
+		if value.Kind() != reflect.Struct {
+			return nil, errors.Newf(errors.ErrInvalidInput, "unsupported %s request payload %T", endpoint, payload)
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for name, field := range map[string]string{"Request": endpoint, "Nonce": nonce} {
+			f := copied.FieldByName(name)
+			if !f.IsValid() || f.Kind() != reflect.String {
+				return nil, errors.Newf(errors.ErrInvalidInput, "%s request payload %T has no %s field", endpoint, payload, name)
+			}
+			f.SetString(field)
+		}
+		request = copied.Interface()
+	}
+
+	payloadBytes, err := jsonMarshal(request)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrDataParsingError, err, "failed to marshal %s request", endpoint)
+	}
+	return payloadBytes, nil
+}
@@ -0,0 +1,75 @@
+package gemini
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// symbolCacheTTL is how long symbol lists and details are reused before being refetched
+const symbolCacheTTL = time.Minute
+
+// symbolCacheEntry holds cached details for a single symbol
+type symbolCacheEntry struct {
+	details   SymbolDetails
+	fetchedAt time.Time
+}
+
+// symbolCache shares symbol lists and details between market data calls
+type symbolCache struct {
+	ttl       time.Duration
+	symbols   []string
+	symbolsAt time.Time
+	details   map[string]symbolCacheEntry
+	mu        sync.Mutex
+}
+
+// newSymbolCache creates a new symbol cache
+func newSymbolCache(ttl time.Duration) *symbolCache {
+	return &symbolCache{
+		ttl:     ttl,
+		details: make(map[string]symbolCacheEntry),
+	}
+}
+
+// getSymbols returns the cached symbol list if it is still fresh
+func (c *symbolCache) getSymbols() ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.symbols == nil || time.Since(c.symbolsAt) > c.ttl {
+		return nil, false
+	}
+	symbols := make([]string, len(c.symbols))
+	copy(symbols, c.symbols)
+	return symbols, true
+}
+
+// putSymbols stores the symbol list
+func (c *symbolCache) putSymbols(symbols []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.symbols = make([]string, len(symbols))
+	copy(c.symbols, symbols)
+	c.symbolsAt = time.Now()
+}
+
+// getDetails returns cached details for a symbol if they are still fresh
+func (c *symbolCache) getDetails(symbol string) (SymbolDetails, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.details[strings.ToLower(symbol)]
+	if !ok || time.Since(entry.fetchedAt) > c.ttl {
+		return SymbolDetails{}, false
+	}
+	return entry.details, true
+}
+
+// putDetails stores details for a symbol
+func (c *symbolCache) putDetails(details SymbolDetails) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details[strings.ToLower(details.Symbol)] = symbolCacheEntry{
+		details:   details,
+		fetchedAt: time.Now(),
+	}
+}
@@ -0,0 +1,54 @@
+package gemini
+
+import (
+	"path"
+	"strings"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// symbolFilter selects symbols by case-insensitive glob patterns, as matched by path.Match
+type symbolFilter struct {
+	include []string // a symbol must match one of these; empty includes every symbol
+	exclude []string // a symbol matching any of these is left out, even if included
+}
+
+// newSymbolFilter validates and normalizes the patterns of a filter
+func newSymbolFilter(include, exclude []string) (*symbolFilter, error) {
+	f := &symbolFilter{}
+	for _, list := range []struct {
+		patterns []string
+		dst      *[]string
+	}{{include, &f.include}, {exclude, &f.exclude}} {
+		for _, pattern := range list.patterns {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, err, "invalid symbol pattern %q", pattern)
+			}
+			*list.dst = append(*list.dst, pattern)
+		}
+	}
+	return f, nil
+}
+
+// match reports whether symbol passes the filter. A nil filter passes every symbol.
+func (f *symbolFilter) match(symbol string) bool {
+	if f == nil {
+		return true
+	}
+	symbol = strings.ToLower(symbol)
+	if matchAny(f.exclude, symbol) {
+		return false
+	}
+	return len(f.include) == 0 || matchAny(f.include, symbol)
+}
+
+// matchAny reports whether symbol matches any of patterns
+func matchAny(patterns []string, symbol string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, symbol); ok {
+			return true
+		}
+	}
+	return false
+}
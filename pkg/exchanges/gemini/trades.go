@@ -0,0 +1,311 @@
+package gemini
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// pastTradesPageLimit is the number of trades requested per page, the maximum Gemini allows
+const pastTradesPageLimit = 500
+
+// PastTrade represents one of the account's own trades
+type PastTrade struct {
+	Price          string  `json:"price"`
+	Amount         string  `json:"amount"`
+	Timestamp      FlexInt `json:"timestamp"`   // Execution time in seconds
+	Timestampms    FlexInt `json:"timestampms"` // Execution time in milliseconds
+	Type           string  `json:"type"`
+	Aggressor      bool    `json:"aggressor"`
+	FeeCurrency    string  `json:"fee_currency"`
+	FeeAmount      string  `json:"fee_amount"`
+	TID            FlexInt `json:"tid"`
+	OrderID        string  `json:"order_id"`
+	ClientOrderID  string  `json:"client_order_id,omitempty"`
+	Exchange       string  `json:"exchange"`
+	IsAuctionFill  bool    `json:"is_auction_fill"`
+	IsClearingFill bool    `json:"is_clearing_fill"`
+	Symbol         string  `json:"symbol"`
+}
+
+// ExecutedAt returns the time the trade executed
+func (t *PastTrade) ExecutedAt() time.Time {
+	return timestampTime(t.Timestampms, int64(t.Timestamp))
+}
+
+// PastTradesRequest represents the request payload for getting past trades
+type PastTradesRequest struct {
+	Request     string `json:"request"`
+	Nonce       string `json:"nonce"`
+	Symbol      string `json:"symbol,omitempty"`
+	LimitTrades int    `json:"limit_trades,omitempty"`
+	// Timestamp returns only trades on or after this Unix time in milliseconds.
+	// Zero returns the most recent trades.
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Account   string `json:"account,omitempty"`
+}
+
+// GetPastTrades fetches a single page of the account's trades for a symbol, most recent first.
+// Use IteratePastTrades to walk the full history.
+// This implements the private API: https://docs.gemini.com/rest/orders#get-past-trades
+func (o *OrderAPI) GetPastTrades(ctx context.Context, req *PastTradesRequest) ([]PastTrade, error) {
+	trades, err := o.getPastTrades(ctx, req)
+	o.gemini.audit("GetPastTrades", func() map[string]interface{} {
+		if req == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"symbol":       req.Symbol,
+			"limit_trades": req.LimitTrades,
+			"timestamp":    req.Timestamp,
+			"account":      req.Account,
+		}
+	}, trades, err)
+	return trades, err
+}
+
+// getPastTrades fetches a page of past trades without auditing
+func (o *OrderAPI) getPastTrades(ctx context.Context, req *PastTradesRequest) ([]PastTrade, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrInvalidInput, "past trades request is required")
+	}
+	if req.LimitTrades < 0 || req.LimitTrades > pastTradesPageLimit {
+		return nil, errors.Newf(errors.ErrInvalidInput, "limit_trades must be between 0 and %d, got %d", pastTradesPageLimit, req.LimitTrades)
+	}
+
+	o.gemini.logger.Debug().Str("symbol", req.Symbol).Int64("timestamp", req.Timestamp).Msg("Fetching past trades")
+
+	var trades []PastTrade
+	if err := o.gemini.doSignedPost(ctx, "/v1/mytrades", req, &trades); err != nil {
+		return nil, err
+	}
+
+	o.gemini.logger.Debug().Int("count", len(trades)).Msg("Successfully fetched past trades")
+	return trades, nil
+}
+
+// PastTradesIterator walks the account's trade history for a symbol from oldest to newest,
+// one page at a time, so the full history never has to be held in memory.
+//
+//	it := g.Order.IteratePastTrades("btcusd", "", since)
+//	for it.Next(ctx) {
+//		trade := it.Trade()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PastTradesIterator struct {
+	api     *OrderAPI
+	symbol  string
+	account string
+	limit   int
+
+	// cursor is the timestamp in milliseconds of the next page request
+	cursor int64
+	// seen holds the IDs of trades already returned at the cursor timestamp, since the next
+	// page starts at that timestamp again
+	seen map[FlexInt]struct{}
+
+	page    []PastTrade
+	current PastTrade
+	done    bool
+	err     error
+}
+
+// IteratePastTrades returns an iterator over the account's trades for symbol made at or after
+// since, oldest first. A zero since starts from the first trade.
+func (o *OrderAPI) IteratePastTrades(symbol, account string, since time.Time) *PastTradesIterator {
+	cursor := int64(1)
+	if ms := since.UnixMilli(); !since.IsZero() && ms > cursor {
+		cursor = ms
+	}
+	return &PastTradesIterator{
+		api:     o,
+		symbol:  symbol,
+		account: account,
+		limit:   pastTradesPageLimit,
+		cursor:  cursor,
+		seen:    make(map[FlexInt]struct{}),
+	}
+}
+
+// Next advances to the next trade, fetching another page when needed. It returns false once
+// the history is exhausted or a request fails; check Err to tell the two apart.
+func (it *PastTradesIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch(ctx)
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Trade returns the trade Next advanced to
+func (it *PastTradesIterator) Trade() PastTrade {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *PastTradesIterator) Err() error {
+	return it.err
+}
+
+// fetch requests the page of trades starting at the cursor and queues the ones not yet
+// returned. Given a timestamp, Gemini returns the earliest trades at or after it, so each
+// page continues from the newest trade of the previous one. Pages overlap by one timestamp
+// so trades sharing the last timestamp of a page are not lost; they are deduplicated by ID.
+func (it *PastTradesIterator) fetch(ctx context.Context) {
+	trades, err := it.api.GetPastTrades(ctx, &PastTradesRequest{
+		Symbol:      it.symbol,
+		LimitTrades: it.limit,
+		Timestamp:   it.cursor,
+		Account:     it.account,
+	})
+	if err != nil {
+		it.err = err
+		return
+	}
+	if len(trades) < it.limit {
+		it.done = true
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		if trades[i].Timestampms != trades[j].Timestampms {
+			return trades[i].Timestampms < trades[j].Timestampms
+		}
+		return trades[i].TID < trades[j].TID
+	})
+
+	cursor := it.cursor
+	for _, trade := range trades {
+		if int64(trade.Timestampms) < it.cursor {
+			continue
+		}
+		if _, ok := it.seen[trade.TID]; ok {
+			continue
+		}
+		if int64(trade.Timestampms) > cursor {
+			cursor = int64(trade.Timestampms)
+			it.seen = make(map[FlexInt]struct{})
+		}
+		it.seen[trade.TID] = struct{}{}
+		it.page = append(it.page, trade)
+	}
+
+	if len(it.page) == 0 && !it.done {
+		// A full page of trades that were all returned before means more than a page of
+		// trades share the cursor timestamp. Move past it rather than request it forever.
+		it.api.gemini.logger.Warn().Int64("timestamp", cursor).Msg("More trades than a page share one timestamp; some may be skipped")
+		cursor++
+		it.seen = make(map[FlexInt]struct{})
+	}
+	it.cursor = cursor
+}
+
+// maxAllPastTrades caps the number of trades GetAllPastTrades collects in memory
+const maxAllPastTrades = 100000
+
+// GetAllPastTrades fetches every trade for symbol made at or after since, oldest first, paging
+// through the history with IteratePastTrades. Trades repeated across pages are returned once.
+//
+// To bound memory, at most 100,000 trades are collected; beyond that the trades gathered so far
+// are returned with ErrInvalidInput. Narrow since, or stream the history with IteratePastTrades
+// or ExportTrades instead.
+func (o *OrderAPI) GetAllPastTrades(ctx context.Context, symbol, account string, since time.Time) ([]PastTrade, error) {
+	var trades []PastTrade
+	it := o.IteratePastTrades(symbol, account, since)
+	for it.Next(ctx) {
+		if len(trades) == maxAllPastTrades {
+			return trades, errors.Newf(errors.ErrInvalidInput, "trade history for %s exceeds %d trades", symbol, maxAllPastTrades).
+				WithDetails("use a later since, IteratePastTrades or ExportTrades")
+		}
+		trades = append(trades, it.Trade())
+	}
+	if err := it.Err(); err != nil {
+		return trades, err
+	}
+	return trades, nil
+}
+
+// Trade export formats supported by ExportTrades
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// tradeCSVHeader lists the columns written by ExportTrades in CSV format
+var tradeCSVHeader = []string{
+	"tid", "order_id", "client_order_id", "symbol", "type", "price", "amount",
+	"fee_currency", "fee_amount", "timestampms", "aggressor", "is_auction_fill", "is_clearing_fill",
+}
+
+// ExportTrades writes the account's full trade history for symbol to w, oldest first, as CSV
+// with a header row or as NDJSON (one JSON object per line). Trades are written page by page
+// as they are fetched, so memory use does not grow with the size of the history. The default
+// account is used; on error, the trades written so far remain in w.
+func (o *OrderAPI) ExportTrades(ctx context.Context, symbol string, w io.Writer, format string) error {
+	var write func(trade PastTrade) error
+	var flush func() error
+	switch format {
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(tradeCSVHeader); err != nil {
+			return errors.Wrap(errors.ErrUnknown, "failed to write trade export", err)
+		}
+		write = func(trade PastTrade) error {
+			return writer.Write([]string{
+				strconv.FormatInt(int64(trade.TID), 10), trade.OrderID, trade.ClientOrderID, trade.Symbol,
+				trade.Type, trade.Price, trade.Amount, trade.FeeCurrency, trade.FeeAmount,
+				strconv.FormatInt(int64(trade.Timestampms), 10), strconv.FormatBool(trade.Aggressor),
+				strconv.FormatBool(trade.IsAuctionFill), strconv.FormatBool(trade.IsClearingFill),
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case ExportFormatNDJSON:
+		write = func(trade PastTrade) error {
+			line, err := jsonMarshal(trade)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(append(line, '\n'))
+			return err
+		}
+		flush = func() error { return nil }
+	default:
+		return errors.Newf(errors.ErrInvalidInput, "unsupported export format %q, expected %q or %q", format, ExportFormatCSV, ExportFormatNDJSON)
+	}
+
+	count := 0
+	it := o.IteratePastTrades(symbol, "", time.Time{})
+	for it.Next(ctx) {
+		if err := write(it.Trade()); err != nil {
+			return errors.Wrap(errors.ErrUnknown, "failed to write trade export", err)
+		}
+		count++
+		// Flush after every page so buffered rows never exceed one page
+		if count%pastTradesPageLimit == 0 {
+			if err := flush(); err != nil {
+				return errors.Wrap(errors.ErrUnknown, "failed to write trade export", err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return errors.Wrap(errors.ErrUnknown, "failed to write trade export", err)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	o.gemini.logger.Debug().Str("symbol", symbol).Int("count", count).Str("format", format).Msg("Exported trades")
+	return nil
+}
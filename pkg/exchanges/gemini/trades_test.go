@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTradeHistoryGemini serves /v1/mytrades from trades the way Gemini pages them: the
+// earliest limit_trades trades at or after timestamp, newest first
+func newTradeHistoryGemini(t *testing.T, trades []PastTrade) (*Gemini, *int) {
+	requests := 0
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		payload := decodeTestPayload(t, r)
+		assert.Equal(t, "/v1/mytrades", payload["request"])
+		timestamp := int64(payload["timestamp"].(float64))
+		limit := int(payload["limit_trades"].(float64))
+
+		var page []PastTrade
+		for _, trade := range trades {
+			if int64(trade.Timestampms) >= timestamp {
+				page = append(page, trade)
+			}
+		}
+		sort.SliceStable(page, func(i, j int) bool { return page[i].Timestampms < page[j].Timestampms })
+		if len(page) > limit {
+			page = page[:limit]
+		}
+		sort.SliceStable(page, func(i, j int) bool { return page[i].Timestampms > page[j].Timestampms })
+		body, _ := json.Marshal(page)
+		_, _ = w.Write(body)
+	})
+	return g, &requests
+}
+
+// tradeHistory builds n trades, two per millisecond so that pages end mid-timestamp
+func tradeHistory(n int) []PastTrade {
+	trades := make([]PastTrade, n)
+	for i := range trades {
+		trades[i] = PastTrade{
+			TID:         FlexInt(i + 1),
+			Timestampms: FlexInt(1700000000000 + int64(i/2)),
+			Symbol:      "BTCUSD",
+			Type:        "Buy",
+			Price:       "30000.00",
+			Amount:      "0.01",
+		}
+	}
+	return trades
+}
+
+func TestOrderAPI_IteratePastTrades(t *testing.T) {
+	history := tradeHistory(1201)
+	g, requests := newTradeHistoryGemini(t, history)
+
+	it := g.Order.IteratePastTrades("btcusd", "", time.Time{})
+	var tids []FlexInt
+	for it.Next(context.Background()) {
+		tids = append(tids, it.Trade().TID)
+	}
+	require.NoError(t, it.Err())
+
+	require.Len(t, tids, len(history))
+	for i, tid := range tids {
+		assert.Equal(t, FlexInt(i+1), tid)
+	}
+	assert.Equal(t, 3, *requests)
+}
+
+func TestOrderAPI_IteratePastTrades_Since(t *testing.T) {
+	g, _ := newTradeHistoryGemini(t, tradeHistory(10))
+
+	it := g.Order.IteratePastTrades("btcusd", "", time.UnixMilli(1700000000003))
+	var tids []FlexInt
+	for it.Next(context.Background()) {
+		tids = append(tids, it.Trade().TID)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []FlexInt{7, 8, 9, 10}, tids)
+}
+
+func TestOrderAPI_GetAllPastTrades(t *testing.T) {
+	history := tradeHistory(1001)
+	g, _ := newTradeHistoryGemini(t, history)
+
+	trades, err := g.Order.GetAllPastTrades(context.Background(), "btcusd", "", time.UnixMilli(1700000000100))
+	require.NoError(t, err)
+	require.Len(t, trades, len(history)-200)
+	seen := make(map[FlexInt]bool)
+	for _, trade := range trades {
+		assert.False(t, seen[trade.TID], "trade %d returned twice", trade.TID)
+		seen[trade.TID] = true
+	}
+	assert.Equal(t, FlexInt(201), trades[0].TID)
+	assert.Equal(t, FlexInt(1001), trades[len(trades)-1].TID)
+}
+
+func TestOrderAPI_ExportTrades(t *testing.T) {
+	g, _ := newTradeHistoryGemini(t, tradeHistory(3))
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	require.NoError(t, g.Order.ExportTrades(ctx, "btcusd", &out, ExportFormatCSV))
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, tradeCSVHeader, rows[0])
+	assert.Equal(t, "1", rows[1][0])
+	assert.Equal(t, "1700000000001", rows[3][9])
+
+	out.Reset()
+	require.NoError(t, g.Order.ExportTrades(ctx, "btcusd", &out, ExportFormatNDJSON))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	var trade PastTrade
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &trade))
+	assert.Equal(t, FlexInt(3), trade.TID)
+
+	assert.Error(t, g.Order.ExportTrades(ctx, "btcusd", &out, "xlsx"))
+}
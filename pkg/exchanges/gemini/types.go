@@ -1,20 +1,96 @@
 package gemini
 
 import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/client"
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+)
+
+// SymbolStatus represents the trading state of a symbol
+type SymbolStatus string
+
+const (
+	SymbolStatusOpen       SymbolStatus = "open"
+	SymbolStatusClosed     SymbolStatus = "closed"
+	SymbolStatusCancelOnly SymbolStatus = "cancel_only"
+	SymbolStatusPostOnly   SymbolStatus = "post_only"
+	SymbolStatusLimitOnly  SymbolStatus = "limit_only"
 )
 
 // Symbol represents a trading symbol from Gemini API
 type Symbol struct {
-	Symbol         string  `json:"symbol"`
-	BaseCurrency   string  `json:"base_currency"`
-	QuoteCurrency  string  `json:"quote_currency"`
-	TickSize       float64 `json:"tick_size"`
-	QuoteIncrement float64 `json:"quote_increment"`
-	MinOrderSize   string  `json:"min_order_size"`
-	Status         string  `json:"status"`
-	WrapEnabled    bool    `json:"wrap_enabled"`
+	Symbol         string       `json:"symbol"`
+	BaseCurrency   string       `json:"base_currency"`
+	QuoteCurrency  string       `json:"quote_currency"`
+	TickSize       FlexFloat    `json:"tick_size"`
+	QuoteIncrement FlexFloat    `json:"quote_increment"`
+	MinOrderSize   string       `json:"min_order_size"`
+	Status         SymbolStatus `json:"status"`
+	WrapEnabled    bool         `json:"wrap_enabled"`
+}
+
+// TickerV1 represents ticker data from Gemini API v1
+type TickerV1 struct {
+	Bid    string         `json:"bid"`
+	Ask    string         `json:"ask"`
+	Last   string         `json:"last"`
+	Volume TickerV1Volume `json:"volume"`
+}
+
+// TickerV1Volume is the 24 hour traded volume of a v1 ticker. Gemini sends it as an
+// object keyed by currency (for example "BTC" and "USD") next to a "timestamp" field.
+type TickerV1Volume struct {
+	// Currencies maps upper case currency codes to the volume traded in that currency
+	Currencies map[string]string
+	// Timestamp is the end of the volume window in milliseconds
+	Timestamp FlexInt
+}
+
+// UnmarshalJSON splits the timestamp from the per-currency volumes
+func (v *TickerV1Volume) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	v.Currencies = make(map[string]string, len(fields))
+	v.Timestamp = 0
+	for key, raw := range fields {
+		if key == "timestamp" {
+			if err := json.Unmarshal(raw, &v.Timestamp); err != nil {
+				return err
+			}
+			continue
+		}
+		var amount FlexFloat
+		if err := json.Unmarshal(raw, &amount); err != nil {
+			return err
+		}
+		v.Currencies[strings.ToUpper(key)] = strings.Trim(string(raw), `"`)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the volume in Gemini's flat format
+func (v TickerV1Volume) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(v.Currencies)+1)
+	for currency, amount := range v.Currencies {
+		fields[currency] = amount
+	}
+	fields["timestamp"] = int64(v.Timestamp)
+	return json.Marshal(fields)
+}
+
+// Get returns the volume traded in currency, or an empty string if absent
+func (v TickerV1Volume) Get(currency string) string {
+	return v.Currencies[strings.ToUpper(currency)]
 }
 
 // TickerV2 represents ticker data from Gemini API v2
@@ -29,11 +105,226 @@ type TickerV2 struct {
 	Ask     string   `json:"ask"`
 }
 
+// FeePromos lists the symbols currently trading under a fee promotion.
+// Gemini does not report promotion end times on this endpoint.
+type FeePromos struct {
+	Symbols []string `json:"symbols"`
+}
+
+// Ticker represents ticker data normalized across API versions.
+// Fields not provided by the selected version are left empty: v1 has no open, high or low,
+// and v2 has no volume.
+type Ticker struct {
+	Symbol      string `json:"symbol"`
+	Bid         string `json:"bid"`
+	Ask         string `json:"ask"`
+	Last        string `json:"last"`
+	Open        string `json:"open,omitempty"`
+	High        string `json:"high,omitempty"`
+	Low         string `json:"low,omitempty"`
+	Volume      string `json:"volume,omitempty"`
+	QuoteVolume string `json:"quote_volume,omitempty"`
+	// PercentChange24h is the 24 hour price change as reported by the price feed. It is only
+	// set by GetAllTickers with TickerDetailPrice.
+	PercentChange24h string `json:"percent_change_24h,omitempty"`
+}
+
+// PriceFeedEntry is the last price and 24 hour change of a trading pair
+type PriceFeedEntry struct {
+	Pair             string `json:"pair"`
+	Price            string `json:"price"`
+	PercentChange24h string `json:"percentChange24h"`
+}
+
 // ErrorResponse represents an error response from Gemini API
 type ErrorResponse struct {
 	Result  string `json:"result"`
 	Reason  string `json:"reason"`
 	Message string `json:"message"`
+	// Extra holds any other fields of the error payload, keyed by field name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the documented fields and keeps the remaining ones in Extra
+func (e *ErrorResponse) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	targets := map[string]*string{"result": &e.Result, "reason": &e.Reason, "message": &e.Message}
+	e.Extra = nil
+	for key, value := range fields {
+		if target, ok := targets[key]; ok {
+			// Non-string values are kept as raw JSON rather than failing the whole error
+			if err := json.Unmarshal(value, target); err == nil {
+				continue
+			}
+		}
+		if e.Extra == nil {
+			e.Extra = make(map[string]json.RawMessage)
+		}
+		e.Extra[key] = value
+	}
+	return nil
+}
+
+// MarshalJSON encodes the error response with the Extra fields inlined, as received
+func (e ErrorResponse) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(e.Extra)+3)
+	for key, value := range e.Extra {
+		fields[key] = value
+	}
+	fields["result"] = e.Result
+	fields["reason"] = e.Reason
+	fields["message"] = e.Message
+	return json.Marshal(fields)
+}
+
+// reasonCodes maps Gemini error reasons, lowercased, to SDK error codes. Reasons not listed
+// are reported as ErrAPIError.
+var reasonCodes = map[string]errors.ErrorCode{
+	"invalidsignature":       errors.ErrInvalidSignature,
+	"missingsignatureheader": errors.ErrInvalidSignature,
+	"invalidapikey":          errors.ErrInvalidAPIKey,
+	"missingapikeyheader":    errors.ErrInvalidAPIKey,
+	"missingrole":            errors.ErrPermissionDenied,
+	"invalidnonce":           errors.ErrInvalidNonce,
+}
+
+// Err converts the error response to an SDKError. Authentication failures get their own
+// code (ErrInvalidSignature, ErrInvalidAPIKey, ErrPermissionDenied or, for a rejected nonce,
+// ErrInvalidNonce); other reasons are
+// reported as ErrAPIError. The parsed response is attached as JSON in the error details so
+// structured consumers can read reason and message separately.
+func (e *ErrorResponse) Err() *errors.SDKError {
+	code, ok := reasonCodes[strings.ToLower(e.Reason)]
+	if !ok {
+		code = errors.ErrAPIError
+	}
+	err := errors.Newf(code, "Gemini API error: %s - %s", e.Reason, e.Message)
+	if details, marshalErr := json.Marshal(e); marshalErr == nil {
+		err = err.WithDetails(string(details))
+	}
+	return err
+}
+
+// requestError wraps an error from the HTTP client. When Gemini answered with an error status,
+// its error response is decoded so that callers see the exchange's reason rather than a network
+// error, and a 403 without a recognized reason is reported as ErrPermissionDenied.
+func requestError(message string, err error) error {
+	var statusErr *client.StatusError
+	if !stderrors.As(err, &statusErr) {
+		return errors.Wrap(errors.ErrNetworkError, message, err)
+	}
+
+	var errorResp ErrorResponse
+	if jsonUnmarshal(statusErr.Body, &errorResp) == nil && errorResp.Result == errorStatus {
+		apiErr := errorResp.Err()
+		if apiErr.Code == errors.ErrAPIError && statusErr.StatusCode == http.StatusForbidden {
+			apiErr.Code = errors.ErrPermissionDenied
+		}
+		return apiErr
+	}
+	if statusErr.StatusCode == http.StatusForbidden {
+		return errors.Wrap(errors.ErrPermissionDenied, message, err)
+	}
+	return errors.Wrap(errors.ErrNetworkError, message, err)
+}
+
+// decodeListResponse decodes the response of a private endpoint that returns a JSON array on
+// success. Gemini reports failures as an object instead, so the top-level JSON type decides how
+// the response is read: an array is decoded into v, and an object is returned as an API error
+// even when its result field is missing. message describes a failure to parse the array, and
+// url is the endpoint the response came from.
+func decodeListResponse(response []byte, url string, v interface{}, message string) error {
+	trimmed := bytes.TrimSpace(response)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var errorResp ErrorResponse
+		if err := jsonUnmarshal(trimmed, &errorResp); err != nil {
+			return parseError(trimmed, url, message, err)
+		}
+		if errorResp.Result == errorStatus || errorResp.Reason != "" || errorResp.Message != "" {
+			return errorResp.Err()
+		}
+		return errors.New(errors.ErrInvalidResponse, "expected a JSON array but got an object").WithDetails(string(trimmed))
+	}
+
+	if err := jsonUnmarshalArray(response, v); err != nil {
+		return parseError(response, url, message, err)
+	}
+	return nil
+}
+
+// maxBodySnippet bounds how much of an unexpected response body is kept in error details
+const maxBodySnippet = 256
+
+// parseError describes a response from url that failed to decode. An empty body, or one that
+// is not JSON at all such as an HTML page from a proxy or captive portal, gives
+// ErrInvalidResponse, with the start of the body in the details. Malformed or mistyped JSON
+// gives ErrDataParsingError.
+func parseError(response []byte, url string, message string, err error) error {
+	trimmed := bytes.TrimSpace(response)
+	if len(trimmed) == 0 {
+		return errors.Newf(errors.ErrInvalidResponse, "empty response body from %s", url)
+	}
+	if trimmed[0] != '{' && trimmed[0] != '[' {
+		snippet := trimmed
+		if len(snippet) > maxBodySnippet {
+			snippet = snippet[:maxBodySnippet]
+		}
+		return errors.Wrap(errors.ErrInvalidResponse, message+": response is not JSON", err).WithDetails(string(snippet))
+	}
+	return errors.Wrap(errors.ErrDataParsingError, message, err)
+}
+
+// timestampTime converts a Gemini timestamp to a time, preferring the millisecond field and
+// falling back to the one in seconds. Missing timestamps give the zero time.
+func timestampTime(ms FlexInt, seconds int64) time.Time {
+	switch {
+	case ms > 0:
+		return time.UnixMilli(int64(ms))
+	case seconds > 0:
+		return time.Unix(seconds, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// FlexFloat is a float64 that unmarshals from either a JSON number or a quoted string
+type FlexFloat float64
+
+// UnmarshalJSON accepts numbers, numeric strings, empty strings and null
+func (f *FlexFloat) UnmarshalJSON(data []byte) error {
+	raw := string(bytes.Trim(bytes.TrimSpace(data), `"`))
+	if raw == "" || raw == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return err
+	}
+	*f = FlexFloat(v)
+	return nil
+}
+
+// FlexInt is an int64 that unmarshals from either a JSON number or a quoted string
+type FlexInt int64
+
+// UnmarshalJSON accepts integers, numeric strings, empty strings and null
+func (i *FlexInt) UnmarshalJSON(data []byte) error {
+	raw := string(bytes.Trim(bytes.TrimSpace(data), `"`))
+	if raw == "" || raw == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = FlexInt(v)
+	return nil
 }
 
 // parseFloatFromString safely converts string to float64 with error handling
@@ -0,0 +1,91 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepquant-labs/deepquant-cex-go-sdk/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampAccessors(t *testing.T) {
+	ms := time.Date(2019, 1, 10, 19, 12, 21, 373*int(time.Millisecond), time.UTC)
+	seconds := ms.Truncate(time.Second)
+
+	var order Order
+	require.NoError(t, json.Unmarshal([]byte(`{"timestamp":"1547147541","timestampms":1547147541373}`), &order))
+	assert.True(t, ms.Equal(order.CreatedAt()), order.CreatedAt())
+
+	var secondsOnly Order
+	require.NoError(t, json.Unmarshal([]byte(`{"timestamp":"1547147541"}`), &secondsOnly))
+	assert.True(t, seconds.Equal(secondsOnly.CreatedAt()), secondsOnly.CreatedAt())
+	assert.True(t, (&Order{}).CreatedAt().IsZero())
+
+	var trade PastTrade
+	require.NoError(t, json.Unmarshal([]byte(`{"timestamp":1547147541,"timestampms":1547147541373}`), &trade))
+	assert.True(t, ms.Equal(trade.ExecutedAt()), trade.ExecutedAt())
+
+	// Deposit address timestamps are in milliseconds even though the field is named timestamp
+	var address DepositAddress
+	require.NoError(t, json.Unmarshal([]byte(`{"address":"bc1q","timestamp":1547147541373}`), &address))
+	assert.True(t, ms.Equal(address.CreatedAt()), address.CreatedAt())
+
+	var update MarketDataUpdate
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"update","timestamp":1547147541,"timestampms":1547147541373}`), &update))
+	assert.True(t, ms.Equal(update.Time()), update.Time())
+
+	clearing := ClearingOrder{Submission: 1547147541373, Expiration: 1547147601373}
+	assert.True(t, ms.Equal(clearing.SubmittedAt()), clearing.SubmittedAt())
+	assert.Equal(t, time.Minute, clearing.ExpiresAt().Sub(clearing.SubmittedAt()))
+}
+
+func TestParseError_NonJSONBody(t *testing.T) {
+	page := "<html><head><title>Sign in to the network</title></head><body>" + strings.Repeat("x", 1000) + "</body></html>"
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/symbols", "/v1/order/status":
+			_, _ = w.Write([]byte(page))
+		default:
+			_, _ = w.Write([]byte(`{"symbol":42}`))
+		}
+	})
+
+	_, err := g.Market.ListSymbols(context.Background())
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	_, err = g.Order.GetOrderStatus(context.Background(), "1", "", false, "")
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+
+	sdkErr, ok := err.(*errors.SDKError)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(sdkErr.Details, "<html><head><title>Sign in"), sdkErr.Details)
+	assert.Len(t, sdkErr.Details, maxBodySnippet)
+
+	// JSON of the wrong shape is still a parsing error
+	_, err = g.Market.GetTicker(context.Background(), "btcusd")
+	assert.Equal(t, errors.ErrDataParsingError, errors.GetCode(err))
+}
+
+func TestParseError_EmptyBody(t *testing.T) {
+	g := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(" \n\t"))
+	})
+	ctx := context.Background()
+
+	_, err := g.Market.GetTickerV2(ctx, "btcusd")
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "empty response body from ")
+	assert.Contains(t, err.Error(), "/v2/ticker/btcusd")
+
+	_, err = g.Order.GetOrderStatus(ctx, "1", "", false, "")
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "/v1/order/status")
+
+	_, err = g.Fund.GetAvailableBalances(ctx, "")
+	assert.Equal(t, errors.ErrInvalidResponse, errors.GetCode(err))
+	assert.Contains(t, err.Error(), "/v1/balances")
+}
@@ -0,0 +1,74 @@
+package gemini
+
+import (
+	"context"
+	"sync"
+)
+
+// privateRequestWorkers bounds the concurrent calls made by methods that fan out private
+// requests. Signed requests with one API key are sent one at a time in nonce order anyway,
+// so more workers would only queue up behind each other.
+const privateRequestWorkers = 4
+
+// forEachBounded calls fn for each distinct key from at most workers goroutines, and returns
+// the results and errors keyed by key. Once ctx is done no further calls are started, and the
+// keys not processed get ctx.Err().
+func forEachBounded[T any](ctx context.Context, workers int, keys []string, fn func(key string) (T, error)) (map[string]T, map[string]error) {
+	results := make(map[string]T, len(keys))
+	errs := make(map[string]error)
+
+	pending := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		pending = append(pending, key)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < min(workers, len(pending)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				var result T
+				err := ctx.Err()
+				if err == nil {
+					result, err = fn(key)
+				}
+
+				mu.Lock()
+				if err != nil {
+					errs[key] = err
+				} else {
+					results[key] = result
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, key := range pending {
+		select {
+		case jobs <- key:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for _, key := range pending {
+			if _, ok := results[key]; !ok && errs[key] == nil {
+				errs[key] = err
+			}
+		}
+	}
+	return results, errs
+}
@@ -0,0 +1,57 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachBounded(t *testing.T) {
+	var calls, inflight, peak int32
+	keys := []string{"a", "b", "a", "bad"}
+	for i := 0; i < 10; i++ {
+		keys = append(keys, fmt.Sprintf("k%d", i))
+	}
+
+	results, errs := forEachBounded(context.Background(), 3, keys, func(key string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		current := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			previous := atomic.LoadInt32(&peak)
+			if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if key == "bad" {
+			return "", fmt.Errorf("failed %s", key)
+		}
+		return "value " + key, nil
+	})
+
+	assert.Equal(t, int32(13), calls, "duplicate keys are processed once")
+	assert.LessOrEqual(t, peak, int32(3))
+	assert.Len(t, results, 12)
+	assert.Equal(t, "value a", results["a"])
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs["bad"], "failed bad")
+
+	// Once ctx is done the remaining keys are not processed and get its error
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	results, errs = forEachBounded(ctx, 1, keys, func(key string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		return key, nil
+	})
+	assert.Equal(t, int32(1), calls)
+	assert.Len(t, results, 1)
+	assert.Len(t, errs, 12)
+	assert.ErrorIs(t, errs["k9"], context.Canceled)
+}
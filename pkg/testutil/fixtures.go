@@ -0,0 +1,109 @@
+// Package testutil records real exchange API responses as fixtures and replays them in tests.
+//
+// The SDK's HTTP client is built on fasthttp rather than net/http, so instead of swapping a
+// transport, both the Recorder and the Replayer are local HTTP servers: point the exchange
+// at their URL (for example with Gemini.SetBaseURLs) and every request goes through them.
+// The Recorder forwards requests to the real API and saves each response to disk; the
+// Replayer serves the saved responses back without network access.
+//
+// Fixtures are matched by HTTP method and path (including the query string). Request bodies
+// and headers are ignored, since private requests carry a fresh nonce and signature on every
+// run. Repeated requests to the same path are saved and replayed in order.
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// RecordEnv is the environment variable that switches NewServer to recording mode
+const RecordEnv = "CEXSDK_RECORD"
+
+// Fixture is a recorded HTTP response
+type Fixture struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Recording reports whether fixtures should be recorded from the live API in this run,
+// which is the case when the CEXSDK_RECORD environment variable is set
+func Recording() bool {
+	return os.Getenv(RecordEnv) != ""
+}
+
+// NewServer starts a Recorder against upstream when recording, or a Replayer otherwise,
+// and returns its base URL. Fixtures are stored in dir.
+func NewServer(t testing.TB, dir, upstream string) string {
+	t.Helper()
+	if Recording() {
+		return NewRecorder(t, dir, upstream).URL()
+	}
+	return NewReplayer(t, dir).URL()
+}
+
+// fixtureName returns the file name of the n-th (starting at 0) fixture for a request
+func fixtureName(method, path string, n int) string {
+	name := strings.Trim(path, "/")
+	name = strings.NewReplacer("/", "_", "?", "_", "&", "_", "=", "-", ":", "-").Replace(name)
+	if name == "" {
+		name = "root"
+	}
+	name = method + "_" + name
+	if n > 0 {
+		name = fmt.Sprintf("%s_%d", name, n+1)
+	}
+	return name + ".json"
+}
+
+// requestCounter numbers repeated requests to the same method and path
+type requestCounter struct {
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func newRequestCounter() *requestCounter {
+	return &requestCounter{counts: make(map[string]int)}
+}
+
+// next returns how many times the request was seen before
+func (c *requestCounter) next(method, path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := method + " " + path
+	n := c.counts[key]
+	c.counts[key] = n + 1
+	return n
+}
+
+// readFixture loads a fixture file
+func readFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- fixture paths are built by the test harness
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// writeFixture saves a fixture file, creating dir if needed
+func writeFixture(dir, name string, fixture *Fixture) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), append(data, '\n'), 0o600)
+}
@@ -0,0 +1,100 @@
+package testutil
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hopHeaders are not forwarded by the Recorder
+var hopHeaders = []string{"Connection", "Keep-Alive", "Proxy-Connection", "Transfer-Encoding", "Upgrade"}
+
+// Recorder is a local server that forwards requests to a live API and saves every response
+// as a fixture. Only responses are written to disk; request headers, which carry the API
+// key and signature, are never stored. Review recorded fixtures for account data before
+// committing them.
+type Recorder struct {
+	server   *httptest.Server
+	upstream string
+	dir      string
+	client   *http.Client
+	counter  *requestCounter
+	t        testing.TB
+}
+
+// NewRecorder starts a Recorder that forwards to upstream (for example
+// "https://api.sandbox.gemini.com") and saves fixtures in dir. It stops when the test ends.
+func NewRecorder(t testing.TB, dir, upstream string) *Recorder {
+	t.Helper()
+	r := &Recorder{
+		upstream: strings.TrimSuffix(upstream, "/"),
+		dir:      dir,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		counter:  newRequestCounter(),
+		t:        t,
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// URL returns the base URL of the Recorder
+func (r *Recorder) URL() string {
+	return r.server.URL
+}
+
+// serveHTTP forwards a request upstream, saves the response and relays it to the caller
+func (r *Recorder) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	path := req.URL.RequestURI()
+	upstreamReq, err := http.NewRequestWithContext(req.Context(), req.Method, r.upstream+path, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	upstreamReq.Header = req.Header.Clone()
+	for _, h := range hopHeaders {
+		upstreamReq.Header.Del(h)
+	}
+
+	resp, err := r.client.Do(upstreamReq)
+	if err != nil {
+		r.t.Errorf("testutil: recording %s %s failed: %v", req.Method, path, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	fixture := &Fixture{
+		Method:      req.Method,
+		Path:        path,
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(respBody),
+	}
+	name := fixtureName(req.Method, path, r.counter.next(req.Method, path))
+	if err := writeFixture(r.dir, name, fixture); err != nil {
+		r.t.Errorf("testutil: saving fixture %s failed: %v", name, err)
+	}
+
+	if fixture.ContentType != "" {
+		w.Header().Set("Content-Type", fixture.ContentType)
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+}
@@ -0,0 +1,67 @@
+package testutil
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// Replayer is a local server that answers requests with fixtures saved by a Recorder
+type Replayer struct {
+	server  *httptest.Server
+	dir     string
+	counter *requestCounter
+	t       testing.TB
+}
+
+// NewReplayer starts a Replayer serving fixtures from dir. It stops when the test ends.
+// A request without a fixture fails the test and is answered with 404.
+func NewReplayer(t testing.TB, dir string) *Replayer {
+	t.Helper()
+	r := &Replayer{
+		dir:     dir,
+		counter: newRequestCounter(),
+		t:       t,
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// URL returns the base URL of the Replayer
+func (r *Replayer) URL() string {
+	return r.server.URL
+}
+
+// serveHTTP answers a request with its next fixture. Once the recorded responses for a
+// request are used up, the last one is served again.
+func (r *Replayer) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.RequestURI()
+	fixture, err := r.lookup(req.Method, path, r.counter.next(req.Method, path))
+	if err != nil {
+		r.t.Errorf("testutil: no fixture for %s %s in %s (record it with %s=1): %v", req.Method, path, r.dir, RecordEnv, err)
+		http.Error(w, "no fixture recorded", http.StatusNotFound)
+		return
+	}
+
+	if fixture.ContentType != "" {
+		w.Header().Set("Content-Type", fixture.ContentType)
+	}
+	w.WriteHeader(fixture.Status)
+	_, _ = w.Write([]byte(fixture.Body))
+}
+
+// lookup returns the n-th fixture for a request, or the last one recorded before it
+func (r *Replayer) lookup(method, path string, n int) (*Fixture, error) {
+	for ; n >= 0; n-- {
+		fixture, err := readFixture(filepath.Join(r.dir, fixtureName(method, path, n)))
+		if errors.Is(err, fs.ErrNotExist) && n > 0 {
+			continue
+		}
+		return fixture, err
+	}
+	return nil, fs.ErrNotExist
+}
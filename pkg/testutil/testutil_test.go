@@ -0,0 +1,112 @@
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url) // #nosec G107 -- local test server
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-GEMINI-APIKEY") != "key" {
+			t.Error("Expected request headers to be forwarded")
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(calls) + `}`))
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+
+	recorder := NewRecorder(t, dir, upstream.URL)
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, recorder.URL()+"/v1/balances", nil)
+		req.Header.Set("X-GEMINI-APIKEY", "key")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 upstream calls, got %d", calls)
+	}
+
+	replayer := NewReplayer(t, dir)
+	expected := []string{`{"call":1}`, `{"call":2}`, `{"call":2}`}
+	for _, want := range expected {
+		req, _ := http.NewRequest(http.MethodPost, replayer.URL()+"/v1/balances", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("Expected replayed body %s, got %s", want, body)
+		}
+		if resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected recorded content type, got %q", resp.Header.Get("Content-Type"))
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected replay not to call upstream, got %d calls", calls)
+	}
+}
+
+// failureRecorder captures test failures instead of failing the wrapped test
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (f *failureRecorder) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func TestReplayer_MissingFixture(t *testing.T) {
+	recorder := &failureRecorder{TB: t}
+	replayer := NewReplayer(recorder, t.TempDir())
+
+	status, _ := get(t, replayer.URL()+"/v1/symbols")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing fixture, got %d", status)
+	}
+	if !recorder.failed {
+		t.Error("Expected a missing fixture to fail the test")
+	}
+}
+
+func TestFixtureName(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		n        int
+		expected string
+	}{
+		{"GET", "/v1/symbols", 0, "GET_v1_symbols.json"},
+		{"GET", "/v1/symbols/details/btcusd", 0, "GET_v1_symbols_details_btcusd.json"},
+		{"POST", "/v1/balances", 1, "POST_v1_balances_2.json"},
+		{"GET", "/v1/marketdata/btcusd?heartbeat=true", 0, "GET_v1_marketdata_btcusd_heartbeat-true.json"},
+		{"GET", "/", 0, "GET_root.json"},
+	}
+	for _, test := range tests {
+		if name := fixtureName(test.method, test.path, test.n); name != test.expected {
+			t.Errorf("fixtureName(%s, %s, %d) = %s, expected %s", test.method, test.path, test.n, name, test.expected)
+		}
+	}
+}